@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// describeMove spells out a move of the game in plain English for screen
+// readers, e.g. "White pawn e2 to e4. Black to move." The move is the one
+// played from pos; after is the game once it has been played.
+func describeMove(pos *chess.Position, mv *chess.Move, after *chess.Game) string {
+	mover := pos.Turn()
+	piece := strings.ToLower(pieceName(pos.Board().Piece(mv.S1()).Type()))
+
+	var sb strings.Builder
+	switch {
+	case mv.HasTag(chess.KingSideCastle):
+		fmt.Fprintf(&sb, "%s castles kingside", mover.Name())
+	case mv.HasTag(chess.QueenSideCastle):
+		fmt.Fprintf(&sb, "%s castles queenside", mover.Name())
+	case mv.HasTag(chess.EnPassant):
+		fmt.Fprintf(&sb, "%s pawn %s takes pawn en passant on %s", mover.Name(), mv.S1(), mv.S2())
+	case mv.HasTag(chess.Capture):
+		captured := strings.ToLower(pieceName(pos.Board().Piece(mv.S2()).Type()))
+		fmt.Fprintf(&sb, "%s %s %s takes %s on %s", mover.Name(), piece, mv.S1(), captured, mv.S2())
+	default:
+		fmt.Fprintf(&sb, "%s %s %s to %s", mover.Name(), piece, mv.S1(), mv.S2())
+	}
+	if mv.Promo() != chess.NoPieceType {
+		fmt.Fprintf(&sb, " and promotes to %s", strings.ToLower(pieceName(mv.Promo())))
+	}
+	sb.WriteString(". ")
+
+	switch {
+	case after.Method() == chess.Checkmate:
+		sb.WriteString("Checkmate. " + outcomeString(after.Outcome()))
+	case after.Outcome() != chess.NoOutcome:
+		sb.WriteString("Game over. " + resultString(after) + ".")
+	default:
+		if mv.HasTag(chess.Check) {
+			sb.WriteString("Check. ")
+		}
+		sb.WriteString(mover.Other().Name() + " to move.")
+	}
+	return sb.String()
+}
+
+// announceMove shows a description of the last move in the status line
+// with -speak, and appends it to the -speak-log file if there is one.
+func (m *model) announceMove() tea.Cmd {
+	if !m.speak {
+		return nil
+	}
+	moves := m.game.Moves()
+	positions := m.game.Positions()
+	if len(moves) == 0 {
+		return nil
+	}
+	text := describeMove(positions[len(positions)-2], moves[len(moves)-1], m.game)
+	m.status = text
+	if m.speakLog == "" {
+		return nil
+	}
+	path := m.speakLog
+	return func() tea.Msg {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+		fmt.Fprintln(f, text)
+		return nil
+	}
+}
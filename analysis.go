@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+const (
+	// analysisMovetime is how long the engine thinks about the position
+	// shown while analysis is on.
+	analysisMovetime = time.Second
+	// defaultEngine is started for analysis when gochess was started
+	// without -engine.
+	defaultEngine = "stockfish"
+)
+
+// analysisMsg carries the engine's verdict on the position shown.
+type analysisMsg struct {
+	engine *uciEngine
+	fen    string
+	result searchResult
+	err    error
+}
+
+// engineStartedMsg carries the engine started to analyse on demand.
+type engineStartedMsg struct {
+	engine *uciEngine
+	err    error
+}
+
+// toggleAnalysis turns on showing the engine's evaluation and best move for
+// the position on the board, starting an engine if there is none, or turns
+// it off again. The engine doesn't take over either side.
+func (m *model) toggleAnalysis() (tea.Cmd, error) {
+	if m.analysing {
+		m.analysing = false
+		m.analysisFEN = ""
+		m.status = "Engine analysis off"
+		if !m.analysisEngine || m.engine == nil {
+			return nil, nil
+		}
+		// the engine was only started to analyse
+		engine := m.engine
+		m.engine, m.analysisEngine = nil, false
+		m.evalPending, m.analysisPending = false, false
+		return func() tea.Msg {
+			engine.close()
+			return nil
+		}, nil
+	}
+	if m.stoppedEngine != nil {
+		return nil, errors.New("the engine has stopped, :engine restart to restart it")
+	}
+	m.analysing = true
+	if m.engine != nil {
+		m.status = "Engine analysis on"
+		return m.nextAnalysis(), nil
+	}
+	m.status = "Starting " + defaultEngine + "…"
+	return func() tea.Msg {
+		engine, err := startEngine(defaultEngine)
+		return engineStartedMsg{engine: engine, err: err}
+	}, nil
+}
+
+// handleEngineStarted puts the engine started for analysis to work.
+func (m *model) handleEngineStarted(msg engineStartedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.analysing = false
+		m.status = ""
+		m.error = fmt.Errorf("starting %s: %w (start gochess with -engine to use another engine)", defaultEngine, msg.err)
+		return nil
+	}
+	if !m.analysing || m.engine != nil {
+		// turned off again while starting
+		go msg.engine.close()
+		return nil
+	}
+	m.engine, m.analysisEngine = msg.engine, true
+	m.status = "Engine analysis on"
+	if m.syzygy != "" {
+		if err := m.engine.useSyzygy(m.syzygy); err != nil {
+			m.status += ", tablebases not used: " + err.Error()
+		}
+	}
+	return tea.Batch(m.nextAnalysis(), m.nextEval())
+}
+
+// nextAnalysis returns a command analysing the position shown, unless it
+// has been analysed already. Only one analysis is in flight at a time.
+func (m *model) nextAnalysis() tea.Cmd {
+	if !m.analysing || m.engine == nil || m.analysisPending {
+		return nil
+	}
+	pos := m.displayedPosition()
+	fen := pos.String()
+	if fen == m.analysisFEN {
+		return nil
+	}
+	if pos.Status() != chess.NoMethod {
+		// nothing to analyse once the game is over
+		m.analysisFEN, m.analysis = fen, searchResult{}
+		return nil
+	}
+	m.stopPondering()
+	m.analysisPending = true
+	engine := m.engine
+	return func() tea.Msg {
+		result, err := engine.searchLines(pos, analysisMovetime, m.multiPV)
+		return analysisMsg{engine: engine, fen: fen, result: result, err: err}
+	}
+}
+
+// handleAnalysis stores the engine's verdict on a position.
+func (m *model) handleAnalysis(msg analysisMsg) tea.Cmd {
+	if msg.engine != m.engine {
+		return nil
+	}
+	m.analysisPending = false
+	if msg.err != nil {
+		return m.engineFailed(msg.err)
+	}
+	m.analysisFEN, m.analysis = msg.fen, msg.result
+	if msg.result.bestMove != "" {
+		m.bestMoves[msg.fen] = msg.result.bestMove
+	}
+	if _, ok := m.evals[msg.fen]; !ok {
+		m.evals[msg.fen] = msg.result.score
+		m.evalStored()
+	}
+	return nil
+}
+
+// analysisLine describes the engine's verdict on the position shown.
+func (m model) analysisLine() string {
+	pos := m.displayedPosition()
+	if m.engine == nil || m.analysisFEN != pos.String() {
+		return "Engine: thinking…"
+	}
+	if m.analysis.bestMove == "" {
+		return "Engine: no moves"
+	}
+	line := "Engine: " + m.analysis.score.String()
+	for _, mv := range pos.ValidMoves() {
+		if mv.String() == m.analysis.bestMove {
+			line += " · best " + m.locale.translateSAN(chess.AlgebraicNotation{}.Encode(pos, mv))
+		}
+	}
+	return line
+}
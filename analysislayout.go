@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+const (
+	// analysisPanelMinWidth and analysisPanelMaxWidth bound the panel
+	// beside the board in the analysis layout, which takes what the board
+	// leaves of the window in between.
+	analysisPanelMinWidth = 28
+	analysisPanelMaxWidth = 56
+	// analysisLayoutChrome is the height the analysis layout needs besides
+	// the board: the title, the evaluation graph and the status lines.
+	analysisLayoutChrome = 18
+)
+
+var analysisCurrentStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7FA650"))
+
+// toggleAnalysisLayout switches between the play layout and the analysis
+// layout, which gives the window over to the board, as large as it fits,
+// the engine's verdict with its candidate moves, the moves with their
+// evaluations and the evaluation graph. The input is hidden until
+// something is typed.
+func (m *model) toggleAnalysisLayout() {
+	m.analysisLayout = !m.analysisLayout
+	m.resizeHistory(m.historyWidth)
+	m.status = "Play layout"
+	if m.analysisLayout {
+		m.status = "Analysis layout, f10 to go back"
+		if !m.analysing {
+			m.status += " · f6 starts the engine's analysis"
+		}
+	}
+}
+
+// layoutScale is the scale the board is drawn at: the one chosen, or in
+// the analysis layout the largest one that fits the window.
+func (m model) layoutScale() boardScale {
+	if !m.analysisLayout {
+		return m.scale
+	}
+	frame := m.boardFrame()
+	for _, s := range []boardScale{scaleLarge, scaleNormal, scaleSmall} {
+		w, h := boardOptions{scale: s, labels: m.labels}.size()
+		w += frame.GetHorizontalFrameSize()
+		h += frame.GetVerticalFrameSize()
+		if w+historyGap+analysisPanelMinWidth <= m.width-docStyle.GetHorizontalFrameSize() && h+analysisLayoutChrome <= m.height {
+			return s
+		}
+	}
+	return scaleCompact
+}
+
+// analysisPanelWidth is the width of the panel beside the board in the
+// analysis layout.
+func (m model) analysisPanelWidth() int {
+	boardWidth, _ := m.boardSize()
+	available := m.width - docStyle.GetHorizontalFrameSize() - boardWidth - historyGap
+	return min(max(available, analysisPanelMinWidth), analysisPanelMaxWidth)
+}
+
+// renderAnalysisLayout lays out the framed board with the engine's verdict
+// and the moves beside it, and the evaluation graph across both below.
+func (m model) renderAnalysisLayout(board string) string {
+	width := m.analysisPanelWidth()
+	inner := width - historyStyle.GetHorizontalFrameSize()
+	engine := historyStyle.Width(inner).Render(m.renderEnginePanel(inner))
+	movesHeight := lipgloss.Height(board) - lipgloss.Height(engine) - historyStyle.GetVerticalFrameSize()
+	moves := historyStyle.Width(inner).Height(max(movesHeight, 1)).Render(m.renderEvaluatedMoves(inner, max(movesHeight, 1)))
+	panel := lipgloss.JoinVertical(lipgloss.Left, engine, moves)
+	body := lipgloss.JoinHorizontal(lipgloss.Top, board, strings.Repeat(" ", historyGap), panel)
+	if m.engine != nil && !m.modalActive() {
+		body = lipgloss.JoinVertical(lipgloss.Left, body, m.renderEvalGraph(lipgloss.Width(body)))
+	}
+	return body
+}
+
+// renderEnginePanel lists the engine's verdict on the position shown and,
+// with :multipv, its candidate moves one per line.
+func (m model) renderEnginePanel(width int) string {
+	if !m.analysing || m.engine == nil {
+		return statusMessageStyle.Faint(true).Render("f6 starts the engine's analysis")
+	}
+	lines := []string{statusMessageStyle.Render(fitWidth(m.analysisLine(), width))}
+	for i, c := range m.candidates() {
+		style := lipgloss.NewStyle().Foreground(c.shade)
+		lines = append(lines, style.Render(fitWidth(fmt.Sprintf("%d. %-8s %s", i+1, c.san, c.score), width)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderEvaluatedMoves lists the moves of the game, one per line: the move
+// with its judgement once both positions around it are evaluated, and the
+// evaluation of the position it led to on the right, e.g. "12... Nf6 ?" and
+// "+1.30". The lines end at the move shown on the board, or as near it as
+// height allows, which is highlighted.
+func (m model) renderEvaluatedMoves(width, height int) string {
+	moves := m.game.Moves()
+	if len(moves) == 0 {
+		return statusMessageStyle.Faint(true).Render("No moves yet")
+	}
+	positions := m.game.Positions()
+	start := gameStartPly(m.game)
+	current := m.currentPly()
+	last := max(current, min(height, len(moves)))
+	first := max(last-height, 0)
+	var lines []string
+	for i := first; i < last && i < len(moves); i++ {
+		san := m.locale.translateSAN(chess.AlgebraicNotation{}.Encode(positions[i], moves[i]))
+		text := plyNumber(start+i) + " " + san
+		eval := "…"
+		if score, ok := m.evals[positions[i+1].String()]; ok {
+			eval = score.String()
+		}
+		if delta, ok := m.evalDelta(i); ok {
+			if symbol := judgements[judgeMove(delta)].symbol; symbol != "" {
+				text += " " + judgements[judgeMove(delta)].style.Render(symbol)
+			}
+		}
+		gap := max(width-lipgloss.Width(text)-lipgloss.Width(eval), 1)
+		line := text + strings.Repeat(" ", gap) + eval
+		if i+1 == current {
+			line = analysisCurrentStyle.Render(text) + strings.Repeat(" ", gap) + analysisCurrentStyle.Render(eval)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
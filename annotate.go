@@ -0,0 +1,239 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+var (
+	arrowSourceStyle = lipgloss.NewStyle().Background(lipgloss.Color("#6F93B8"))
+	arrowTargetStyle = lipgloss.NewStyle().Background(lipgloss.Color("#4A76A8"))
+	circleStyle      = lipgloss.NewStyle().Background(lipgloss.Color("#C8675F"))
+)
+
+// arrow is an arrow drawn from one square to another.
+type arrow struct {
+	from, to chess.Square
+}
+
+// annotation holds the arrows and circles drawn on a position, as on
+// lichess. A terminal can't draw real arrows, so the squares an arrow
+// starts and ends on are highlighted and the empty squares it crosses are
+// dotted.
+type annotation struct {
+	arrows  []arrow
+	circles []chess.Square
+}
+
+func (a annotation) empty() bool {
+	return len(a.arrows) == 0 && len(a.circles) == 0
+}
+
+// toggleArrow adds an arrow, or removes it when it is already drawn.
+func (a annotation) toggleArrow(ar arrow) annotation {
+	if i := slices.Index(a.arrows, ar); i >= 0 {
+		a.arrows = slices.Delete(slices.Clone(a.arrows), i, i+1)
+	} else {
+		a.arrows = append(slices.Clone(a.arrows), ar)
+	}
+	return a
+}
+
+// toggleCircle circles a square, or removes its circle.
+func (a annotation) toggleCircle(sq chess.Square) annotation {
+	if i := slices.Index(a.circles, sq); i >= 0 {
+		a.circles = slices.Delete(slices.Clone(a.circles), i, i+1)
+	} else {
+		a.circles = append(slices.Clone(a.circles), sq)
+	}
+	return a
+}
+
+// highlights returns the square backgrounds of the annotation.
+func (a annotation) highlights() map[chess.Square]lipgloss.Style {
+	if a.empty() {
+		return nil
+	}
+	hl := map[chess.Square]lipgloss.Style{}
+	for _, ar := range a.arrows {
+		hl[ar.from] = arrowSourceStyle
+		hl[ar.to] = arrowTargetStyle
+	}
+	for _, sq := range a.circles {
+		hl[sq] = circleStyle
+	}
+	return hl
+}
+
+// path returns the squares an arrow passes over between its ends. Arrows
+// that aren't along a line, such as knight moves, have none.
+func (ar arrow) path() []chess.Square {
+	df := int(ar.to.File()) - int(ar.from.File())
+	dr := int(ar.to.Rank()) - int(ar.from.Rank())
+	if df != 0 && dr != 0 && df != dr && df != -dr {
+		return nil
+	}
+	steps := max(abs(df), abs(dr))
+	var squares []chess.Square
+	for i := 1; i < steps; i++ {
+		file := int(ar.from.File()) + i*sign(df)
+		rank := int(ar.from.Rank()) + i*sign(dr)
+		squares = append(squares, chess.NewSquare(chess.File(file), chess.Rank(rank)))
+	}
+	return squares
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
+
+// annotationCommand matches the [%cal ...] and [%csl ...] commands that
+// lichess and ChessBase embed in PGN comments.
+var annotationCommand = regexp.MustCompile(`\[%(cal|csl)\s+([^\]]*)\]`)
+
+// parseAnnotation reads the arrows and circles of a PGN comment. Colors are
+// ignored.
+func parseAnnotation(comment string) annotation {
+	var a annotation
+	for _, match := range annotationCommand.FindAllStringSubmatch(comment, -1) {
+		for _, item := range strings.Split(match[2], ",") {
+			// a color letter followed by one or two squares, e.g. Ge2e4
+			item = strings.TrimSpace(item)
+			if len(item) < 3 {
+				continue
+			}
+			squares := item[1:]
+			switch {
+			case match[1] == "csl" && len(squares) == 2:
+				if sq, ok := parseSquare(squares); ok {
+					a.circles = append(a.circles, sq)
+				}
+			case match[1] == "cal" && len(squares) == 4:
+				from, ok1 := parseSquare(squares[:2])
+				to, ok2 := parseSquare(squares[2:])
+				if ok1 && ok2 {
+					a.arrows = append(a.arrows, arrow{from, to})
+				}
+			}
+		}
+	}
+	return a
+}
+
+// comment encodes the annotation as PGN comment commands, in green.
+func (a annotation) comment() string {
+	var parts []string
+	if len(a.circles) > 0 {
+		var items []string
+		for _, sq := range a.circles {
+			items = append(items, "G"+sq.String())
+		}
+		parts = append(parts, "[%csl "+strings.Join(items, ",")+"]")
+	}
+	if len(a.arrows) > 0 {
+		var items []string
+		for _, ar := range a.arrows {
+			items = append(items, "G"+ar.from.String()+ar.to.String())
+		}
+		parts = append(parts, "[%cal "+strings.Join(items, ",")+"]")
+	}
+	return strings.Join(parts, " ")
+}
+
+// stripAnnotation removes arrow and circle commands from a PGN comment.
+func stripAnnotation(comment string) string {
+	return strings.TrimSpace(annotationCommand.ReplaceAllString(comment, ""))
+}
+
+// gameAnnotations collects the annotations in the comments of a game,
+// keyed by the FEN of the position they were drawn on.
+func gameAnnotations(game *chess.Game) map[string]annotation {
+	annotations := map[string]annotation{}
+	positions := game.Positions()
+	for i, comments := range game.Comments() {
+		var a annotation
+		for _, c := range comments {
+			parsed := parseAnnotation(c)
+			a.arrows = append(a.arrows, parsed.arrows...)
+			a.circles = append(a.circles, parsed.circles...)
+		}
+		if !a.empty() && i+1 < len(positions) {
+			annotations[positions[i+1].String()] = a
+		}
+	}
+	return annotations
+}
+
+// annotate toggles an arrow, or a circle when from and to are the same
+// square, on the position shown. Annotations can only be drawn while
+// reviewing or analysing.
+func (m *model) annotate(from, to chess.Square) error {
+	if m.mode != modeReview && m.mode != modeAnalysis {
+		return errors.New("arrows and circles can only be drawn in review or analysis mode")
+	}
+	fen := m.displayedPosition().String()
+	a := m.annotations[fen]
+	if from == to {
+		a = a.toggleCircle(from)
+	} else {
+		a = a.toggleArrow(arrow{from, to})
+	}
+	if a.empty() {
+		delete(m.annotations, fen)
+	} else {
+		m.annotations[fen] = a
+	}
+	return nil
+}
+
+// clearAnnotation removes the arrows and circles from the position shown.
+func (m *model) clearAnnotation() {
+	delete(m.annotations, m.displayedPosition().String())
+}
+
+// shownAnnotation returns the annotation of the position on the board.
+func (m model) shownAnnotation() annotation {
+	if m.mode != modeReview && m.mode != modeAnalysis {
+		return annotation{}
+	}
+	return m.annotations[m.displayedPosition().String()]
+}
+
+// handleAnnotationMouse draws an arrow by dragging with the right button
+// from one square to another, or a circle by right-clicking a square. It
+// reports whether the event was used up.
+func (m *model) handleAnnotationMouse(msg tea.MouseMsg) bool {
+	sq, onBoard := m.screenSquare(msg.X, msg.Y)
+	switch {
+	case msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonRight:
+		m.annotating = onBoard && (m.mode == modeReview || m.mode == modeAnalysis)
+		m.annotateFrom = sq
+		return true
+	case msg.Action == tea.MouseActionRelease && m.annotating:
+		m.annotating = false
+		if onBoard {
+			m.error = m.annotate(m.annotateFrom, sq)
+		}
+		return true
+	}
+	return false
+}
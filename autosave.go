@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const autosaveFile = "autosave.pgn"
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so readers never see a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+type autosaveTickMsg struct{}
+
+// autosavedMsg reports the outcome of an autosave to the file at path.
+type autosavedMsg struct {
+	path string
+	err  error
+}
+
+func autosaveTick(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return autosaveTickMsg{}
+	})
+}
+
+// autosave returns a command writing the game being played to the
+// autosave file.
+func (m *model) autosave() tea.Cmd {
+	m.movesSinceAutosave = 0
+	pgn := exportPGN(m.liveGame(), m.annotations, m.engineNotes())
+	return func() tea.Msg {
+		path, err := configPath(autosaveFile)
+		if err == nil {
+			err = writeFileAtomic(path, []byte(pgn))
+		}
+		return autosavedMsg{path: path, err: err}
+	}
+}
+
+// autosaveAfterMove saves every autosaveMoves moves, if set.
+func (m *model) autosaveAfterMove() tea.Cmd {
+	if m.autosaveMoves <= 0 {
+		return nil
+	}
+	m.movesSinceAutosave++
+	if m.movesSinceAutosave < m.autosaveMoves {
+		return nil
+	}
+	return m.autosave()
+}
+
+func (m *model) handleAutosaved(msg autosavedMsg) {
+	if msg.err != nil {
+		m.error = msg.err
+		return
+	}
+	// remembered here rather than in the command, which may run alongside
+	// a save or load updating the list too
+	rememberGame(msg.path)
+	// don't push out a message the user may still be reading
+	if m.status == "" {
+		m.status = "Autosaved"
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// tempConfigDir points the config directory to a new temporary directory
+// for the test, returning the directory gochess keeps its files in.
+func tempConfigDir(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("AppData", filepath.Join(home, "AppData"))
+	dir, err := configDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestAutosaveRemembersTheGameInUpdate(t *testing.T) {
+	dir := tempConfigDir(t)
+	u := newUIModel(t)
+	u.enter("e4")
+
+	msg := u.m.autosave()()
+	if recent, err := readRecent(); err != nil || len(recent) != 0 {
+		t.Fatalf("recent games = %q, %v before the autosave is reported, want none", recent, err)
+	}
+	u.send(msg)
+	recent, err := readRecent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, autosaveFile); len(recent) != 1 || recent[0] != want {
+		t.Errorf("recent games = %q, want %q", recent, want)
+	}
+	if u.m.status != "Autosaved" {
+		t.Errorf("status %q, want Autosaved", u.m.status)
+	}
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// labelPlacement controls which edges of the board get rank/file labels.
+type labelPlacement int
+
+const (
+	labelsAll labelPlacement = iota
+	labelsLeftBottom
+	labelsNone
+)
+
+func parseLabelPlacement(s string) (labelPlacement, error) {
+	switch s {
+	case "all":
+		return labelsAll, nil
+	case "left-bottom":
+		return labelsLeftBottom, nil
+	case "none":
+		return labelsNone, nil
+	default:
+		return labelsAll, fmt.Errorf("unknown label placement %q (want all, left-bottom or none)", s)
+	}
+}
+
+// boardBorder is the frame drawn around the board in the UI.
+type boardBorder int
+
+const (
+	borderNone boardBorder = iota
+	borderRounded
+	borderDouble
+)
+
+func parseBoardBorder(s string) (boardBorder, error) {
+	switch s {
+	case "none":
+		return borderNone, nil
+	case "rounded":
+		return borderRounded, nil
+	case "double":
+		return borderDouble, nil
+	default:
+		return borderNone, fmt.Errorf("unknown border %q (want none, rounded or double)", s)
+	}
+}
+
+// style returns the style framing the board, in the given color or the
+// title color when it is empty.
+func (b boardBorder) style(color lipgloss.Color) lipgloss.Style {
+	if color == "" {
+		color = lipgloss.Color("#BC7342")
+	}
+	switch b {
+	case borderRounded:
+		return lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(color)
+	case borderDouble:
+		return lipgloss.NewStyle().Border(lipgloss.DoubleBorder()).BorderForeground(color)
+	default:
+		return lipgloss.NewStyle()
+	}
+}
+
+var unicodePieces = map[chess.Piece]string{
+	chess.WhiteKing:   "♔",
+	chess.WhiteQueen:  "♕",
+	chess.WhiteRook:   "♖",
+	chess.WhiteBishop: "♗",
+	chess.WhiteKnight: "♘",
+	chess.WhitePawn:   "♙",
+	chess.BlackKing:   "♚",
+	chess.BlackQueen:  "♛",
+	chess.BlackRook:   "♜",
+	chess.BlackBishop: "♝",
+	chess.BlackKnight: "♞",
+	chess.BlackPawn:   "♟",
+}
+
+// pieceSymbol returns how a piece is drawn on the board, following the
+// theme's piece set.
+func (opts boardOptions) pieceSymbol(piece chess.Piece) string {
+	switch opts.theme.pieces {
+	case piecesFigurine:
+		if !opts.noColor {
+			return unicodePieces[chess.NewPiece(piece.Type(), chess.Black)]
+		}
+		// without colors only the outlines tell the sides apart
+		return unicodePieces[piece]
+	case piecesUnicode:
+		return unicodePieces[piece]
+	}
+	letter := opts.locale.letter(piece.Type())
+	if opts.noColor && piece.Color() == chess.Black {
+		// without colors the case tells the sides apart, as in FEN
+		return strings.ToLower(letter)
+	}
+	return letter
+}
+
+// boardScale controls how many cells each square of the board takes up.
+type boardScale int
+
+const (
+	scaleNormal boardScale = iota
+	scaleCompact
+	scaleSmall
+	scaleLarge
+)
+
+func parseBoardScale(s string) (boardScale, error) {
+	switch s {
+	case "normal":
+		return scaleNormal, nil
+	case "compact":
+		return scaleCompact, nil
+	case "small":
+		return scaleSmall, nil
+	case "large":
+		return scaleLarge, nil
+	default:
+		return scaleNormal, fmt.Errorf("unknown board scale %q (want compact, small, normal or large)", s)
+	}
+}
+
+// squareSize returns the width and height of a single square in cells.
+func (s boardScale) squareSize() (width, height int) {
+	switch s {
+	case scaleCompact:
+		return 1, 1
+	case scaleSmall:
+		return 2, 1
+	case scaleLarge:
+		return 5, 2
+	default:
+		return 3, 1
+	}
+}
+
+// size returns the width and height of the rendered board in cells.
+func (opts boardOptions) size() (width, height int) {
+	squareWidth, squareHeight := opts.scale.squareSize()
+	width, height = 8*squareWidth, 8*squareHeight
+	switch opts.labels {
+	case labelsAll:
+		// rank labels on both sides, file labels above and below
+		width += 4
+		height += 2
+	case labelsLeftBottom:
+		width += 2
+		height++
+	}
+	return width, height
+}
+
+// boardOffset returns the position of the top-left square relative to the
+// top-left corner of the rendered board.
+func (opts boardOptions) boardOffset() (x, y int) {
+	if opts.labels != labelsNone {
+		x = 2
+	}
+	if opts.labels == labelsAll {
+		y = 1
+	}
+	return x, y
+}
+
+// squareAt maps a cell of the rendered board to the square drawn there.
+func (opts boardOptions) squareAt(x, y int) (chess.Square, bool) {
+	offX, offY := opts.boardOffset()
+	squareWidth, squareHeight := opts.scale.squareSize()
+	x, y = x-offX, y-offY
+	if x < 0 || y < 0 || x >= 8*squareWidth || y >= 8*squareHeight {
+		return chess.NoSquare, false
+	}
+	return opts.gridSquare(x/squareWidth, y/squareHeight), true
+}
+
+// gridSquare returns the square drawn in the given column and row of the
+// board, counted from the top-left square. Drawing and mapping cells back
+// to squares both go through it, so they agree in either orientation.
+func (opts boardOptions) gridSquare(col, row int) chess.Square {
+	if opts.flipped {
+		return chess.NewSquare(chess.File(7-col), chess.Rank(row))
+	}
+	return chess.NewSquare(chess.File(col), chess.Rank(7-row))
+}
+
+// enPassantTarget returns the square a pawn can capture en passant on, or
+// NoSquare. The chess package sets the en passant square after every double
+// pawn push, so it only counts when such a capture is legal.
+func enPassantTarget(pos *chess.Position) chess.Square {
+	ep := pos.EnPassantSquare()
+	if ep == chess.NoSquare {
+		return ep
+	}
+	for _, mv := range pos.ValidMoves() {
+		if mv.HasTag(chess.EnPassant) {
+			return ep
+		}
+	}
+	return chess.NoSquare
+}
+
+// edgeRank is the rank on the side's own edge of the board.
+func edgeRank(side chess.Color) int {
+	if side == chess.Black {
+		return 7
+	}
+	return 0
+}
+
+// turnMarker points at the edge of the side to move from its rank label,
+// in the side's piece color like the turn indicator under the board. Being
+// tied to a rank, it follows the board when it is flipped.
+func turnMarker(side chess.Color, t theme) string {
+	style := turnWhite
+	if side == chess.Black {
+		style = turnBlack
+	}
+	return style.Foreground(t.pieceStyle(side).GetForeground()).Render("▸")
+}
+
+func renderBoard(pos *chess.Position, width int, opts boardOptions) string {
+	board := pos.Board()
+	var sb strings.Builder
+
+	// Center the entire board block
+	boardWidth, _ := opts.size()
+	squareWidth, squareHeight := opts.scale.squareSize()
+	indentStr := strings.Repeat(" ", max((width-boardWidth)/2, 0))
+
+	leftLabels := opts.labels != labelsNone
+	rightLabels := opts.labels == labelsAll
+
+	ep := enPassantTarget(pos)
+	marks := opts.annotation.highlights()
+	dotted := map[chess.Square]bool{}
+	for _, ar := range opts.annotation.arrows {
+		for _, sq := range ar.path() {
+			dotted[sq] = true
+		}
+	}
+
+	// File labels - perfectly aligned under squares
+	var files strings.Builder
+	files.WriteString(indentStr)
+	if leftLabels {
+		files.WriteString("  ")
+	}
+	for col := range 8 {
+		files.WriteString(lipgloss.PlaceHorizontal(squareWidth, lipgloss.Center, opts.gridSquare(col, 0).File().String()))
+	}
+	if rightLabels {
+		files.WriteString("  ")
+	}
+
+	if opts.labels == labelsAll {
+		sb.WriteString(files.String())
+		sb.WriteString("\n")
+	}
+
+	for row := range 8 {
+		rank := int(opts.gridSquare(0, row).Rank())
+		// Squares may be several lines tall, so each row is joined
+		// horizontally from blocks. Rank labels sit on the first line.
+		cells := []string{indentStr}
+		if leftLabels && opts.turnMarker && rank == edgeRank(pos.Turn()) {
+			cells = append(cells, fmt.Sprintf("%d", rank+1)+turnMarker(pos.Turn(), opts.theme))
+		} else if leftLabels {
+			cells = append(cells, fmt.Sprintf("%d ", rank+1))
+		}
+
+		for col := range 8 {
+			sq := opts.gridSquare(col, row)
+			piece := board.Piece(sq)
+
+			squareStyle := opts.theme.squareStyle((int(sq.File())+rank)%2 == 0)
+			if hl, ok := marks[sq]; ok {
+				squareStyle = squareStyle.Background(hl.GetBackground())
+			}
+			if hl, ok := opts.highlights[sq]; ok {
+				squareStyle = squareStyle.Inherit(hl).Background(hl.GetBackground())
+			}
+			squareStyle = squareStyle.Width(squareWidth).Height(squareHeight)
+
+			pieceStyle := opts.theme.pieceOn(piece.Color(), squareStyle.GetBackground())
+			if opts.focus != nil && !opts.focus[sq] {
+				pieceStyle = pieceStyle.Faint(true)
+			}
+
+			// circles and bracketed highlights are drawn around the piece
+			// where they fit, highlights taking precedence
+			deco := opts.decorations[sq]
+			before, after := "", ""
+			if squareWidth >= 3 && slices.Contains(opts.annotation.circles, sq) {
+				before, after = "(", ")"
+			}
+			if deco.brackets && squareWidth >= 3 {
+				before, after = "[", "]"
+			} else if deco.brackets {
+				deco.underline = true
+			}
+			blank := " "
+			if deco.underline {
+				pieceStyle = pieceStyle.Underline(true)
+				blank = pieceStyle.Render(blank)
+			}
+			// coordinates only fit in squares at least two cells wide
+			if piece == chess.NoPiece && sq == ep {
+				marker := "ep"
+				if squareWidth < 2 {
+					marker = "*"
+				}
+				cells = append(cells, squareStyle.Render(enPassantStyle.Render(marker)))
+			} else if piece == chess.NoPiece && opts.showCoords && squareWidth >= 2 {
+				cells = append(cells, squareStyle.Render(coordStyle.Render(sq.String())))
+			} else if piece == chess.NoPiece && before != "" {
+				cells = append(cells, squareStyle.Render(before+blank+after))
+			} else if piece == chess.NoPiece && dotted[sq] {
+				cells = append(cells, squareStyle.Render("·"))
+			} else if piece == chess.NoPiece {
+				cells = append(cells, squareStyle.Render(blank))
+			} else {
+				notation := opts.pieceSymbol(piece)
+				cells = append(cells, squareStyle.Render(pieceStyle.Render(before+notation+after)))
+			}
+		}
+
+		if rightLabels {
+			cells = append(cells, fmt.Sprintf(" %d", rank+1))
+		}
+		sb.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, cells...))
+		if row < 7 {
+			sb.WriteString("\n")
+		}
+	}
+
+	if opts.labels != labelsNone {
+		sb.WriteString("\n")
+		sb.WriteString(files.String())
+	}
+	return sb.String()
+}
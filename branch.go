@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// branchHere plays on from the position shown while reviewing, in a new
+// tab: the user takes over the side to move, against the engine if there is
+// one. The game stays as it was in its own tab, and the branch tells where
+// it leaves the game and what was played there.
+func (m *model) branchHere() (tea.Cmd, error) {
+	if m.mode != modeReview {
+		return nil, errors.New("pick the position to branch from in review mode")
+	}
+	if len(m.sessions) >= maxTabs {
+		return nil, fmt.Errorf("at most %d games can be open", maxTabs)
+	}
+	original, ply, tab := m.game, m.viewPly, m.active+1
+	game, err := replayGame(original, ply)
+	if err != nil {
+		return nil, err
+	}
+	s := newSession()
+	if m.clock != nil {
+		s.clock = newChessClock(m.clock.timeControls())
+	}
+	s.flipped = m.flipped
+	if m.engine != nil {
+		s.engineColor = game.Position().Turn().Other()
+		s.flipped = s.engineColor == chess.White
+	}
+	m.sessions = append(m.sessions, s)
+	cmd := tea.Batch(m.switchTab(len(m.sessions)-1), m.startGame(game))
+	m.branchOf, m.branchPly = original, ply
+	m.status = fmt.Sprintf("Playing on from %s, the game is still in tab %d", plyNumber(gameStartPly(original)+ply), tab)
+	return cmd, nil
+}
+
+// branchNote compares a branch with the game it was taken from: where the
+// moves played differ, or what the game went on with while they don't.
+func (m model) branchNote() string {
+	if m.branchOf == nil {
+		return ""
+	}
+	played, original := m.game.Moves(), m.branchOf.Moves()
+	positions := m.branchOf.Positions()
+	for ply := m.branchPly; ply < len(original); ply++ {
+		san := chess.AlgebraicNotation{}.Encode(positions[ply], original[ply])
+		number := plyNumber(gameStartPly(m.branchOf) + ply)
+		switch {
+		case ply >= len(played):
+			return "The game went on with " + number + " " + san
+		case played[ply].String() != original[ply].String():
+			return "Left the game at " + number + ", where it went " + san
+		}
+	}
+	return "Past the end of the game"
+}
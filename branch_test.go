@@ -0,0 +1,112 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+func TestBranchNamesTheTabOfTheGame(t *testing.T) {
+	u := newUIModel(t)
+	u.enter("e4")
+	u.enter("e5")
+	// two more tabs, then back to the game in the first
+	for range 2 {
+		u.send(tea.KeyMsg{Type: tea.KeyCtrlT})
+	}
+	u.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}, Alt: true})
+	if u.m.active != 0 {
+		t.Fatalf("tab %d active, want the first", u.m.active+1)
+	}
+
+	u.send(tea.KeyMsg{Type: tea.KeyF3})
+	u.send(tea.KeyMsg{Type: tea.KeyLeft})
+	if _, err := u.m.branchHere(); err != nil {
+		t.Fatal(err)
+	}
+	if u.m.active != 3 {
+		t.Errorf("branch in tab %d, want a new fourth tab", u.m.active+1)
+	}
+	if !strings.Contains(u.m.status, "still in tab 1") {
+		t.Errorf("status %q doesn't point to the game in tab 1", u.m.status)
+	}
+	if got := moveList(u.m.game); got != "e4" {
+		t.Errorf("branch has the moves %q, want e4", got)
+	}
+}
+
+// engineModel plays against an engine that is never asked to move, its
+// commands not being run.
+func engineModel(t *testing.T, engineColor chess.Color) *uiModel {
+	t.Helper()
+	u := newUIModel(t)
+	u.m.engine = newFakeEngine(t, func(string) []string { return nil }).uciEngine
+	u.m.engineColor = engineColor
+	return u
+}
+
+// wantSides checks the engine side and orientation of tab i.
+func (u *uiModel) wantSides(i int, engineColor chess.Color, flipped bool) {
+	u.t.Helper()
+	u.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{rune('1' + i)}, Alt: true})
+	if u.m.active != i {
+		u.t.Fatalf("tab %d active, want %d", u.m.active+1, i+1)
+	}
+	if u.m.engineColor != engineColor || u.m.flipped != flipped {
+		u.t.Errorf("tab %d has the engine playing %s, flipped %t, want %s, %t", i+1, u.m.engineColor.Name(), u.m.flipped, engineColor.Name(), flipped)
+	}
+}
+
+func TestBranchKeepsTheSidesOfTheGame(t *testing.T) {
+	u := engineModel(t, chess.Black)
+	u.m.startGame(newTestGame(t, "e4", "e5", "Nf3"))
+	u.send(tea.KeyMsg{Type: tea.KeyF3})
+	u.send(tea.KeyMsg{Type: tea.KeyHome})
+	u.send(tea.KeyMsg{Type: tea.KeyRight})
+	// Black to move, so the user takes Black in the branch
+	if _, err := u.m.branchHere(); err != nil {
+		t.Fatal(err)
+	}
+	u.wantSides(0, chess.Black, false)
+	u.wantSides(1, chess.White, true)
+}
+
+func TestRematchKeepsOtherTabs(t *testing.T) {
+	u := engineModel(t, chess.Black)
+	if _, err := u.m.openTab(); err != nil {
+		t.Fatal(err)
+	}
+	u.m.rematch()
+	u.wantSides(0, chess.Black, false)
+	u.wantSides(1, chess.White, true)
+}
+
+func TestBranchNumbersFromTheStart(t *testing.T) {
+	tests := []struct {
+		name string
+		game *chess.Game
+		ply  int
+		want string
+	}{
+		{"standard start", newTestGame(t, "e4", "e5", "Nf3"), 2, "Playing on from 2."},
+		{"Black to move", newTestGame(t, "e4", "e5", "Nf3"), 3, "Playing on from 2..."},
+		{"set up at move 34", newTestGameFrom(t, blackToMove, "Nf6", "Nc3"), 1, "Playing on from 35."},
+		{"set up with Black to move", newTestGameFrom(t, blackToMove, "Nf6", "Nc3"), 0, "Playing on from 34..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUIModel(t)
+			u.m.startGame(tt.game)
+			u.send(tea.KeyMsg{Type: tea.KeyF3})
+			u.m.viewPly = tt.ply
+			if _, err := u.m.branchHere(); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(u.m.status, tt.want+",") {
+				t.Errorf("status %q, want it to start %q", u.m.status, tt.want)
+			}
+		})
+	}
+}
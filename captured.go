@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// captureOrder is how the captured pieces beside the board are sorted, if
+// they are shown.
+type captureOrder int
+
+const (
+	capturesOff        captureOrder = iota
+	capturesAscending               // pawns first
+	capturesDescending              // queens first
+)
+
+func parseCaptureOrder(s string) (captureOrder, error) {
+	switch s {
+	case "off":
+		return capturesOff, nil
+	case "asc":
+		return capturesAscending, nil
+	case "desc":
+		return capturesDescending, nil
+	default:
+		return capturesOff, fmt.Errorf("unknown order %q (want asc, desc or off)", s)
+	}
+}
+
+// capturedPieces returns the pieces taken in the first ply moves of the
+// game, by the side that took them. A piece counts as what stood on the
+// board when it was taken, so a promoted pawn is taken as a queen.
+func capturedPieces(game *chess.Game, ply int) map[chess.Color][]chess.Piece {
+	positions := game.Positions()
+	captured := map[chess.Color][]chess.Piece{}
+	for i, mv := range game.Moves()[:ply] {
+		pos := positions[i]
+		var victim chess.Piece
+		switch {
+		case mv.HasTag(chess.EnPassant):
+			victim = chess.NewPiece(chess.Pawn, pos.Turn().Other())
+		case mv.HasTag(chess.Capture):
+			victim = pos.Board().Piece(mv.S2())
+		default:
+			continue
+		}
+		captured[pos.Turn()] = append(captured[pos.Turn()], victim)
+	}
+	return captured
+}
+
+// materialValue adds up the pieces' values in pawns.
+func materialValue(pieces []chess.Piece) int {
+	total := 0
+	for _, p := range pieces {
+		total += pieceValues[p.Type()]
+	}
+	return total
+}
+
+// groupCaptures sorts pieces by value in the given order and groups those
+// of a type together. Knights rank just below bishops of the same value, so
+// they come before them in ascending order and after them in descending.
+func groupCaptures(pieces []chess.Piece, order captureOrder) [][]chess.Piece {
+	rank := func(p chess.Piece) int {
+		// chess.Knight comes after chess.Bishop, and pawns last
+		return pieceValues[p.Type()]*10 + int(chess.Pawn-p.Type())
+	}
+	sorted := slices.Clone(pieces)
+	slices.SortStableFunc(sorted, func(a, b chess.Piece) int {
+		if order == capturesDescending {
+			return rank(b) - rank(a)
+		}
+		return rank(a) - rank(b)
+	})
+	var groups [][]chess.Piece
+	for i, p := range sorted {
+		if i == 0 || p.Type() != sorted[i-1].Type() {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], p)
+	}
+	return groups
+}
+
+// capturedGroups draws pieces taken by a side, grouped as the captures are
+// ordered. The pieces stand on the theme's light square color so that both
+// sides show.
+func (m model) capturedGroups(pieces []chess.Piece) []string {
+	opts := m.boardOptions()
+	background := m.theme.squareStyle(false).GetBackground()
+	var groups []string
+	for _, group := range groupCaptures(pieces, m.captureOrder) {
+		var glyphs strings.Builder
+		for _, p := range group {
+			glyphs.WriteString(opts.pieceSymbol(p))
+		}
+		style := m.theme.pieceOn(group[0].Color(), background).Background(background)
+		groups = append(groups, style.Render(glyphs.String()))
+	}
+	return groups
+}
+
+// renderCaptured draws the pieces each side has taken in the position on
+// the board, e.g. "♟♟♟ ♞ +2 · ♙ ♗", the advantage next to the side ahead.
+func (m model) renderCaptured(boardWidth int) string {
+	captured := m.shownCaptures()
+	net := materialValue(captured[chess.White]) - materialValue(captured[chess.Black])
+
+	var sides []string
+	for _, side := range []chess.Color{chess.White, chess.Black} {
+		groups := m.capturedGroups(captured[side])
+		if len(groups) == 0 {
+			groups = []string{coordStyle.Render("—")}
+		}
+		if side == chess.White && net > 0 || side == chess.Black && net < 0 {
+			groups = append(groups, coordStyle.Render(fmt.Sprintf("+%d", max(net, -net))))
+		}
+		sides = append(sides, strings.Join(groups, " "))
+	}
+	line := strings.Join(sides, coordStyle.Render(" · "))
+	return lipgloss.PlaceHorizontal(boardWidth, lipgloss.Center, line)
+}
+
+// materialView is how the material balance is shown under the board.
+type materialView int
+
+const (
+	materialPieces materialView = iota // the captured pieces, with -captured
+	materialBar                        // a bar filled toward the side ahead
+)
+
+func parseMaterialView(s string) (materialView, error) {
+	switch s {
+	case "pieces":
+		return materialPieces, nil
+	case "bar":
+		return materialBar, nil
+	default:
+		return materialPieces, fmt.Errorf("unknown material view %q (want pieces or bar)", s)
+	}
+}
+
+// materialBarCap is the advantage in pawns that fills the bar.
+const materialBarCap = 10
+
+// renderMaterialBar draws the material balance of the position on the
+// board as a bar as wide as the board, White's share on the left in the
+// light square color and Black's on the right in the dark one. It is half
+// and half when material is even and fills toward the side ahead, with the
+// advantage written on its side.
+func (m model) renderMaterialBar(boardWidth int) string {
+	net := 0
+	for _, p := range m.displayedPosition().Board().SquareMap() {
+		if p.Color() == chess.White {
+			net += pieceValues[p.Type()]
+		} else {
+			net -= pieceValues[p.Type()]
+		}
+	}
+	capped := min(max(net, -materialBarCap), materialBarCap)
+	white := boardWidth * (capped + materialBarCap) / (2 * materialBarCap)
+	whiteText, blackText := "", ""
+	switch {
+	case net > 0:
+		whiteText = fmt.Sprintf(" +%d", net)
+	case net < 0:
+		blackText = fmt.Sprintf("+%d ", -net)
+	}
+	light := m.theme.squareStyle(false).GetBackground()
+	dark := m.theme.squareStyle(true).GetBackground()
+	left := lipgloss.NewStyle().Background(light).Foreground(dark).Width(white)
+	right := lipgloss.NewStyle().Background(dark).Foreground(light).Width(boardWidth - white).Align(lipgloss.Right)
+	return left.Render(fitWidth(whiteText, white)) + right.Render(fitWidth(blackText, boardWidth-white))
+}
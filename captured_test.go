@@ -0,0 +1,64 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+// groupLetters writes groups of pieces as letters, e.g. "PP N B".
+func groupLetters(groups [][]chess.Piece) string {
+	var words []string
+	for _, group := range groups {
+		var letters strings.Builder
+		for _, p := range group {
+			letters.WriteString(strings.ToUpper(p.Type().String()))
+		}
+		words = append(words, letters.String())
+	}
+	return strings.Join(words, " ")
+}
+
+func TestGroupCaptures(t *testing.T) {
+	pieces := []chess.Piece{
+		chess.BlackBishop, chess.BlackPawn, chess.BlackQueen, chess.BlackKnight,
+		chess.BlackPawn, chess.BlackRook, chess.BlackBishop, chess.BlackKnight,
+	}
+	tests := []struct {
+		order captureOrder
+		want  string
+	}{
+		{capturesAscending, "PP NN BB R Q"},
+		{capturesDescending, "Q R BB NN PP"},
+	}
+	for _, tt := range tests {
+		before := slices.Clone(pieces)
+		if got := groupLetters(groupCaptures(pieces, tt.order)); got != tt.want {
+			t.Errorf("groupCaptures in order %d = %q, want %q", tt.order, got, tt.want)
+		}
+		if !slices.Equal(pieces, before) {
+			t.Errorf("groupCaptures in order %d reordered the pieces given", tt.order)
+		}
+	}
+	if groups := groupCaptures(nil, capturesAscending); len(groups) != 0 {
+		t.Errorf("groupCaptures of no pieces = %v, want none", groups)
+	}
+}
+
+func TestCapturedPieces(t *testing.T) {
+	// a knight and a bishop traded, and an en passant capture
+	game := newTestGame(t, "e4", "d5", "exd5", "Nf6", "Bb5+", "Bd7", "Bxd7+", "Nbxd7", "c4", "c5", "dxc6")
+	captured := capturedPieces(game, len(game.Moves()))
+	if got := groupLetters(groupCaptures(captured[chess.White], capturesAscending)); got != "PP B" {
+		t.Errorf("White took %q, want PP B", got)
+	}
+	if got := groupLetters(groupCaptures(captured[chess.Black], capturesAscending)); got != "B" {
+		t.Errorf("Black took %q, want B", got)
+	}
+	// up to a ply, not past it
+	if got := capturedPieces(game, 3)[chess.White]; len(got) != 1 || got[0] != chess.BlackPawn {
+		t.Errorf("White took %v in the first 3 plies, want a pawn", got)
+	}
+}
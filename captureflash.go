@@ -0,0 +1,92 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+const (
+	captureFrames        = 4
+	captureFrameInterval = 70 * time.Millisecond
+)
+
+var captureFlashStyle = lipgloss.NewStyle().Background(lipgloss.Color("#E07B67"))
+
+// captureFrameMsg advances the capture animation started with the given
+// sequence number.
+type captureFrameMsg int
+
+// captureFlash is a capture being animated: the position before it, with
+// the square of the piece taken flashing, until the capturing piece
+// settles on the board.
+type captureFlash struct {
+	before *chess.Position
+	square chess.Square // the square the captured piece stood on
+	frame  int          // 0 when not animating
+	seq    int
+}
+
+// capturedSquare returns the square of the piece mv takes, which for en
+// passant is not the square the pawn moves to.
+func capturedSquare(mv *chess.Move) chess.Square {
+	if mv.HasTag(chess.EnPassant) {
+		return chess.NewSquare(mv.S2().File(), mv.S1().Rank())
+	}
+	return mv.S2()
+}
+
+// startCaptureFlash animates the last move of the game if it was a capture
+// and -animate-captures is set.
+func (m *model) startCaptureFlash() tea.Cmd {
+	moves := m.game.Moves()
+	if !m.animateCaptures || len(moves) == 0 {
+		return nil
+	}
+	mv := moves[len(moves)-1]
+	if !mv.HasTag(chess.Capture) && !mv.HasTag(chess.EnPassant) {
+		return nil
+	}
+	positions := m.game.Positions()
+	m.capture.before = positions[len(positions)-2]
+	m.capture.square = capturedSquare(mv)
+	m.capture.frame = 1
+	m.capture.seq++
+	return nextCaptureFrame(m.capture.seq)
+}
+
+func nextCaptureFrame(seq int) tea.Cmd {
+	return tea.Tick(captureFrameInterval, func(time.Time) tea.Msg {
+		return captureFrameMsg(seq)
+	})
+}
+
+// handleCaptureFrame moves the animation on, ignoring frames of an
+// animation that was skipped or restarted.
+func (m *model) handleCaptureFrame(msg captureFrameMsg) tea.Cmd {
+	if int(msg) != m.capture.seq || m.capture.frame == 0 {
+		return nil
+	}
+	m.capture.frame++
+	if m.capture.frame >= captureFrames {
+		m.capture.frame = 0
+		return nil
+	}
+	return nextCaptureFrame(m.capture.seq)
+}
+
+// captureBoard returns the position and board options of the current frame
+// of the capture animation: the captured square flashes on odd frames.
+func (m model) captureBoard(opts boardOptions) (*chess.Position, boardOptions) {
+	if m.capture.frame%2 == 1 {
+		highlights := make(map[chess.Square]lipgloss.Style, len(opts.highlights)+1)
+		for sq, style := range opts.highlights {
+			highlights[sq] = style
+		}
+		highlights[m.capture.square] = captureFlashStyle
+		opts.highlights = highlights
+	}
+	return m.capture.before, opts
+}
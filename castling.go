@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// castlingRights describes the castling rights left to both sides, e.g.
+// "White: O-O O-O-O · Black: O-O". When that doesn't fit in width they are
+// given as in FEN instead.
+func castlingRights(pos *chess.Position, width int) string {
+	rights := pos.CastleRights()
+	sides := []string{}
+	for _, color := range []chess.Color{chess.White, chess.Black} {
+		var castles []string
+		if rights.CanCastle(color, chess.KingSide) {
+			castles = append(castles, "O-O")
+		}
+		if rights.CanCastle(color, chess.QueenSide) {
+			castles = append(castles, "O-O-O")
+		}
+		if len(castles) == 0 {
+			castles = []string{"—"}
+		}
+		sides = append(sides, color.Name()+": "+strings.Join(castles, " "))
+	}
+	line := strings.Join(sides, " · ")
+	if lipgloss.Width(line) > width {
+		line = rights.String()
+	}
+	return line
+}
+
+// renderCastlingRights draws the castling rights line centered under the
+// board.
+func renderCastlingRights(pos *chess.Position, boardWidth int) string {
+	return coordStyle.Width(boardWidth).Align(lipgloss.Center).Render(castlingRights(pos, boardWidth))
+}
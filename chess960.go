@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// standard960 is the Scharnagl number of the standard starting position.
+const standard960 = 518
+
+// knightPlacements lists, by Scharnagl knight code, which two of the five
+// squares left after placing the bishops and queen hold the knights.
+var knightPlacements = [10][2]int{
+	{0, 1}, {0, 2}, {0, 3}, {0, 4}, {1, 2},
+	{1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4},
+}
+
+// backRank960 returns the back rank of Chess960 position id (0–959) as
+// white piece letters from the a- to the h-file, following Scharnagl's
+// numbering.
+func backRank960(id int) (string, error) {
+	if id < 0 || id > 959 {
+		return "", fmt.Errorf("chess960 position %d out of range (want 0-959)", id)
+	}
+	var rank [8]byte
+	n := id
+	rank[2*(n%4)+1] = 'B' // light-squared bishop on b, d, f or h
+	n /= 4
+	rank[2*(n%4)] = 'B' // dark-squared bishop on a, c, e or g
+	n /= 4
+
+	// place a piece on the i-th still empty square
+	place := func(i int, piece byte) {
+		for file := range rank {
+			if rank[file] != 0 {
+				continue
+			}
+			if i == 0 {
+				rank[file] = piece
+				return
+			}
+			i--
+		}
+	}
+	place(n%6, 'Q')
+	n /= 6
+	knights := knightPlacements[n]
+	// the second knight's index shrinks by one once the first is placed
+	place(knights[0], 'N')
+	place(knights[1]-1, 'N')
+	// rook, king and rook take the remaining squares in that order
+	place(0, 'R')
+	place(0, 'K')
+	place(0, 'R')
+	return string(rank[:]), nil
+}
+
+// fen960 returns the starting FEN of a Chess960 position. The chess package
+// only knows standard castling, so castling rights are given for the
+// standard position alone.
+func fen960(id int) (string, error) {
+	back, err := backRank960(id)
+	if err != nil {
+		return "", err
+	}
+	castling := "-"
+	if id == standard960 {
+		castling = "KQkq"
+	}
+	return fmt.Sprintf("%s/pppppppp/8/8/8/8/PPPPPPPP/%s w %s - 0 1", strings.ToLower(back), back, castling), nil
+}
+
+// newGame960 starts a game from a Chess960 position.
+func newGame960(id int) (*chess.Game, error) {
+	fen, err := fen960(id)
+	if err != nil {
+		return nil, err
+	}
+	opt, err := chess.FEN(fen)
+	if err != nil {
+		return nil, err
+	}
+	return chess.NewGame(opt), nil
+}
+
+// isChess960 reports whether a loaded game says it is Chess960 in its
+// Variant tag, as "Chess960" or "Fischerandom".
+func isChess960(game *chess.Game) bool {
+	tag := game.GetTagPair("Variant")
+	if tag == nil {
+		return false
+	}
+	variant := strings.ToLower(tag.Value)
+	return strings.Contains(variant, "960") || strings.Contains(variant, "fischerandom")
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+const clockTickInterval = 100 * time.Millisecond
+
+var (
+	clockStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#BC7342")).
+			Padding(0, 1)
+
+	activeClockStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FFFDF5")).
+				Background(lipgloss.Color("#BC7342")).
+				Padding(0, 1)
+)
+
+type clockTickMsg time.Time
+
+// incrementMode is how a clock gives time back after a move.
+type incrementMode int
+
+const (
+	// fischer adds the full increment after every move.
+	fischer incrementMode = iota
+	// bronstein gives back the time used on the move, up to the delay.
+	bronstein
+)
+
+func (mode incrementMode) String() string {
+	if mode == bronstein {
+		return "Bronstein"
+	}
+	return "Fischer"
+}
+
+// timeControl is the time each player starts with plus their increment.
+type timeControl struct {
+	initial   time.Duration
+	increment time.Duration
+	mode      incrementMode
+}
+
+// parseTimeControl reads time controls written as minutes and seconds:
+// "3+2" is three minutes with a two second Fischer increment, "3d2" three
+// minutes with a two second Bronstein delay.
+func parseTimeControl(s string) (timeControl, error) {
+	tc := timeControl{mode: fischer}
+	sep := strings.IndexAny(s, "+d")
+	if sep < 0 {
+		return tc, fmt.Errorf("bad time control %q (want e.g. 3+2 or 3d2)", s)
+	}
+	if s[sep] == 'd' {
+		tc.mode = bronstein
+	}
+	minutes, err := strconv.ParseFloat(s[:sep], 64)
+	if err != nil || minutes <= 0 {
+		return tc, fmt.Errorf("bad time control %q: invalid minutes", s)
+	}
+	seconds, err := strconv.ParseFloat(s[sep+1:], 64)
+	if err != nil || seconds < 0 {
+		return tc, fmt.Errorf("bad time control %q: invalid seconds", s)
+	}
+	tc.initial = time.Duration(minutes * float64(time.Minute))
+	tc.increment = time.Duration(seconds * float64(time.Second))
+	return tc, nil
+}
+
+// parseTimeOdds reads a time control shared by both players, or a
+// White:Black pair such as "5+0:1+0" giving them different times.
+func parseTimeOdds(s string) (white, black timeControl, err error) {
+	w, b, odds := strings.Cut(s, ":")
+	if white, err = parseTimeControl(w); err != nil {
+		return white, black, err
+	}
+	if !odds {
+		return white, white, nil
+	}
+	black, err = parseTimeControl(b)
+	return white, black, err
+}
+
+// String formats the time control the way parseTimeControl reads it.
+func (tc timeControl) String() string {
+	sep := "+"
+	if tc.mode == bronstein {
+		sep = "d"
+	}
+	minutes := strconv.FormatFloat(tc.initial.Minutes(), 'f', -1, 64)
+	seconds := strconv.FormatFloat(tc.increment.Seconds(), 'f', -1, 64)
+	return minutes + sep + seconds
+}
+
+// chessClock tracks the remaining time of both players, who may have
+// different time controls.
+type chessClock struct {
+	controls  map[chess.Color]timeControl
+	remaining map[chess.Color]time.Duration
+	moveStart time.Duration // remaining time of the side to move when its turn began
+	lastTick  time.Time
+	flagged   chess.Color // side that ran out of time, if any
+	running   bool        // whether a tick loop is scheduled
+
+	// by ply, the time each move took, the time given back for it and the
+	// time its side had left after it
+	spent, gained, left []time.Duration
+}
+
+func newChessClock(white, black timeControl) *chessClock {
+	c := &chessClock{controls: map[chess.Color]timeControl{chess.White: white, chess.Black: black}}
+	c.reset(chess.White)
+	return c
+}
+
+// reset gives both players their initial time again, for a game in which
+// first moves first.
+func (c *chessClock) reset(first chess.Color) {
+	c.remaining = map[chess.Color]time.Duration{
+		chess.White: c.controls[chess.White].initial,
+		chess.Black: c.controls[chess.Black].initial,
+	}
+	c.flagged = chess.NoColor
+	c.lastTick = time.Now()
+	c.moveStart = c.remaining[first]
+	c.spent, c.gained, c.left = nil, nil, nil
+}
+
+// start schedules the tick loop unless it is already running.
+func (c *chessClock) start() tea.Cmd {
+	if c.running {
+		return nil
+	}
+	c.running = true
+	c.lastTick = time.Now()
+	return clockTick()
+}
+
+func clockTick() tea.Cmd {
+	return tea.Tick(clockTickInterval, func(t time.Time) tea.Msg {
+		return clockTickMsg(t)
+	})
+}
+
+// tick charges the time elapsed since the previous tick to the given side.
+// When paused the elapsed time is discarded instead. It reports whether the
+// side has run out of time.
+func (c *chessClock) tick(now time.Time, side chess.Color, paused bool) bool {
+	elapsed := now.Sub(c.lastTick)
+	c.lastTick = now
+	if paused {
+		return false
+	}
+	c.remaining[side] -= elapsed
+	if c.remaining[side] <= 0 {
+		c.remaining[side] = 0
+		c.flagged = side
+		return true
+	}
+	return false
+}
+
+// moved adds the increment for the side that just completed its move, the
+// ply-th of the game, and records the time it took. Moves taken back are
+// recorded over; moves played before the clock started count as unknown,
+// with no time recorded.
+func (c *chessClock) moved(side chess.Color, ply int) {
+	tc := c.controls[side]
+	used := max(c.moveStart-c.remaining[side], 0)
+	var gained time.Duration
+	switch tc.mode {
+	case fischer:
+		gained = tc.increment
+	case bronstein:
+		gained = min(used, tc.increment)
+	}
+	c.remaining[side] += gained
+	c.moveStart = c.remaining[side.Other()]
+
+	for len(c.spent) < ply {
+		c.spent, c.gained, c.left = append(c.spent, 0), append(c.gained, 0), append(c.left, 0)
+	}
+	c.spent = append(c.spent[:ply], used)
+	c.gained = append(c.gained[:ply], gained)
+	c.left = append(c.left[:ply], c.remaining[side])
+}
+
+// takeBack sets the clocks back to where they were after the first plies
+// moves of a game first moves first in, for moves taken back: each side
+// gets the time it had left after its last move kept, or its initial time
+// when it has none. A side with a kept move played before the clock
+// started keeps the time it has.
+func (c *chessClock) takeBack(plies int, first chess.Color) {
+	for _, side := range []chess.Color{chess.White, chess.Black} {
+		left, known := c.controls[side].initial, true
+		i := 0
+		if side != first {
+			i = 1
+		}
+		for ; i < plies; i += 2 {
+			if i < len(c.left) && c.left[i] > 0 {
+				left, known = c.left[i], true
+			} else {
+				known = false
+			}
+		}
+		if known {
+			c.remaining[side] = left
+		}
+	}
+	toMove := first
+	if plies%2 == 1 {
+		toMove = first.Other()
+	}
+	c.flagged = chess.NoColor
+	c.moveStart = c.remaining[toMove]
+	c.lastTick = time.Now()
+	n := min(plies, len(c.spent))
+	c.spent, c.gained, c.left = c.spent[:n], c.gained[:n], c.left[:n]
+}
+
+// usage adds up the time a side has spent on its moves and been given back
+// for them, over the first plies of a game first moves to make, and counts
+// its moves.
+func (c *chessClock) usage(side, first chess.Color, plies int) (spent, gained time.Duration, moves int) {
+	for i := 0; i < min(plies, len(c.spent)); i++ {
+		if (i%2 == 0) == (side == first) && c.left[i] > 0 {
+			spent += c.spent[i]
+			gained += c.gained[i]
+			moves++
+		}
+	}
+	return spent, gained, moves
+}
+
+// timeControls returns the time controls the clock was set up with.
+func (c *chessClock) timeControls() (white, black timeControl) {
+	return c.controls[chess.White], c.controls[chess.Black]
+}
+
+// label describes the time controls, as White:Black when they differ.
+func (c *chessClock) label() string {
+	white, black := c.timeControls()
+	if white == black {
+		return white.String() + " " + white.mode.String()
+	}
+	if white.mode == black.mode {
+		return white.String() + ":" + black.String() + " " + white.mode.String()
+	}
+	return white.String() + " " + white.mode.String() + " : " + black.String() + " " + black.mode.String()
+}
+
+func formatClock(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+func (m model) renderClocks() string {
+	clocks := make([]string, 0, 2)
+	for _, side := range []chess.Color{chess.White, chess.Black} {
+		style := clockStyle
+		if side == m.game.Position().Turn() && m.game.Outcome() == chess.NoOutcome {
+			style = activeClockStyle
+		}
+		clocks = append(clocks, style.Render(side.Name()+" "+formatClock(m.clock.remaining[side])))
+	}
+	label := clockStyle.Faint(true).Render(m.clock.label())
+	return lipgloss.JoinHorizontal(lipgloss.Top, clocks[0], "  ", clocks[1], "  ", label)
+}
+
+// renderTimeSummary accounts for the time of each side so far: how long
+// its moves took in all and on average, the time its increments gave back
+// and how long the last move took, e.g.
+// "White 12 moves in 03:10, 00:16 each, +00:24 · Black … · last move 00:21".
+func (m model) renderTimeSummary() string {
+	plies := len(m.game.Moves())
+	first := m.game.Positions()[0].Turn()
+	var parts []string
+	for _, side := range []chess.Color{chess.White, chess.Black} {
+		spent, gained, moves := m.clock.usage(side, first, plies)
+		if moves == 0 {
+			continue
+		}
+		text := fmt.Sprintf("%s %d moves in %s, %s each", side.Name(), moves, formatClock(spent), formatClock(spent/time.Duration(moves)))
+		if gained > 0 {
+			text += ", +" + formatClock(gained)
+		}
+		parts = append(parts, text)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	if last := min(plies, len(m.clock.spent)) - 1; last >= 0 {
+		parts = append(parts, "last move "+formatClock(m.clock.spent[last]))
+	}
+	return clockStyle.Faint(true).Render(strings.Join(parts, " · "))
+}
+
+// clkText formats the time left the way [%clk] takes it, as h:mm:ss.
+func clkText(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
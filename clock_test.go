@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/notnil/chess"
+)
+
+// clockedModel is a game with 5+0 clocks.
+func clockedModel(t *testing.T) *uiModel {
+	t.Helper()
+	u := newUIModel(t)
+	tc := timeControl{initial: 5 * time.Minute}
+	u.m.clock = newChessClock(tc, tc)
+	return u
+}
+
+// tick lets d pass on the clock.
+func (u *uiModel) tick(d time.Duration) {
+	u.t.Helper()
+	u.send(clockTickMsg(u.m.clock.lastTick.Add(d)))
+}
+
+func TestClockPausedByPrompts(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(u *uiModel)
+	}{
+		{"resign confirmation", func(u *uiModel) { u.enter("resign") }},
+		{"staged move", func(u *uiModel) {
+			u.m.confirmMoves = true
+			u.enter("e4")
+		}},
+		{"coach warning", func(u *uiModel) {
+			u.m.pendingMove = u.m.validMoves[0]
+		}},
+		{"overwrite prompt", func(u *uiModel) { u.m.pendingSave = "game.pgn" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := clockedModel(t)
+			tt.setup(u)
+			if !u.m.promptPending() {
+				t.Fatal("no prompt pending")
+			}
+			u.tick(time.Minute)
+			if got := u.m.clock.remaining[chess.White]; got != 5*time.Minute {
+				t.Errorf("White has %s left after a minute at the prompt, want 5m0s", got)
+			}
+		})
+	}
+}
+
+func TestClockRunsWithoutPrompt(t *testing.T) {
+	u := clockedModel(t)
+	u.tick(time.Minute)
+	if got := u.m.clock.remaining[chess.White]; got != 4*time.Minute {
+		t.Errorf("White has %s left after a minute, want 4m0s", got)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// pieceValues is the usual material count in pawns. The king has no value
+// as it can never be won.
+var pieceValues = map[chess.PieceType]int{
+	chess.Queen:  9,
+	chess.Rook:   5,
+	chess.Bishop: 3,
+	chess.Knight: 3,
+	chess.Pawn:   1,
+}
+
+// blunderWarning describes the queen or rook that playing mv would hang:
+// one the opponent can take where it can't be recaptured, or with a cheaper
+// piece. Material won by mv itself is counted so that trades aren't flagged.
+// It returns "" when mv looks safe.
+func blunderWarning(pos *chess.Position, mv *chess.Move) string {
+	after := pos.Update(mv)
+	if after.Status() != chess.NoMethod {
+		// the game is over
+		return ""
+	}
+	won := pieceValues[pos.Board().Piece(mv.S2()).Type()]
+	for _, reply := range after.ValidMoves() {
+		victim := after.Board().Piece(reply.S2())
+		if victim.Type() != chess.Queen && victim.Type() != chess.Rook {
+			continue
+		}
+		lost := pieceValues[victim.Type()]
+		if canCapture(after.Update(reply), reply.S2()) {
+			lost -= pieceValues[after.Board().Piece(reply.S1()).Type()]
+		}
+		if lost > won {
+			return fmt.Sprintf("your %s on %s can be taken for free", strings.ToLower(pieceName(victim.Type())), reply.S2())
+		}
+	}
+	return ""
+}
+
+// canCapture reports whether the side to move can take on sq.
+func canCapture(pos *chess.Position, sq chess.Square) bool {
+	for _, mv := range pos.ValidMoves() {
+		if mv.S2() == sq {
+			return true
+		}
+	}
+	return false
+}
+
+// pieceName is the English name of a piece type.
+func pieceName(t chess.PieceType) string {
+	for _, group := range legalMoveGroups {
+		if group.piece == t {
+			return strings.TrimSuffix(group.name, "s")
+		}
+	}
+	return ""
+}
+
+// playMove plays a move entered by the user, answering it from the book
+// when drilling an opening and from the solution when studying. With
+// -coach a move that hangs a queen or rook is held back until the user
+// confirms it.
+func (m *model) playMove(mv *chess.Move) tea.Cmd {
+	if m.match != nil && m.mode == modePlay {
+		m.error = errors.New("the engines are playing the match, f4 to try moves in analysis")
+		return nil
+	}
+	if err := m.checkRepertoire(mv); err != nil {
+		m.error = err
+		return nil
+	}
+	if err := m.checkStudy(mv); err != nil {
+		m.error = err
+		return nil
+	}
+	if m.coach && m.pendingMove == nil {
+		if warning := blunderWarning(m.game.Position(), mv); warning != "" {
+			m.pendingMove = mv
+			m.coachWarning = warning
+			return nil
+		}
+	}
+	m.pendingMove = nil
+	if err := m.game.Move(mv); err != nil {
+		m.error = err
+		return nil
+	}
+	m.textInput.Reset()
+	cmd := m.moveApplied()
+	m.drillProgress()
+	m.studyProgress()
+	return cmd
+}
+
+// answerCoach handles the key pressed while the coach waits for a
+// confirmation. Enter or y plays the move and any other key takes it back;
+// it reports whether the key was used up.
+func (m *model) answerCoach(msg tea.KeyMsg) (tea.Cmd, bool) {
+	mv := m.pendingMove
+	switch msg.String() {
+	case "enter", "y":
+		return m.playMove(mv), true
+	case "esc", "n":
+		m.pendingMove = nil
+		m.status = "Move taken back"
+		return nil, true
+	}
+	// keep editing the move
+	m.pendingMove = nil
+	return nil, false
+}
+
+// coachPrompt is shown while a move waits for confirmation.
+func (m model) coachPrompt() string {
+	san := m.locale.translateSAN(chess.AlgebraicNotation{}.Encode(m.game.Position(), m.pendingMove))
+	return fmt.Sprintf("Are you sure? After %s %s (enter/y to play, n to take back)", san, m.coachWarning)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// compatMode is how much of the terminal's capabilities gochess relies on.
+type compatMode int
+
+const (
+	compatAuto compatMode = iota // what the terminal reports it supports
+	compat16                     // the 16 basic ANSI colors, no alternate screen
+	compatMono                   // no colors and no alternate screen
+)
+
+func parseCompatMode(s string) (compatMode, error) {
+	switch s {
+	case "auto":
+		return compatAuto, nil
+	case "16":
+		return compat16, nil
+	case "mono":
+		return compatMono, nil
+	default:
+		return compatAuto, fmt.Errorf("unknown compatibility mode %q (want auto, 16 or mono)", s)
+	}
+}
+
+// apply sets the color profile lipgloss renders with, and reports whether
+// the colors are gone, in which case the board tells the sides apart by
+// the case of the piece letters. lipgloss already detects the profile and
+// brings truecolor down to what the terminal has, so auto leaves it alone.
+func (c compatMode) apply() (noColor bool) {
+	switch c {
+	case compat16:
+		lipgloss.SetColorProfile(termenv.ANSI)
+	case compatMono:
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+	return lipgloss.ColorProfile() == termenv.Ascii
+}
+
+// altScreen reports whether to draw in the alternate screen. Terminals
+// that announce themselves as dumb, or don't at all, are drawn to inline.
+func (c compatMode) altScreen() bool {
+	if c != compatAuto {
+		return false
+	}
+	term := os.Getenv("TERM")
+	return term != "" && term != "dumb"
+}
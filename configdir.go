@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// configDir returns the directory gochess keeps its files in, creating it
+// if needed: gochess under $XDG_CONFIG_HOME (~/.config without it) on
+// Linux and other Unixes, ~/Library/Application Support on macOS and
+// %AppData% on Windows.
+func configDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "gochess")
+	return dir, os.MkdirAll(dir, 0o755)
+}
+
+// configPath returns the path of the named file in the config directory,
+// creating the directory if needed.
+func configPath(name string) (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestConfigDir(t *testing.T) {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		t.Skip("the config directory isn't taken from XDG_CONFIG_HOME")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	t.Run("XDG_CONFIG_HOME", func(t *testing.T) {
+		xdg := filepath.Join(t.TempDir(), "config")
+		t.Setenv("XDG_CONFIG_HOME", xdg)
+		dir, err := configDir()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := filepath.Join(xdg, "gochess"); dir != want {
+			t.Errorf("configDir() = %q, want %q", dir, want)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			t.Errorf("configDir() didn't create %s: %v", dir, err)
+		}
+	})
+
+	t.Run("HOME", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", "")
+		dir, err := configDir()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := filepath.Join(home, ".config", "gochess"); dir != want {
+			t.Errorf("configDir() = %q, want %q", dir, want)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			t.Errorf("configDir() didn't create %s: %v", dir, err)
+		}
+	})
+}
+
+func TestConfigPath(t *testing.T) {
+	dir := tempConfigDir(t)
+	path, err := configPath(recentFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, recentFile); path != want {
+		t.Errorf("configPath(%q) = %q, want %q", recentFile, path, want)
+	}
+	// only the directory is created, the file can be written into it
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConfigDirUnusable(t *testing.T) {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		t.Skip("the config directory isn't taken from XDG_CONFIG_HOME")
+	}
+	// a file where the directory should be
+	file := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", file)
+	if _, err := configPath(recentFile); err == nil {
+		t.Error("configPath() succeeds without a directory to keep the file in")
+	}
+}
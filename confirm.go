@@ -0,0 +1,54 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+var (
+	stagedFromStyle = lipgloss.NewStyle().Background(lipgloss.Color("#C9A33E"))
+	stagedToStyle   = lipgloss.NewStyle().Background(lipgloss.Color("#E3C565"))
+)
+
+// submitMove plays a move the user entered, or with -confirm stages it so
+// that it is previewed on the board until enter is pressed again.
+func (m *model) submitMove(mv *chess.Move) tea.Cmd {
+	if m.confirmMoves {
+		m.stagedMove = mv
+		return nil
+	}
+	return m.playMove(mv)
+}
+
+// answerStaged handles the key pressed while a move is staged: enter plays
+// it and esc takes it back. Any other key takes it back too and goes on to
+// edit the input; it reports whether the key was used up.
+func (m *model) answerStaged(msg tea.KeyMsg) (tea.Cmd, bool) {
+	mv := m.stagedMove
+	m.stagedMove = nil
+	switch msg.String() {
+	case "enter":
+		return m.playMove(mv), true
+	case "esc":
+		return nil, true
+	}
+	return nil, false
+}
+
+// stagedHighlights marks the squares of the staged move.
+func (m model) stagedHighlights() map[chess.Square]lipgloss.Style {
+	if m.stagedMove == nil {
+		return nil
+	}
+	return map[chess.Square]lipgloss.Style{
+		m.stagedMove.S1(): stagedFromStyle,
+		m.stagedMove.S2(): stagedToStyle,
+	}
+}
+
+// stagedPrompt is shown while a move waits to be confirmed.
+func (m model) stagedPrompt() string {
+	san := m.locale.translateSAN(chess.AlgebraicNotation{}.Encode(m.game.Position(), m.stagedMove))
+	return "Press enter to play " + san + " or esc to cancel"
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultPieceContrast is the contrast ratio below which a piece is
+// recolored to stand out from its square, unless the theme sets another.
+// It only catches pieces that all but vanish, such as black pieces on very
+// dark squares; the default colors are well above it.
+const defaultPieceContrast = 1.5
+
+// luminance returns the relative luminance of a #RRGGBB color as defined
+// by WCAG, from 0 for black to 1 for white. ANSI color numbers depend on
+// the terminal, so they have none.
+func luminance(c lipgloss.Color) (float64, bool) {
+	s := string(c)
+	if len(s) != 7 || s[0] != '#' {
+		return 0, false
+	}
+	rgb, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	channel := func(shift uint) float64 {
+		v := float64(rgb>>shift&0xFF) / 255
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(16) + 0.7152*channel(8) + 0.0722*channel(0), true
+}
+
+// contrastRatio is the WCAG contrast ratio of two luminances, from 1 for
+// the same color to 21 for black on white.
+func contrastRatio(a, b float64) float64 {
+	return (max(a, b) + 0.05) / (min(a, b) + 0.05)
+}
+
+// mixColor blends a #RRGGBB color toward another by t, from 0 to 1.
+func mixColor(from, to lipgloss.Color, t float64) lipgloss.Color {
+	a, _ := strconv.ParseUint(string(from)[1:], 16, 32)
+	b, _ := strconv.ParseUint(string(to)[1:], 16, 32)
+	var rgb [3]uint64
+	for i, shift := range []uint{16, 8, 0} {
+		x, y := float64(a>>shift&0xFF), float64(b>>shift&0xFF)
+		rgb[i] = uint64(math.Round(x + (y-x)*t))
+	}
+	return lipgloss.Color(fmt.Sprintf("#%02X%02X%02X", rgb[0], rgb[1], rgb[2]))
+}
+
+// readableOn returns the piece color fg, moved just far enough toward white
+// on dark squares or black on light ones to reach the minimum contrast
+// with the square color bg. A piece keeps most of its own color this way,
+// so the sides stay apart. Colors that aren't #RRGGBB are left alone.
+func readableOn(fg, bg lipgloss.Color, minimum float64) lipgloss.Color {
+	fgLum, ok1 := luminance(fg)
+	bgLum, ok2 := luminance(bg)
+	if !ok1 || !ok2 || minimum <= 1 || contrastRatio(fgLum, bgLum) >= minimum {
+		return fg
+	}
+	target := lipgloss.Color("#FFFFFF")
+	if contrastRatio(bgLum, 0) > contrastRatio(bgLum, 1) {
+		target = "#000000"
+	}
+	for step := 1; step <= 10; step++ {
+		mixed := mixColor(fg, target, float64(step)/10)
+		if lum, _ := luminance(mixed); contrastRatio(lum, bgLum) >= minimum {
+			return mixed
+		}
+	}
+	return target
+}
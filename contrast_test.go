@@ -0,0 +1,146 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+func TestLuminance(t *testing.T) {
+	tests := []struct {
+		color lipgloss.Color
+		want  float64
+		ok    bool
+	}{
+		{"#000000", 0, true},
+		{"#FFFFFF", 1, true},
+		{"#ffffff", 1, true},
+		{"#FF0000", 0.2126, true},
+		{"#00FF00", 0.7152, true},
+		{"12", 0, false},
+		{"#FFF", 0, false},
+		{"#GGGGGG", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := luminance(tt.color)
+		if ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("luminance(%s) = %v, %t, want %v, %t", tt.color, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestContrastRatio(t *testing.T) {
+	if got := contrastRatio(1, 0); got != 21 {
+		t.Errorf("contrast of white and black = %v, want 21", got)
+	}
+	if got := contrastRatio(0, 1); got != 21 {
+		t.Errorf("contrast of black and white = %v, want 21", got)
+	}
+	if got := contrastRatio(0.4, 0.4); got != 1 {
+		t.Errorf("contrast of a color with itself = %v, want 1", got)
+	}
+}
+
+func TestMixColor(t *testing.T) {
+	tests := []struct {
+		from, to lipgloss.Color
+		t        float64
+		want     lipgloss.Color
+	}{
+		{"#000000", "#FFFFFF", 0, "#000000"},
+		{"#000000", "#FFFFFF", 0.5, "#808080"},
+		{"#000000", "#FFFFFF", 1, "#FFFFFF"},
+		{"#204060", "#000000", 0.5, "#102030"},
+	}
+	for _, tt := range tests {
+		if got := mixColor(tt.from, tt.to, tt.t); got != tt.want {
+			t.Errorf("mixColor(%s, %s, %v) = %s, want %s", tt.from, tt.to, tt.t, got, tt.want)
+		}
+	}
+}
+
+// contrastOf is the contrast ratio of two #RRGGBB colors.
+func contrastOf(t *testing.T, a, b lipgloss.Color) float64 {
+	t.Helper()
+	la, ok1 := luminance(a)
+	lb, ok2 := luminance(b)
+	if !ok1 || !ok2 {
+		t.Fatalf("no luminance for %s or %s", a, b)
+	}
+	return contrastRatio(la, lb)
+}
+
+func TestReadableOn(t *testing.T) {
+	tests := []struct {
+		name    string
+		fg, bg  lipgloss.Color
+		lighter bool // whether the piece is lightened rather than darkened
+	}{
+		{"black piece on a dark square", "#101010", "#202020", true},
+		{"dark blue piece on black", "#000030", "#000000", true},
+		{"white piece on a light square", "#F0F0F0", "#FFFFFF", false},
+		{"yellow piece on a cream square", "#FFFF80", "#FFFFE0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readableOn(tt.fg, tt.bg, defaultPieceContrast)
+			if c := contrastOf(t, got, tt.bg); c < defaultPieceContrast {
+				t.Errorf("readableOn(%s, %s) = %s, contrast %.2f, want at least %v", tt.fg, tt.bg, got, c, defaultPieceContrast)
+			}
+			before, _ := luminance(tt.fg)
+			after, _ := luminance(got)
+			if (after > before) != tt.lighter {
+				t.Errorf("readableOn(%s, %s) = %s, want it %s", tt.fg, tt.bg, got, map[bool]string{true: "lighter", false: "darker"}[tt.lighter])
+			}
+		})
+	}
+}
+
+func TestReadableOnLeavesColors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fg, bg  lipgloss.Color
+		minimum float64
+	}{
+		{"already readable", "#FFFFFF", "#000000", defaultPieceContrast},
+		{"check turned off", "#101010", "#202020", 1},
+		{"ANSI piece", "0", "#000000", defaultPieceContrast},
+		{"ANSI square", "#000000", "0", defaultPieceContrast},
+	}
+	for _, tt := range tests {
+		if got := readableOn(tt.fg, tt.bg, tt.minimum); got != tt.fg {
+			t.Errorf("%s: readableOn(%s, %s, %v) = %s, want it left", tt.name, tt.fg, tt.bg, tt.minimum, got)
+		}
+	}
+}
+
+func TestPieceOn(t *testing.T) {
+	dark := lipgloss.Color("#000000")
+	th := theme{black: "#111111", dark: dark}
+	fg := th.pieceOn(chess.Black, dark).GetForeground().(lipgloss.Color)
+	if fg == th.black {
+		t.Errorf("black pieces on black squares keep their color %s", fg)
+	}
+	if c := contrastOf(t, fg, dark); c < defaultPieceContrast {
+		t.Errorf("black pieces recolored to %s, contrast %.2f, want at least %v", fg, c, defaultPieceContrast)
+	}
+
+	// a theme's own minimum, here turning the check off
+	th.contrast = 1
+	if fg := th.pieceOn(chess.Black, dark).GetForeground(); fg != th.black {
+		t.Errorf("with the check off black pieces are recolored to %v", fg)
+	}
+
+	// the default colors are well above the minimum
+	var def theme
+	for _, color := range []chess.Color{chess.White, chess.Black} {
+		for _, square := range []bool{false, true} {
+			bg := def.squareStyle(square).GetBackground()
+			if got, want := def.pieceOn(color, bg).GetForeground(), def.pieceStyle(color).GetForeground(); got != want {
+				t.Errorf("default %s pieces recolored to %v", color.Name(), got)
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+const (
+	// demoMoveDelay is the pause between moves in demo mode. With an engine
+	// the engine's thinking time sets the pace instead.
+	demoMoveDelay = time.Second
+	// demoRestartDelay is how long a finished demo game stays on screen.
+	demoRestartDelay = 5 * time.Second
+)
+
+// demoTickMsg makes the next move of a demo game.
+type demoTickMsg struct{}
+
+func demoTick(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return demoTickMsg{}
+	})
+}
+
+// handleDemoTick plays on in demo mode, starting a new game once the
+// current one is over. The engine plays both sides by itself; without one
+// the tick lets randomSource make its next move.
+func (m *model) handleDemoTick() tea.Cmd {
+	if !m.demo {
+		return nil
+	}
+	if m.game.Outcome() != chess.NoOutcome {
+		return tea.Batch(m.newGame(), demoTick(demoMoveDelay))
+	}
+	if m.engine != nil {
+		return demoTick(demoMoveDelay)
+	}
+	m.demoDue = true
+	return nil
+}
+
+// stopDemo hands the board over to the user.
+func (m *model) stopDemo() {
+	m.demo = false
+	m.status = "Demo stopped"
+}
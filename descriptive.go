@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// descriptiveFiles names the files, from the a-file to the h-file, after
+// the pieces that start on them, as English descriptive notation does.
+var descriptiveFiles = [8]string{"QR", "QN", "QB", "Q", "K", "KB", "KN", "KR"}
+
+var descriptiveLetters = map[chess.PieceType]string{
+	chess.King:   "K",
+	chess.Queen:  "Q",
+	chess.Rook:   "R",
+	chess.Bishop: "B",
+	chess.Knight: "N",
+	chess.Pawn:   "P",
+}
+
+// descriptiveInput matches what only descriptive notation writes: a dash
+// before the square, or a piece rather than a square after x.
+var descriptiveInput = regexp.MustCompile(`-|x[KQRBNP]`)
+
+// descriptiveSquare names a square as the side to move sees it: ranks are
+// counted from its own end of the board, so e4 is K4 for White and K5 for
+// Black.
+func descriptiveSquare(sq chess.Square, side chess.Color) string {
+	rank := int(sq.Rank()) + 1
+	if side == chess.Black {
+		rank = 9 - rank
+	}
+	return descriptiveFiles[sq.File()] + strconv.Itoa(rank)
+}
+
+// shortSquare leaves the wing out of a square, KB3 becoming B3, which is
+// how squares are usually written when only one of the two fits the move.
+func shortSquare(name string) string {
+	if len(name) == 3 {
+		return name[1:]
+	}
+	return name
+}
+
+// descriptiveSpellings lists the ways of writing mv in descriptive
+// notation, from the shortest to the most qualified, leaving out check.
+// Moves name their target square, "N-KB3" or "N-B3", and captures the
+// piece taken, "PxP", with its square when needed, "PxP/Q4". Two pieces of
+// a kind are told apart by their wing, "KN-Q2", pawns by their file,
+// "KBPxP", and as a last resort by their square, "N/KB3-Q2". The wing is
+// the one the piece stands on, not the one it started from.
+func descriptiveSpellings(pos *chess.Position, mv *chess.Move) []string {
+	switch {
+	case mv.HasTag(chess.KingSideCastle):
+		return []string{"O-O"}
+	case mv.HasTag(chess.QueenSideCastle):
+		return []string{"O-O-O"}
+	}
+	side := pos.Turn()
+	piece := pos.Board().Piece(mv.S1()).Type()
+	letter := descriptiveLetters[piece]
+
+	pieces := []string{letter}
+	switch piece {
+	case chess.Pawn:
+		pieces = append(pieces, descriptiveFiles[mv.S1().File()]+letter)
+	case chess.Rook, chess.Bishop, chess.Knight:
+		wing := "Q"
+		if mv.S1().File() >= chess.FileE {
+			wing = "K"
+		}
+		pieces = append(pieces, wing+letter)
+	}
+	pieces = append(pieces, letter+"/"+descriptiveSquare(mv.S1(), side))
+
+	var targets []string
+	if mv.HasTag(chess.Capture) || mv.HasTag(chess.EnPassant) {
+		taken := capturedSquare(mv)
+		victim := "x" + descriptiveLetters[pos.Board().Piece(taken).Type()]
+		square := descriptiveSquare(taken, side)
+		targets = []string{victim, victim + "/" + shortSquare(square), victim + "/" + square}
+	} else {
+		square := descriptiveSquare(mv.S2(), side)
+		targets = []string{"-" + shortSquare(square), "-" + square}
+	}
+
+	promotion := ""
+	if mv.Promo() != chess.NoPieceType {
+		promotion = "=" + descriptiveLetters[mv.Promo()]
+	}
+	var spellings []string
+	for _, p := range pieces {
+		for _, t := range targets {
+			spellings = append(spellings, p+t+promotion)
+		}
+	}
+	return spellings
+}
+
+// normalizeDescriptive reduces a spelling to what tells moves apart, so
+// that "p-k8(q) ch", "P-K8=Q" and "0-0" compare as written here.
+func normalizeDescriptive(s string) string {
+	s = strings.ToUpper(strings.Join(strings.Fields(s), ""))
+	s = strings.NewReplacer("0", "O", "=", "", "(", "", ")", "", ".", "", "+", "", "#", "").Replace(s)
+	for _, suffix := range []string{"MATE", "CH", "EP"} {
+		s = strings.TrimSuffix(s, suffix)
+	}
+	return s
+}
+
+// descriptiveIndex maps every spelling of the legal moves of the position
+// to the moves it could mean.
+func descriptiveIndex(pos *chess.Position) map[string][]*chess.Move {
+	index := map[string][]*chess.Move{}
+	for _, mv := range pos.ValidMoves() {
+		for _, s := range descriptiveSpellings(pos, mv) {
+			// K4 is short for itself, so spellings can repeat
+			key := normalizeDescriptive(s)
+			if moves := index[key]; len(moves) == 0 || moves[len(moves)-1] != mv {
+				index[key] = append(moves, mv)
+			}
+		}
+	}
+	return index
+}
+
+// encodeDescriptive writes mv in descriptive notation, in the shortest
+// spelling that no other legal move shares, with "ch" or "mate" after a
+// check.
+func encodeDescriptive(pos *chess.Position, mv *chess.Move) string {
+	index := descriptiveIndex(pos)
+	spellings := descriptiveSpellings(pos, mv)
+	text := spellings[len(spellings)-1]
+	for _, s := range spellings {
+		if len(index[normalizeDescriptive(s)]) == 1 {
+			text = s
+			break
+		}
+	}
+	if mv.HasTag(chess.Check) {
+		if pos.Update(mv).Status() == chess.Checkmate {
+			return text + " mate"
+		}
+		return text + " ch"
+	}
+	return text
+}
+
+// decodeDescriptive reads a move typed in descriptive notation, such as
+// "P-K4" or "NxP", for the side to move.
+func decodeDescriptive(pos *chess.Position, input string) (*chess.Move, error) {
+	moves := descriptiveIndex(pos)[normalizeDescriptive(input)]
+	switch len(moves) {
+	case 0:
+		return nil, fmt.Errorf("%q is not a legal move in descriptive notation", strings.TrimSpace(input))
+	case 1:
+		return moves[0], nil
+	}
+	var spellings []string
+	for _, mv := range moves {
+		spellings = append(spellings, encodeDescriptive(pos, mv))
+	}
+	return nil, fmt.Errorf("%q could be %s", strings.TrimSpace(input), strings.Join(spellings, " or "))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+const startFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+var descriptiveTests = []struct {
+	name  string
+	fen   string
+	moves []string // played from fen to reach the position
+	san   string
+	want  string
+}{
+	{"white pawn", startFEN, nil, "e4", "P-K4"},
+	{"black pawn", startFEN, []string{"e4"}, "e5", "P-K4"},
+	{"white knight", startFEN, nil, "Nf3", "N-KB3"},
+	{"black knight", startFEN, []string{"e4"}, "Nf6", "N-KB3"},
+	{"short square", startFEN, []string{"e4", "e5"}, "Bc4", "B-B4"},
+	{"rooks told apart by wing", "k7/8/8/8/8/8/K7/R6R w - - 0 1", nil, "Rad1", "QR-Q1"},
+	{"pawns taking the same pawn", startFEN, []string{"e4", "d5", "c4", "Nf6"}, "exd5", "KPxP"},
+	{"pawn taking the same pawn", startFEN, []string{"e4", "d5", "c4", "Nf6"}, "cxd5", "QBPxP"},
+	{"pawn taking either pawn", startFEN, []string{"e4", "d5", "Nc3", "f5"}, "exf5", "PxP/B5"},
+	{"black pawns taking the same pawn", startFEN, []string{"d4", "e5", "a3", "c5", "Nf3"}, "exd4", "KPxP"},
+	{"white promotion", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", nil, "e8=Q", "P-K8=Q"},
+	{"black underpromotion", "4k3/8/8/8/8/8/p7/4K3 b - - 0 1", nil, "a1=N", "P-R8=N"},
+	{"promotion taking with check", "3r3k/4P3/8/8/8/8/8/K7 w - - 0 1", nil, "exd8=Q+", "PxR=Q ch"},
+	{"white en passant", startFEN, []string{"e4", "a6", "e5", "d5"}, "exd6", "PxP"},
+	{"black en passant", startFEN, []string{"a3", "e5", "a4", "e4", "d4"}, "exd3", "PxP"},
+	{"white castles kingside", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", nil, "O-O", "O-O"},
+	{"white castles queenside", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", nil, "O-O-O", "O-O-O"},
+	{"black castles kingside", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", nil, "O-O", "O-O"},
+	{"black castles queenside", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", nil, "O-O-O", "O-O-O"},
+	{"mate", startFEN, []string{"f3", "e5", "g4"}, "Qh4#", "Q-R5 mate"},
+}
+
+func TestEncodeDescriptive(t *testing.T) {
+	for _, tt := range descriptiveTests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := newTestGameFrom(t, tt.fen, tt.moves...).Position()
+			mv, err := chess.AlgebraicNotation{}.Decode(pos, tt.san)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := encodeDescriptive(pos, mv); got != tt.want {
+				t.Errorf("encodeDescriptive(%s) = %q, want %q", tt.san, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeDescriptive(t *testing.T) {
+	for _, tt := range descriptiveTests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := newTestGameFrom(t, tt.fen, tt.moves...).Position()
+			mv, err := decodeDescriptive(pos, tt.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := (chess.AlgebraicNotation{}).Encode(pos, mv); got != tt.san {
+				t.Errorf("decodeDescriptive(%q) = %s, want %s", tt.want, got, tt.san)
+			}
+		})
+	}
+}
+
+func TestDecodeDescriptiveSpellings(t *testing.T) {
+	tests := []struct {
+		fen   string
+		input string
+		san   string
+	}{
+		{startFEN, "p-k4", "e4"},
+		{startFEN, "P - K 4", "e4"},
+		{startFEN, "N-KB3", "Nf3"},
+		{startFEN, "KN-KB3", "Nf3"},
+		{startFEN, "N/KN1-KB3", "Nf3"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "0-0", "O-O"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "0-0-0", "O-O-O"},
+		{"8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "P-K8(Q)", "e8=Q"},
+		{"3r3k/4P3/8/8/8/8/8/K7 w - - 0 1", "PxR=Q ch", "exd8=Q+"},
+		{"3r3k/4P3/8/8/8/8/8/K7 w - - 0 1", "PxR/Q8=Q", "exd8=Q+"},
+	}
+	for _, tt := range tests {
+		pos := newTestGameFrom(t, tt.fen).Position()
+		mv, err := decodeDescriptive(pos, tt.input)
+		if err != nil {
+			t.Errorf("decodeDescriptive(%q): %v", tt.input, err)
+			continue
+		}
+		if got := (chess.AlgebraicNotation{}).Encode(pos, mv); got != tt.san {
+			t.Errorf("decodeDescriptive(%q) = %s, want %s", tt.input, got, tt.san)
+		}
+	}
+}
+
+func TestDecodeDescriptiveErrors(t *testing.T) {
+	pos := chess.NewGame().Position()
+	if _, err := decodeDescriptive(pos, "N-B3"); err == nil || !strings.Contains(err.Error(), "N-QB3 or N-KB3") {
+		t.Errorf("decodeDescriptive(N-B3) error %v, want both knight moves named", err)
+	}
+	if _, err := decodeDescriptive(pos, "P-K5"); err == nil || !strings.Contains(err.Error(), "not a legal move") {
+		t.Errorf("decodeDescriptive(P-K5) error %v, want it refused", err)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/notnil/chess"
+)
+
+// boardDiagram draws the position in plain text, White's pieces in capitals
+// and Black's in small letters, as in a FEN, with dots for empty squares:
+//
+//	8 r n b q k b n r
+//	7 p p p p p p p p
+//	…
+//	  a b c d e f g h
+//
+// It is seen from Black's side when flipped.
+func boardDiagram(pos *chess.Position, flipped bool) string {
+	board := pos.Board()
+	var sb strings.Builder
+	for r := 7; r >= 0; r-- {
+		rank := chess.Rank(r)
+		if flipped {
+			rank = chess.Rank(7 - r)
+		}
+		sb.WriteString(rank.String())
+		for f := 0; f < 8; f++ {
+			file := chess.File(f)
+			if flipped {
+				file = chess.File(7 - f)
+			}
+			letter := "."
+			if p := board.Piece(chess.NewSquare(file, rank)); p != chess.NoPiece {
+				letter = p.Type().String()
+				if p.Color() == chess.White {
+					letter = strings.ToUpper(letter)
+				}
+			}
+			sb.WriteString(" " + letter)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(" ")
+	for f := 0; f < 8; f++ {
+		file := chess.File(f)
+		if flipped {
+			file = chess.File(7 - f)
+		}
+		sb.WriteString(" " + file.String())
+	}
+	return sb.String()
+}
+
+// markdownDiagram puts the diagram of the position in a fenced code block
+// followed by its FEN, ready to paste into an issue or a chat.
+func markdownDiagram(pos *chess.Position, flipped bool) string {
+	return "```\n" + boardDiagram(pos, flipped) + "\n```\n\nFEN: `" + pos.String() + "`\n"
+}
+
+// copyDiagram puts the markdown diagram of the position shown on the
+// clipboard, or shows it in an overlay when there is no clipboard to use.
+func (m *model) copyDiagram() {
+	diagram := markdownDiagram(m.displayedPosition(), m.flipped)
+	if err := clipboard.WriteAll(diagram); err != nil {
+		m.overlay = newTextOverlay("Diagram", strings.TrimSpace(diagram))
+		m.status = "No clipboard available, copy the diagram from here"
+		return
+	}
+	m.status = "Diagram copied to the clipboard"
+}
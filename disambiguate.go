@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// pieceToSquare matches a piece move that names its target square only,
+// such as Nd2 or Rxe1.
+var pieceToSquare = regexp.MustCompile(`^([NBRQK])x?([a-h][1-8])$`)
+
+// ambiguousMoves returns the legal moves a typed piece move may be when
+// more than one piece of its type can go to its square, as for Nd2 with
+// knights on b1 and f3. It returns nil for any other input.
+func (l pieceLocale) ambiguousMoves(pos *chess.Position, moves []*chess.Move, input string) []*chess.Move {
+	san := l.parseSAN(normalizeSAN(input))
+	if r := []rune(san); len(r) > 1 && !pieceToSquare.MatchString(san) {
+		san = l.parseSAN(strings.ToUpper(string(r[0])) + string(r[1:]))
+	}
+	parts := pieceToSquare.FindStringSubmatch(san)
+	if parts == nil {
+		return nil
+	}
+	var found []*chess.Move
+	for _, mv := range moves {
+		piece := pos.Board().Piece(mv.S1())
+		if piece.Type().String() == strings.ToLower(parts[1]) && mv.S2().String() == parts[2] {
+			found = append(found, mv)
+		}
+	}
+	if len(found) < 2 {
+		return nil
+	}
+	return found
+}
+
+// ambiguityPrompt asks which of the pieces to move, e.g. "Which knight?
+// b1 or f3".
+func (m model) ambiguityPrompt() string {
+	squares := make([]string, len(m.ambiguous))
+	for i, mv := range m.ambiguous {
+		squares[i] = mv.S1().String()
+	}
+	piece := m.game.Position().Board().Piece(m.ambiguous[0].S1())
+	which := strings.Join(squares[:len(squares)-1], ", ") + " or " + squares[len(squares)-1]
+	return fmt.Sprintf("Which %s? %s (type its square, file or rank, esc to cancel)", strings.ToLower(pieceName(piece.Type())), which)
+}
+
+// answerAmbiguity picks the move of the piece the input names by its
+// square, or by its file or rank when that tells the pieces apart. It
+// reports whether a choice was pending; without a match it stays pending.
+func (m *model) answerAmbiguity(input string) (tea.Cmd, bool) {
+	if m.ambiguous == nil {
+		return nil, false
+	}
+	input = strings.ToLower(strings.TrimSpace(input))
+	var picked []*chess.Move
+	for _, mv := range m.ambiguous {
+		from := mv.S1().String()
+		if input == from || len(input) == 1 && strings.Contains(from, input) {
+			picked = append(picked, mv)
+		}
+	}
+	m.textInput.Reset()
+	if len(picked) != 1 {
+		m.error = fmt.Errorf("%q doesn't pick one of them", input)
+		return nil, true
+	}
+	m.ambiguous = nil
+	m.error = nil
+	return m.submitMove(picked[0]), true
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+const (
+	// The engine offers a draw once the evaluation of the last
+	// engineDrawPlies positions has stayed within engineDrawMargin of
+	// equality, but not before engineDrawMinPly. It accepts an offer on the
+	// same terms.
+	engineDrawMargin = 25 // centipawns
+	engineDrawPlies  = 6
+	engineDrawMinPly = 40
+)
+
+// errEngineToMove is the error for resigning or claiming a draw while the
+// engine thinks about its move.
+var errEngineToMove = errors.New("wait for the engine to move")
+
+// engineToMove reports whether it is the engine's move in the game being
+// played.
+func (m model) engineToMove() bool {
+	return m.engine != nil && m.engineColor != chess.NoColor && m.game.Position().Turn() == m.engineColor
+}
+
+// resign ends the game with side resigning. A search still running for the
+// game, like the engine's move, a hint or pondering, is stopped: its result
+// is of no use any more.
+func (m *model) resign(side chess.Color) {
+	m.game.Resign(side)
+	m.stopPondering()
+	if m.engine != nil && (m.engineThinking || m.hintPending) {
+		m.engine.stop()
+	}
+}
+
+// offerDraw makes a draw offer on behalf of the given side. The engine
+// answers offers made to it right away.
+func (m *model) offerDraw(side chess.Color) error {
+	if m.game.Outcome() != chess.NoOutcome {
+		return errors.New("the game is already over")
+	}
+	if m.drawOffer != chess.NoColor {
+		return errors.New("a draw offer is already pending")
+	}
+	if m.engineToMove() {
+		return errEngineToMove
+	}
+	m.drawOffer = side
+	if m.engine != nil && side.Other() == m.engineColor {
+		if m.engineWantsDraw() {
+			return m.acceptDraw()
+		}
+		m.drawOffer = chess.NoColor
+		m.status = "The engine declines the draw"
+	}
+	return nil
+}
+
+// acceptDraw ends the game on a pending draw offer.
+func (m *model) acceptDraw() error {
+	if m.drawOffer == chess.NoColor {
+		return errors.New("no draw has been offered")
+	}
+	m.drawOffer = chess.NoColor
+	return m.game.Draw(chess.DrawOffer)
+}
+
+// declineDraw withdraws a pending draw offer and play continues.
+func (m *model) declineDraw() error {
+	if m.drawOffer == chess.NoColor {
+		return errors.New("no draw has been offered")
+	}
+	m.drawOffer = chess.NoColor
+	m.status = "Draw declined"
+	return nil
+}
+
+// answerDrawOffer handles a y/n answer typed at the move prompt while an
+// offer is pending. It reports whether the input was an answer.
+func (m *model) answerDrawOffer(input string) (bool, error) {
+	if m.drawOffer == chess.NoColor {
+		return false, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes":
+		return true, m.acceptDraw()
+	case "n", "no":
+		return true, m.declineDraw()
+	}
+	return false, nil
+}
+
+// inlineCommand handles the special inputs the move prompt takes besides
+// moves: (=) claims a draw when the position allows it and offers one
+// otherwise, and resign asks to resign. It reports whether the input was
+// one of them.
+func (m *model) inlineCommand(input string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "(=)":
+		if m.mode != modePlay {
+			return true, errors.New("draws can only be offered in play mode")
+		}
+		if m.engineToMove() {
+			return true, errEngineToMove
+		}
+		for _, method := range m.game.EligibleDraws() {
+			if method != chess.DrawOffer {
+				return true, m.game.Draw(method)
+			}
+		}
+		return true, m.offerDraw(m.game.Position().Turn())
+	case "resign":
+		if m.mode != modePlay {
+			return true, errors.New("you can only resign in play mode")
+		}
+		if m.game.Outcome() != chess.NoOutcome {
+			return true, errors.New("the game is already over")
+		}
+		if m.engineToMove() {
+			return true, errEngineToMove
+		}
+		m.confirmResign = true
+		return true, nil
+	}
+	return false, nil
+}
+
+// answerResign handles the answer to the resign confirmation: y resigns
+// for the side to move and anything else carries on playing.
+func (m *model) answerResign(input string) (bool, error) {
+	if !m.confirmResign {
+		return false, nil
+	}
+	m.confirmResign = false
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes":
+		if m.game.Outcome() != chess.NoOutcome {
+			return true, errors.New("the game is already over")
+		}
+		m.resign(m.game.Position().Turn())
+	default:
+		m.status = "Resignation withdrawn"
+	}
+	return true, nil
+}
+
+// engineWantsDraw reports whether the evaluations of the recent positions
+// have been level for long enough for the engine to agree to a draw. The
+// current position is left out as it may not have been evaluated yet.
+func (m model) engineWantsDraw() bool {
+	positions := m.game.Positions()
+	if len(positions) <= max(engineDrawMinPly, engineDrawPlies) {
+		return false
+	}
+	for _, pos := range positions[len(positions)-1-engineDrawPlies : len(positions)-1] {
+		score, ok := m.evals[pos.String()]
+		if !ok || score.mate != 0 || score.cp > engineDrawMargin || score.cp < -engineDrawMargin {
+			return false
+		}
+	}
+	return true
+}
+
+// engineWantsResign reports whether the engine should resign rather than
+// play on, with -engine-resign: its evaluation of the position it is to
+// move in, score, and of its last -engine-resign-moves positions before
+// has been below the threshold from its side. Being mated counts as below
+// it.
+func (m model) engineWantsResign(score engineScore) bool {
+	if m.resignBelow <= 0 || m.engineColor == chess.NoColor {
+		return false
+	}
+	losing := func(s engineScore) bool {
+		cp, mate := s.cp, s.mate
+		if m.engineColor == chess.Black {
+			cp, mate = -cp, -mate
+		}
+		if mate != 0 {
+			return mate < 0
+		}
+		return cp <= -m.resignBelow
+	}
+	if !losing(score) {
+		return false
+	}
+	positions := m.game.Positions()
+	for i := 1; i < m.resignMoves; i++ {
+		ply := len(positions) - 1 - 2*i
+		if ply < 0 {
+			return false
+		}
+		s, ok := m.evals[positions[ply].String()]
+		if !ok || !losing(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// drawOfferPrompt is shown while an offer waits for an answer.
+func (m model) drawOfferPrompt() string {
+	return m.drawOffer.Name() + " offers a draw: type y to accept or n to decline"
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+func TestEngineWantsResign(t *testing.T) {
+	tests := []struct {
+		name   string
+		side   chess.Color
+		below  int
+		scores []engineScore // from White's side, of the engine's positions, the last the one it is to move in
+		want   bool
+	}{
+		{"off", chess.Black, 0, []engineScore{{cp: 5000}}, false},
+		{"above the threshold", chess.Black, 500, []engineScore{{cp: 400}}, false},
+		{"at the threshold", chess.Black, 500, []engineScore{{cp: 500}}, true},
+		{"as White", chess.White, 500, []engineScore{{cp: -700}}, true},
+		{"winning", chess.White, 500, []engineScore{{cp: 700}}, false},
+		{"threshold past the delta cap", chess.Black, 1500, []engineScore{{cp: 1600}}, true},
+		{"short of a threshold past the cap", chess.Black, 1500, []engineScore{{cp: 1200}}, false},
+		{"being mated", chess.Black, 1500, []engineScore{{mate: 3}}, true},
+		{"mating", chess.Black, 1500, []engineScore{{mate: -3}}, false},
+		{"losing for long enough", chess.Black, 500, []engineScore{{cp: 600}, {cp: 900}, {cp: 800}}, true},
+		{"not losing for long enough", chess.Black, 500, []engineScore{{cp: 100}, {cp: 900}, {cp: 800}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := initialModel(config{})
+			m.engineColor = tt.side
+			m.resignBelow = tt.below
+			m.resignMoves = len(tt.scores)
+			// knights going back and forth leave the engine to move with
+			// two positions of its own before
+			moves := []string{"Nf3", "Nf6", "Ng1", "Ng8"}
+			if tt.side == chess.Black {
+				moves = []string{"e4", "Nf6", "Nf3", "Ng8", "Ng1"}
+			}
+			for _, move := range moves {
+				if err := m.game.MoveStr(move); err != nil {
+					t.Fatal(err)
+				}
+			}
+			positions := m.game.Positions()
+			last := len(tt.scores) - 1
+			for i, s := range tt.scores[:last] {
+				m.evals[positions[len(positions)-1-2*(last-i)].String()] = s
+			}
+			if got := m.engineWantsResign(tt.scores[last]); got != tt.want {
+				t.Errorf("engineWantsResign = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// repertoire is a set of opening lines to drill. The user plays one side
+// and the other side's moves are played from the book.
+type repertoire struct {
+	lines     [][]string // moves in algebraic notation from the initial position
+	completed []bool
+	side      chess.Color
+}
+
+// loadRepertoire reads opening lines from a PGN file, one line per game, or
+// from a plain text file with one line of moves per line of text, e.g.
+// "1. e4 e5 2. Nf3 Nc6". Blank lines and lines starting with # are skipped.
+func loadRepertoire(path string, side chess.Color) (*repertoire, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &repertoire{side: side}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		games, err := chess.GamesFromPGN(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		for _, game := range games {
+			r.lines = append(r.lines, sanHistory(game))
+		}
+	} else {
+		for i, text := range strings.Split(string(data), "\n") {
+			text = strings.TrimSpace(text)
+			if text == "" || strings.HasPrefix(text, "#") {
+				continue
+			}
+			game := chess.NewGame()
+			for _, field := range movetextTokens(text) {
+				if err := game.MoveStr(field); err != nil {
+					return nil, fmt.Errorf("%s:%d: %w", path, i+1, err)
+				}
+			}
+			r.lines = append(r.lines, sanHistory(game))
+		}
+	}
+	r.lines = slices.DeleteFunc(r.lines, func(line []string) bool { return len(line) == 0 })
+	if len(r.lines) == 0 {
+		return nil, errors.New(path + " has no opening lines")
+	}
+	r.completed = make([]bool, len(r.lines))
+	return r, nil
+}
+
+// bookMoves returns the moves the repertoire continues with after the given
+// moves, those of lines not completed yet first.
+func (r *repertoire) bookMoves(history []string) []string {
+	var todo, done []string
+	for i, line := range r.lines {
+		if len(line) <= len(history) || !slices.Equal(line[:len(history)], history) {
+			continue
+		}
+		next := line[len(history)]
+		if slices.Contains(todo, next) || slices.Contains(done, next) {
+			continue
+		}
+		if r.completed[i] {
+			done = append(done, next)
+		} else {
+			todo = append(todo, next)
+		}
+	}
+	return append(todo, done...)
+}
+
+// markCompleted records the lines the given moves play out to the end and
+// reports whether any of them wasn't completed before.
+func (r *repertoire) markCompleted(history []string) bool {
+	newly := false
+	for i, line := range r.lines {
+		if !r.completed[i] && slices.Equal(line, history) {
+			r.completed[i] = true
+			newly = true
+		}
+	}
+	return newly
+}
+
+// progress returns how many lines have been completed out of how many.
+func (r *repertoire) progress() (done, total int) {
+	for _, c := range r.completed {
+		if c {
+			done++
+		}
+	}
+	return done, len(r.lines)
+}
+
+// checkRepertoire rejects a move that leaves the repertoire while drilling.
+// Once the book runs out any move may be played.
+func (m *model) checkRepertoire(mv *chess.Move) error {
+	if m.drill == nil || m.mode != modePlay || m.historyDrop > 0 {
+		return nil
+	}
+	book := m.drill.bookMoves(m.history)
+	if len(book) == 0 {
+		return nil
+	}
+	san := chess.AlgebraicNotation{}.Encode(m.game.Position(), mv)
+	if slices.Contains(book, san) {
+		return nil
+	}
+	for i := range book {
+		book[i] = m.locale.translateSAN(book[i])
+	}
+	return fmt.Errorf("%s is not in your repertoire, expected %s", m.locale.translateSAN(san), strings.Join(book, " or "))
+}
+
+// drillProgress notes when a line has been played out, by the user or by
+// the book.
+func (m *model) drillProgress() {
+	if m.drill == nil || m.mode != modePlay || m.historyDrop > 0 {
+		return
+	}
+	if m.drill.markCompleted(m.history) {
+		done, total := m.drill.progress()
+		m.status = fmt.Sprintf("Line complete! %d of %d lines drilled, :new for the next one", done, total)
+	}
+}
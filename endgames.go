@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// endgamePreset is a standard endgame to practise against the engine. The
+// user plays the side to move.
+type endgamePreset struct {
+	name string
+	fen  string
+	draw bool // the side to move is to hold the draw rather than win
+}
+
+var endgamePresets = []endgamePreset{
+	{name: "K+Q vs K", fen: "4k3/8/8/8/8/8/8/3QK3 w - - 0 1"},
+	{name: "K+R vs K", fen: "4k3/8/8/8/8/8/8/R3K3 w - - 0 1"},
+	{name: "Lucena", fen: "1K1k4/1P6/8/8/8/8/r7/2R5 w - - 0 1"},
+	{name: "Philidor", fen: "4k3/7R/r7/3KP3/8/8/8/8 b - - 0 1", draw: true},
+}
+
+func (p endgamePreset) goal() string {
+	if p.draw {
+		return "hold the draw"
+	}
+	return "win"
+}
+
+// endgameList numbers the presets for :endgame, ticking those converted.
+func (m model) endgameList() string {
+	var sb strings.Builder
+	for i, p := range endgamePresets {
+		done := " "
+		if m.endgamesConverted[i] {
+			done = "✓"
+		}
+		fmt.Fprintf(&sb, "%s %d. %-10s %s\n", done, i+1, p.name, p.goal())
+	}
+	sb.WriteString("\n:endgame <n> to practise one")
+	return sb.String()
+}
+
+// startEndgame sets up the preset's position with the user playing the side
+// to move against the engine.
+func (m *model) startEndgame(i int) (tea.Cmd, error) {
+	if i < 0 || i >= len(endgamePresets) {
+		return nil, fmt.Errorf("no endgame %d, there are %d", i+1, len(endgamePresets))
+	}
+	if m.engine == nil {
+		return nil, errors.New("endgame practice needs an engine, start gochess with -engine")
+	}
+	preset := endgamePresets[i]
+	fen, err := chess.FEN(preset.fen)
+	if err != nil {
+		return nil, err
+	}
+	game := chess.NewGame(fen)
+	m.engineColor = game.Position().Turn().Other()
+	m.flipped = m.engineColor == chess.White
+	m.stashedGame, m.stashedHistory = nil, nil
+	cmd := m.startGame(game)
+	m.endgame = i
+	m.status = fmt.Sprintf("%s: %s against the engine", preset.name, preset.goal())
+	return cmd, nil
+}
+
+// endgameEnded checks the outcome of an endgame being practised once the
+// game ends: converting it ticks it off, failing sets it up again. It
+// reports whether the endgame was failed and restarted.
+func (m *model) endgameEnded() (tea.Cmd, bool) {
+	if m.endgame < 0 {
+		return nil, false
+	}
+	preset := endgamePresets[m.endgame]
+	side := m.engineColor.Other()
+	outcome := m.game.Outcome()
+	won := outcome == chess.WhiteWon && side == chess.White || outcome == chess.BlackWon && side == chess.Black
+	if won || preset.draw && outcome == chess.Draw {
+		m.endgamesConverted[m.endgame] = true
+		m.status = preset.name + " converted"
+		return nil, false
+	}
+	cmd, err := m.startEndgame(m.endgame)
+	if err != nil {
+		m.error = err
+		return nil, false
+	}
+	m.status = preset.name + " not converted, try again"
+	return cmd, true
+}
@@ -0,0 +1,17 @@
+package main
+
+import "testing"
+
+func TestNewEndgameWithoutEngine(t *testing.T) {
+	u := newUIModel(t)
+	u.enter("e4")
+	// the endgame was started while the engine was still running
+	u.m.endgame = 0
+	u.m.newGame()
+	if u.m.error == nil {
+		t.Error("setting up the endgame again without an engine shows no error")
+	}
+	if got := moveList(u.m.game); got != "e4" {
+		t.Errorf("game has the moves %q, want e4 kept", got)
+	}
+}
@@ -0,0 +1,607 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+const (
+	// evalMovetime is how long the engine thinks about each position it evaluates.
+	evalMovetime = 200 * time.Millisecond
+	// playMovetime is how long the engine thinks before playing a move.
+	playMovetime = time.Second
+)
+
+// engineScore is an evaluation in centipawns from White's point of view.
+// When mate is non-zero it is the number of moves until mate, negative when
+// Black is mating, and cp is meaningless.
+type engineScore struct {
+	cp   int
+	mate int
+}
+
+func (s engineScore) String() string {
+	switch {
+	case s.mate > 0:
+		return fmt.Sprintf("#%d", s.mate)
+	case s.mate < 0:
+		return fmt.Sprintf("#-%d", -s.mate)
+	default:
+		return fmt.Sprintf("%+.2f", float64(s.cp)/100)
+	}
+}
+
+// uciEngine talks to a chess engine over the UCI protocol. Searches are
+// serialized, so it is safe to use from several commands at once.
+type uciEngine struct {
+	path  string
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan string // engine output, closed when the engine exits
+
+	// options are the names of the options the engine announced
+	options map[string]bool
+	// limit replaces the default thinking time of searches when set
+	limit searchLimit
+	// settings are the options set so far, in order, to set them again
+	// when the engine is restarted
+	settings [][2]string
+
+	mu sync.Mutex
+}
+
+// startEngine launches the engine at path and waits for it to be ready.
+func startEngine(path string) (*uciEngine, error) {
+	cmd := exec.Command(path)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	e := &uciEngine{path: path, cmd: cmd, stdin: stdin, lines: make(chan string, 64), options: map[string]bool{}}
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			e.lines <- scanner.Text()
+		}
+		close(e.lines)
+	}()
+
+	if err := e.send("uci"); err != nil {
+		return nil, err
+	}
+	for line := range e.lines {
+		if line == "uciok" {
+			break
+		}
+		// option name <name, possibly with spaces> type <type> ...
+		if rest, ok := strings.CutPrefix(line, "option name "); ok {
+			name, _, _ := strings.Cut(rest, " type ")
+			e.options[name] = true
+		}
+	}
+	if err := e.ready(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// ready waits until the engine has processed everything sent so far.
+func (e *uciEngine) ready() error {
+	if err := e.send("isready"); err != nil {
+		return err
+	}
+	return e.waitFor("readyok")
+}
+
+// setOption sets an option the engine announced.
+func (e *uciEngine) setOption(name, value string) error {
+	if !e.options[name] {
+		return fmt.Errorf("the engine has no %q option", name)
+	}
+	if err := e.send("setoption name " + name + " value " + value); err != nil {
+		return err
+	}
+	e.settings = append(e.settings, [2]string{name, value})
+	return nil
+}
+
+// restart starts the same engine again with the same options and limit,
+// after it stopped or to get it unstuck.
+func (e *uciEngine) restart() (*uciEngine, error) {
+	fresh, err := startEngine(e.path)
+	if err != nil {
+		return nil, err
+	}
+	fresh.limit = e.limit
+	for _, s := range e.settings {
+		if err := fresh.setOption(s[0], s[1]); err != nil {
+			fresh.close()
+			return nil, err
+		}
+	}
+	if err := fresh.ready(); err != nil {
+		fresh.close()
+		return nil, err
+	}
+	return fresh, nil
+}
+
+// limitStrength asks the engine to play at about the given Elo rating, or
+// at the given skill level for engines such as Stockfish that have one.
+// Zero leaves the setting alone.
+func (e *uciEngine) limitStrength(elo, skill int) error {
+	var errs []error
+	if elo > 0 {
+		if err := e.setOption("UCI_LimitStrength", "true"); err != nil {
+			errs = append(errs, err)
+		} else if err := e.setOption("UCI_Elo", strconv.Itoa(elo)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if skill > 0 {
+		if err := e.setOption("Skill Level", strconv.Itoa(skill)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := e.ready(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (e *uciEngine) send(line string) error {
+	_, err := fmt.Fprintln(e.stdin, line)
+	return err
+}
+
+// stop tells the engine to end the search it is running, which then
+// returns with the best move found so far. Engines ignore it when idle.
+func (e *uciEngine) stop() error {
+	return e.send("stop")
+}
+
+// waitFor discards output until a line starting with prefix arrives.
+func (e *uciEngine) waitFor(prefix string) error {
+	for line := range e.lines {
+		if strings.HasPrefix(line, prefix) {
+			return nil
+		}
+	}
+	return errors.New("engine exited unexpectedly")
+}
+
+// searchLimit bounds a search by time, nodes or depth, or by the clocks of
+// the game. At most one of them is set.
+type searchLimit struct {
+	movetime time.Duration
+	nodes    int
+	depth    int
+
+	// the time left on each side's clock and their increments, for the
+	// engine to budget its time itself
+	wtime, btime time.Duration
+	winc, binc   time.Duration
+}
+
+func (l searchLimit) isZero() bool {
+	return l == searchLimit{}
+}
+
+// goCommand is the UCI command starting a search within the limit.
+func (l searchLimit) goCommand() string {
+	switch {
+	case l.nodes > 0:
+		return fmt.Sprintf("go nodes %d", l.nodes)
+	case l.depth > 0:
+		return fmt.Sprintf("go depth %d", l.depth)
+	case l.wtime > 0 || l.btime > 0:
+		return fmt.Sprintf("go wtime %d btime %d winc %d binc %d", l.wtime.Milliseconds(), l.btime.Milliseconds(), l.winc.Milliseconds(), l.binc.Milliseconds())
+	default:
+		return fmt.Sprintf("go movetime %d", l.movetime.Milliseconds())
+	}
+}
+
+// searchResult is the outcome of a search: the position's score from
+// White's point of view and the best move in UCI notation. With MultiPV,
+// lines holds the engine's candidate moves, best first.
+type searchResult struct {
+	score      engineScore
+	bestMove   string
+	ponderMove string // the reply the engine expects to its best move, if it says
+	lines      []pvLine
+	tbhits     int // tablebase probes, when the engine has tables
+}
+
+// pvLine is one of the candidate moves of a MultiPV search, with its score
+// from White's point of view.
+type pvLine struct {
+	score engineScore
+	move  string
+}
+
+// search thinks about the position for the given time, or within the
+// engine's limit if one was set.
+func (e *uciEngine) search(pos *chess.Position, movetime time.Duration) (searchResult, error) {
+	return e.searchLines(pos, movetime, 1)
+}
+
+// searchLines searches like search, asking the engine for its n best moves
+// with the MultiPV option, which is set back to 1 afterwards so that games
+// aren't played any slower. Engines without the option give one line.
+func (e *uciEngine) searchLines(pos *chess.Position, movetime time.Duration, n int) (searchResult, error) {
+	return e.searchReporting(pos, movetime, n, nil)
+}
+
+// searchReporting searches like searchLines, passing the engine's progress
+// to report as it thinks, at most every progressInterval.
+func (e *uciEngine) searchReporting(pos *chess.Position, movetime time.Duration, n int, report func(searchProgress)) (searchResult, error) {
+	e.mu.Lock()
+	limit := e.limit
+	e.mu.Unlock()
+	if limit.isZero() {
+		limit = searchLimit{movetime: movetime}
+	}
+	return e.searchWithin(pos, limit, n, report)
+}
+
+// searchWithin searches like searchReporting within the given limit rather
+// than the engine's.
+func (e *uciEngine) searchWithin(pos *chess.Position, limit searchLimit, n int, report func(searchProgress)) (searchResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if n > 1 && e.options["MultiPV"] {
+		if err := e.send("setoption name MultiPV value " + strconv.Itoa(n)); err != nil {
+			return searchResult{}, err
+		}
+		defer e.send("setoption name MultiPV value 1")
+	}
+	if err := e.send("position fen " + pos.String()); err != nil {
+		return searchResult{}, err
+	}
+	if err := e.send(limit.goCommand()); err != nil {
+		return searchResult{}, err
+	}
+	return e.readSearch(pos.Turn(), n, report)
+}
+
+// ponder thinks about the position after the predicted move while the
+// opponent is still to play it. The search goes on until ponderhit turns
+// it into the search for the engine's move, within the limit, or stop ends
+// it, after which its result is of no use. Either command is taken from
+// end, to be sent only once the engine is pondering: one sent before would
+// be lost and leave it pondering for good.
+func (e *uciEngine) ponder(pos *chess.Position, predicted string, limit searchLimit, end <-chan string) (searchResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.send("position fen " + pos.String() + " moves " + predicted); err != nil {
+		return searchResult{}, err
+	}
+	if err := e.send("go ponder " + strings.TrimPrefix(limit.goCommand(), "go ")); err != nil {
+		return searchResult{}, err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case command := <-end:
+			e.send(command)
+		case <-done:
+		}
+	}()
+	return e.readSearch(pos.Turn().Other(), 1, nil)
+}
+
+// readSearch reads the output of a search with turn to move until its best
+// move, keeping the n best lines.
+func (e *uciEngine) readSearch(turn chess.Color, n int, report func(searchProgress)) (searchResult, error) {
+	// UCI scores are relative to the side to move
+	sign := 1
+	if turn == chess.Black {
+		sign = -1
+	}
+	var score engineScore
+	var tbhits int
+	var progress searchProgress
+	var reported time.Time
+	lines := map[int]pvLine{}
+	for line := range e.lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "info":
+			if v, ok := infoValue(fields, "tbhits"); ok {
+				tbhits, _ = strconv.Atoi(v)
+			}
+			if report != nil && progress.update(fields, sign) && time.Since(reported) >= progressInterval {
+				reported = time.Now()
+				report(progress)
+			}
+			s, ok := parseScore(fields)
+			if !ok {
+				continue
+			}
+			s.cp, s.mate = sign*s.cp, sign*s.mate
+			rank := 1
+			if v, ok := infoValue(fields, "multipv"); ok {
+				rank, _ = strconv.Atoi(v)
+			}
+			if rank == 1 {
+				score = s
+			}
+			if mv, ok := infoValue(fields, "pv"); ok {
+				lines[rank] = pvLine{score: s, move: mv}
+			}
+		case "bestmove":
+			result := searchResult{score: score, tbhits: tbhits}
+			if len(fields) > 1 {
+				result.bestMove = fields[1]
+			}
+			if len(fields) > 3 && fields[2] == "ponder" {
+				result.ponderMove = fields[3]
+			}
+			for rank := 1; rank <= n; rank++ {
+				l, ok := lines[rank]
+				if !ok {
+					break
+				}
+				result.lines = append(result.lines, l)
+			}
+			return result, nil
+		}
+	}
+	return searchResult{}, errors.New("engine exited unexpectedly")
+}
+
+// infoValue returns the field following key in the fields of an info line.
+func infoValue(fields []string, key string) (string, bool) {
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == key {
+			return fields[i+1], true
+		}
+	}
+	return "", false
+}
+
+// parseScore extracts the score from the fields of an info line.
+func parseScore(fields []string) (engineScore, bool) {
+	for i := 0; i+2 < len(fields); i++ {
+		if fields[i] != "score" {
+			continue
+		}
+		n, err := strconv.Atoi(fields[i+2])
+		if err != nil {
+			return engineScore{}, false
+		}
+		switch fields[i+1] {
+		case "cp":
+			return engineScore{cp: n}, true
+		case "mate":
+			if n == 0 {
+				// the side to move is mated
+				n = -1
+			}
+			return engineScore{mate: n}, true
+		}
+	}
+	return engineScore{}, false
+}
+
+// close asks the engine to quit and waits for it to exit, killing it if it
+// doesn't within a second. Output is drained meanwhile so that the goroutine
+// reading it can finish.
+func (e *uciEngine) close() {
+	e.send("quit")
+	e.stdin.Close()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-e.lines:
+			if !ok {
+				e.cmd.Wait()
+				return
+			}
+		case <-timeout:
+			e.cmd.Process.Kill()
+			timeout = nil
+		}
+	}
+}
+
+// evalMsg carries the engine's evaluation of a position.
+type evalMsg struct {
+	engine *uciEngine
+	fen    string
+	score  engineScore
+	tbhits int
+	err    error
+}
+
+// nextEval returns a command evaluating the earliest position of the game
+// that has no evaluation yet. Only one evaluation is in flight at a time.
+// Evaluations are keyed by FEN so they survive switching between games.
+func (m *model) nextEval() tea.Cmd {
+	if m.engine == nil || m.evalPending {
+		return nil
+	}
+	for _, pos := range m.game.Positions() {
+		fen := pos.String()
+		if _, ok := m.evals[fen]; ok {
+			continue
+		}
+		m.stopPondering()
+		m.evalPending = true
+		engine := m.engine
+		return func() tea.Msg {
+			result, err := engine.search(pos, evalMovetime)
+			return evalMsg{engine: engine, fen: fen, score: result.score, tbhits: result.tbhits, err: err}
+		}
+	}
+	return nil
+}
+
+// engineReadyMsg kicks off the engine from Init, which can't update the
+// model itself.
+type engineReadyMsg struct{}
+
+func engineReady() tea.Msg {
+	return engineReadyMsg{}
+}
+
+// handleEval stores an evaluation and schedules the next one.
+func (m *model) handleEval(msg evalMsg) tea.Cmd {
+	if msg.engine != m.engine {
+		// from an engine that has stopped since
+		return nil
+	}
+	m.evalPending = false
+	if msg.err != nil {
+		return m.engineFailed(msg.err)
+	}
+	m.evals[msg.fen] = msg.score
+	m.evalStored()
+	if pos := m.game.Position(); pos.String() == msg.fen && m.status == "" {
+		if result := tablebaseResult(msg.score, msg.tbhits, pos, m.syzygyPieces); result != "" {
+			m.status = "Tablebase: " + result
+		}
+	}
+	return m.nextEval()
+}
+
+// engineMoveMsg carries the move the engine chose in a position.
+type engineMoveMsg struct {
+	engine *uciEngine
+	fen    string
+	result searchResult
+	err    error
+}
+
+// handleEngineMove plays the engine's move, unless the game has moved on
+// while it was thinking, or resigns for it when it has been lost for long
+// enough.
+func (m *model) handleEngineMove(msg engineMoveMsg) tea.Cmd {
+	if msg.engine != m.engine {
+		return nil
+	}
+	m.engineThinking = false
+	if msg.err != nil {
+		return m.engineFailed(msg.err)
+	}
+	pos := m.game.Position()
+	if m.mode != modePlay || m.game.Outcome() != chess.NoOutcome || pos.String() != msg.fen {
+		// Update asks again if it is still the engine's turn
+		return nil
+	}
+	if _, ok := m.evals[msg.fen]; !ok {
+		m.evals[msg.fen] = msg.result.score
+	}
+	if m.engineWantsResign(msg.result.score) {
+		m.resign(m.engineColor)
+		m.status = fmt.Sprintf("The engine resigns: it has seen itself lost (%s) for %d moves", msg.result.score, m.resignMoves)
+		return nil
+	}
+	mv, err := chess.UCINotation{}.Decode(pos, msg.result.bestMove)
+	if err == nil {
+		err = m.game.Move(mv)
+	}
+	if err != nil {
+		m.error = fmt.Errorf("engine played %q: %w", msg.result.bestMove, err)
+		return nil
+	}
+	m.ponderFEN, m.ponderMove = m.game.Position().String(), msg.result.ponderMove
+	cmd := m.moveApplied()
+	if m.game.Outcome() == chess.NoOutcome && m.engineWantsDraw() {
+		m.drawOffer = m.engineColor
+	}
+	return cmd
+}
+
+// engineFailed sets aside an engine that crashed or stopped talking. The
+// game goes on without it, the user playing both sides, until the engine is
+// restarted with :engine restart.
+func (m *model) engineFailed(err error) tea.Cmd {
+	engine := m.engine
+	m.engine, m.stoppedEngine = nil, engine
+	m.evalPending, m.engineThinking, m.analysisPending, m.hintPending = false, false, false, false
+	m.pondering, m.ponderMove = nil, ""
+	m.error = nil
+	m.status = fmt.Sprintf("Engine stopped (%v): you play both sides, :engine restart to restart it", err)
+	return func() tea.Msg {
+		engine.close()
+		return nil
+	}
+}
+
+// engineRestartedMsg carries the engine started again by restartEngine.
+type engineRestartedMsg struct {
+	engine *uciEngine
+	err    error
+}
+
+// restartEngine starts the engine again, after it stopped or to get it
+// unstuck. It picks up the game from the current position.
+func (m *model) restartEngine() (tea.Cmd, error) {
+	old := m.engine
+	if old == nil {
+		old = m.stoppedEngine
+	}
+	if old == nil {
+		return nil, errors.New("no engine to restart, start gochess with -engine")
+	}
+	running := m.engine != nil
+	m.engine, m.stoppedEngine = nil, old
+	m.evalPending, m.engineThinking, m.analysisPending, m.hintPending = false, false, false, false
+	m.pondering, m.ponderMove = nil, ""
+	m.status = "Restarting the engine…"
+	return func() tea.Msg {
+		if running {
+			old.close()
+		}
+		engine, err := old.restart()
+		return engineRestartedMsg{engine: engine, err: err}
+	}, nil
+}
+
+// handleEngineRestarted puts the restarted engine back to work. When the
+// engine was restarted twice in a row the first to come back is kept and
+// the other closed.
+func (m *model) handleEngineRestarted(msg engineRestartedMsg) tea.Cmd {
+	if m.engine != nil {
+		if msg.engine == nil {
+			return nil
+		}
+		return func() tea.Msg {
+			msg.engine.close()
+			return nil
+		}
+	}
+	if msg.err != nil {
+		m.status = ""
+		m.error = fmt.Errorf("restarting the engine: %w", msg.err)
+		return nil
+	}
+	m.engine, m.stoppedEngine = msg.engine, nil
+	m.status = "Engine restarted"
+	return m.nextEval()
+}
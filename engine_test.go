@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeEngine is a UCI engine in the test, answering each command the way
+// reply says. The commands it gets are passed on to received.
+type fakeEngine struct {
+	*uciEngine
+	received chan string
+}
+
+func newFakeEngine(t *testing.T, reply func(command string) []string) *fakeEngine {
+	t.Helper()
+	r, w := io.Pipe()
+	f := &fakeEngine{
+		uciEngine: &uciEngine{path: "fake", stdin: w, lines: make(chan string, 64), options: map[string]bool{}},
+		received:  make(chan string, 64),
+	}
+	go func() {
+		defer close(f.lines)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			command := scanner.Text()
+			f.received <- command
+			for _, line := range reply(command) {
+				f.lines <- line
+			}
+		}
+	}()
+	t.Cleanup(func() { w.Close() })
+	return f
+}
+
+// expect waits for the engine to get a command starting with prefix,
+// failing on any of the unwanted ones before it.
+func (f *fakeEngine) expect(t *testing.T, prefix string, unwanted ...string) string {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case command := <-f.received:
+			if strings.HasPrefix(command, prefix) {
+				return command
+			}
+			for _, u := range unwanted {
+				if strings.HasPrefix(command, u) {
+					t.Fatalf("engine got %q while waiting for %q", command, prefix)
+				}
+			}
+		case <-timeout:
+			t.Fatalf("engine never got %q", prefix)
+			return ""
+		}
+	}
+}
+
+func TestReadSearch(t *testing.T) {
+	f := newFakeEngine(t, func(command string) []string {
+		if strings.HasPrefix(command, "go ") {
+			return []string{
+				"info depth 1 score cp 20 pv c7c5",
+				"info depth 2 score cp -35 pv e7e5",
+				"bestmove e7e5 ponder g1f3",
+			}
+		}
+		return nil
+	})
+	pos := newTestGame(t, "e4").Position()
+	result, err := f.search(pos, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Black is to move, so the engine's scores are turned around
+	if result.score != (engineScore{cp: 35}) || result.bestMove != "e7e5" || result.ponderMove != "g1f3" {
+		t.Errorf("search = %+v, want score +0.35, best e7e5 and ponder g1f3", result)
+	}
+}
+
+// scriptEngine writes a UCI engine as a shell script that does nothing but
+// start up and quit, for tests that start and close engines.
+func scriptEngine(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the engine is a shell script")
+	}
+	path := filepath.Join(t.TempDir(), "engine")
+	script := `#!/bin/sh
+while read -r command; do
+	case "$command" in
+	uci) echo uciok ;;
+	isready) echo readyok ;;
+	quit) exit 0 ;;
+	esac
+done
+`
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRestartEngineTwice(t *testing.T) {
+	engine, err := startEngine(scriptEngine(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := newUIModel(t)
+	u.m.engine = engine
+	t.Cleanup(func() {
+		if u.m.engine != nil {
+			u.m.engine.close()
+		}
+	})
+
+	first, err := u.m.restartEngine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := u.m.restartEngine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted := []engineRestartedMsg{first().(engineRestartedMsg), second().(engineRestartedMsg)}
+	for _, msg := range restarted {
+		if msg.err != nil {
+			t.Fatal(msg.err)
+		}
+	}
+	u.send(restarted[0])
+	close := u.m.handleEngineRestarted(restarted[1])
+	if close == nil {
+		t.Fatal("the engine that came back second is kept running")
+	}
+	close()
+	if u.m.engine != restarted[0].engine {
+		t.Error("the engine that came back first isn't the one kept")
+	}
+	if state := restarted[1].engine.cmd.ProcessState; state == nil || !state.Exited() {
+		t.Error("the engine that came back second is still running")
+	}
+}
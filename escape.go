@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// escGesture is what pressing Esc three times in quick succession does,
+// for players who'd rather not be asked to confirm. Without it a single
+// Esc quits.
+type escGesture int
+
+const (
+	escOff    escGesture = iota
+	escResign            // resign the game for the side to move
+	escQuit              // quit; a single Esc no longer does
+)
+
+func parseEscGesture(s string) (escGesture, error) {
+	switch s {
+	case "off":
+		return escOff, nil
+	case "resign":
+		return escResign, nil
+	case "quit":
+		return escQuit, nil
+	default:
+		return escOff, fmt.Errorf("unknown Esc gesture %q (want resign, quit or off)", s)
+	}
+}
+
+const (
+	// escPresses presses of Esc, each within escWindow of the one before,
+	// make the gesture.
+	escPresses = 3
+	escWindow  = 500 * time.Millisecond
+)
+
+// escSequence counts the Esc presses in a row that had nothing to clear
+// or leave.
+type escSequence struct {
+	count int
+	last  time.Time
+}
+
+// press records an Esc pressed at now and reports whether it completes
+// the gesture, after which counting starts over.
+func (s *escSequence) press(now time.Time) bool {
+	if s.count > 0 && now.Sub(s.last) > escWindow {
+		s.count = 0
+	}
+	s.count++
+	s.last = now
+	if s.count < escPresses {
+		return false
+	}
+	*s = escSequence{}
+	return true
+}
+
+// escPressed handles an Esc that had no typed input to clear or mode to
+// leave, counting it toward the gesture.
+func (m *model) escPressed(now time.Time) tea.Cmd {
+	if !m.escs.press(now) {
+		action := "quit"
+		if m.escGesture == escResign {
+			action = "resign"
+		}
+		m.status = fmt.Sprintf("Esc %d more times quickly to %s", escPresses-m.escs.count, action)
+		if m.escs.count == escPresses-1 {
+			m.status = "Esc once more to " + action
+		}
+		return nil
+	}
+	if m.escGesture == escQuit {
+		return tea.Quit
+	}
+	m.status = ""
+	switch {
+	case m.game.Outcome() != chess.NoOutcome:
+		m.error = errors.New("the game is already over")
+	case m.engineToMove():
+		m.error = errEngineToMove
+	default:
+		m.resign(m.game.Position().Turn())
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/notnil/chess"
+)
+
+// evalCommand matches the evaluation command lichess and other tools read
+// from PGN comments, e.g. [%eval 0.35] or [%eval #-3].
+var evalCommand = regexp.MustCompile(`\s*\[%eval [^\]]*\]`)
+
+// clkCommand matches the clock command, e.g. [%clk 0:04:58].
+var clkCommand = regexp.MustCompile(`\s*\[%clk [^\]]*\]`)
+
+// engineNotes are the engine's findings written into exported PGN as
+// comments, keyed by FEN like the evaluations they come from, along with
+// the clock times with -clk.
+type engineNotes struct {
+	evals  map[string]engineScore
+	best   map[string]string // best moves found by analysis, in UCI notation
+	clocks []time.Duration   // by ply, the time left after the move, 0 if unknown
+}
+
+func (m model) engineNotes() engineNotes {
+	notes := engineNotes{evals: m.evals, best: m.bestMoves}
+	if m.clkComments && m.clock != nil {
+		notes.clocks = m.clock.left
+	}
+	return notes
+}
+
+// clk returns the [%clk] command of the ply-th move, or "" when its time
+// isn't known.
+func (n engineNotes) clk(ply int) string {
+	if ply >= len(n.clocks) || n.clocks[ply] <= 0 {
+		return ""
+	}
+	return "[%clk " + clkText(n.clocks[ply]) + "]"
+}
+
+// evalText formats a score the way %eval takes it: pawns from White's
+// point of view, or # and the moves to mate.
+func evalText(s engineScore) string {
+	if s.mate != 0 {
+		return fmt.Sprintf("#%d", s.mate)
+	}
+	return fmt.Sprintf("%.2f", float64(s.cp)/100)
+}
+
+// comment returns the notes on a move: the evaluation of the position it
+// leads to and, when analysis found a different move, the best move in the
+// position it was played in.
+func (n engineNotes) comment(before, after *chess.Position, played *chess.Move) string {
+	var parts []string
+	if s, ok := n.evals[after.String()]; ok {
+		parts = append(parts, "[%eval "+evalText(s)+"]")
+	}
+	if best := n.best[before.String()]; best != "" && best != played.String() {
+		for _, mv := range before.ValidMoves() {
+			if mv.String() == best {
+				parts = append(parts, "Best: "+chess.AlgebraicNotation{}.Encode(before, mv))
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// hasEval reports whether the notes evaluate the position, in which case
+// evaluations loaded with the game are replaced.
+func (n engineNotes) hasEval(pos *chess.Position) bool {
+	_, ok := n.evals[pos.String()]
+	return ok
+}
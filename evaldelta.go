@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// evalDeltaCap bounds the evaluations compared, in centipawns, so that a
+// side already lost or winning by far isn't judged on how far.
+const evalDeltaCap = 1000
+
+// moveJudgement is how much a move lost by the engine's evaluation.
+type moveJudgement int
+
+const (
+	judgedFine moveJudgement = iota
+	judgedInaccuracy
+	judgedMistake
+	judgedBlunder
+)
+
+// judgements holds, by judgement, the centipawns a move has to lose for
+// it, its symbol and the style of its delta in the history.
+var judgements = []struct {
+	loss   int
+	symbol string
+	style  lipgloss.Style
+}{
+	judgedFine:       {0, "", lipgloss.NewStyle().Foreground(lipgloss.Color("#7F7F7F"))},
+	judgedInaccuracy: {50, "?!", lipgloss.NewStyle().Foreground(lipgloss.Color("#DEBA90"))},
+	judgedMistake:    {100, "?", lipgloss.NewStyle().Foreground(lipgloss.Color("#E0A060"))},
+	judgedBlunder:    {300, "??", lipgloss.NewStyle().Foreground(lipgloss.Color("#E07B67")).Bold(true)},
+}
+
+// cappedCP turns a score into centipawns from White's side within
+// evalDeltaCap, a mate counting as the cap.
+func cappedCP(s engineScore) int {
+	switch {
+	case s.mate > 0:
+		return evalDeltaCap
+	case s.mate < 0:
+		return -evalDeltaCap
+	}
+	return min(max(s.cp, -evalDeltaCap), evalDeltaCap)
+}
+
+// evalDelta returns how the evaluation changed with the move at index ply
+// of the game, from the side of the player who made it, and whether both
+// positions have been evaluated. A move that gives away a pawn has a delta
+// of -100 whichever side made it.
+func (m model) evalDelta(ply int) (int, bool) {
+	positions := m.game.Positions()
+	if ply+1 >= len(positions) {
+		return 0, false
+	}
+	before, ok := m.evals[positions[ply].String()]
+	if !ok {
+		return 0, false
+	}
+	after, ok := m.evals[positions[ply+1].String()]
+	if !ok {
+		return 0, false
+	}
+	delta := cappedCP(after) - cappedCP(before)
+	if positions[ply].Turn() == chess.Black {
+		delta = -delta
+	}
+	return delta, true
+}
+
+// judgeMove judges a move by its delta.
+func judgeMove(delta int) moveJudgement {
+	for j := judgedBlunder; j > judgedFine; j-- {
+		if -delta >= judgements[j].loss {
+			return j
+		}
+	}
+	return judgedFine
+}
+
+// evalStored refreshes the history for a new evaluation, which may complete
+// the delta of a move.
+func (m *model) evalStored() {
+	if m.evalDeltas {
+		m.updateHistoryViewport()
+	}
+}
+
+// toggleEvalDeltas shows or hides the deltas in the history.
+func (m *model) toggleEvalDeltas() error {
+	if m.engine == nil {
+		return errors.New("the deltas come from the engine's evaluations, start gochess with -engine or press f6")
+	}
+	m.evalDeltas = !m.evalDeltas
+	m.updateHistoryViewport()
+	m.status = "Evaluation deltas hidden"
+	if m.evalDeltas {
+		m.status = "Evaluation deltas shown: ?! inaccuracy, ? mistake, ?? blunder"
+	}
+	return nil
+}
+
+// moveTokens returns the tokens of the i-th move of the history: the move
+// and, with -eval-deltas once both positions around it are evaluated, its
+// symbol and delta colored by how bad it was, e.g. "?? -3.20".
+func (m model) moveTokens(i int) []historyToken {
+	ply := m.historyDrop + i
+	tokens := []historyToken{{text: m.moveText(i), ply: ply + 1}}
+	if !m.evalDeltas {
+		return tokens
+	}
+	delta, ok := m.evalDelta(ply)
+	if !ok {
+		return tokens
+	}
+	j := judgeMove(delta)
+	text := fmt.Sprintf("%+.2f", float64(delta)/100)
+	if symbol := judgements[j].symbol; symbol != "" {
+		text = symbol + " " + text
+	}
+	return append(tokens, historyToken{text: text, style: &judgements[j].style})
+}
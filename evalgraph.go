@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	evalGraphHeight = 3   // rows of block characters
+	evalGraphCap    = 800 // centipawns; larger advantages and mates are drawn at the cap
+)
+
+var (
+	evalBarStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#DEBA90"))
+	evalCurrentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#7FA650"))
+	evalBlocks       = []rune(" ▁▂▃▄▅▆▇█")
+)
+
+// evalLevel maps a score to the number of filled eighths of the graph,
+// with equality in the middle.
+func evalLevel(s engineScore) int {
+	cp := min(max(s.cp, -evalGraphCap), evalGraphCap)
+	switch {
+	case s.mate > 0:
+		cp = evalGraphCap
+	case s.mate < 0:
+		cp = -evalGraphCap
+	}
+	levels := evalGraphHeight * 8
+	return (cp + evalGraphCap) * levels / (2 * evalGraphCap)
+}
+
+// currentPly is the ply shown on the board.
+func (m model) currentPly() int {
+	if m.mode == modeReview {
+		return m.viewPly
+	}
+	return len(m.game.Positions()) - 1
+}
+
+// renderEvalGraph draws one column per ply, White's advantage going up.
+// When the game doesn't fit, the columns up to the current ply are shown.
+func (m model) renderEvalGraph(width int) string {
+	positions := m.game.Positions()
+	current := m.currentPly()
+	width -= historyStyle.GetHorizontalFrameSize()
+	first := max(current-width+1, 0)
+	last := min(first+width, len(positions))
+
+	var rows [evalGraphHeight]strings.Builder
+	for ply := first; ply < last; ply++ {
+		score, ok := m.evals[positions[ply].String()]
+		style := evalBarStyle
+		if ply == current {
+			style = evalCurrentStyle
+		}
+		for row := range evalGraphHeight {
+			block := ' '
+			if ok {
+				// rows are drawn top to bottom
+				fill := evalLevel(score) - (evalGraphHeight-1-row)*8
+				block = evalBlocks[min(max(fill, 0), 8)]
+			} else if ply == current {
+				block = '·'
+			}
+			rows[row].WriteString(style.Render(string(block)))
+		}
+	}
+
+	label := "Eval: …"
+	if score, ok := m.evals[positions[current].String()]; ok {
+		label = "Eval: " + score.String()
+	}
+	lines := []string{label}
+	for i := range rows {
+		lines = append(lines, rows[i].String())
+	}
+	return historyStyle.Width(width).Render(strings.Join(lines, "\n"))
+}
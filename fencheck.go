@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// castlingSquares are the squares the king and rook of each castling right
+// start on, in standard chess.
+var castlingSquares = map[rune]struct {
+	color      chess.Color
+	side       string
+	king, rook chess.Square
+}{
+	'K': {chess.White, "kingside", chess.E1, chess.H1},
+	'Q': {chess.White, "queenside", chess.E1, chess.A1},
+	'k': {chess.Black, "kingside", chess.E8, chess.H8},
+	'q': {chess.Black, "queenside", chess.E8, chess.A8},
+}
+
+// fenProblems lists what makes the position of a FEN impossible: wrong king
+// counts, too many pieces, pawns on the back ranks, the side that just
+// moved being in check, and castling rights or an en passant square that
+// don't match the board. It can't prove a position reachable, only catch
+// the usual mistakes made editing FENs by hand. The error is for FENs that
+// can't be read at all, saying what is wrong with them.
+func fenProblems(s string) ([]string, error) {
+	s = strings.Join(strings.Fields(s), " ")
+	if err := fenSyntaxError(s); err != nil {
+		return nil, err
+	}
+	fen, err := chess.FEN(s)
+	if err != nil {
+		return nil, err
+	}
+	pos := chess.NewGame(fen).Position()
+	board := pos.Board()
+	fields := strings.Fields(s)
+
+	var problems []string
+	kings := map[chess.Color]chess.Square{}
+	counts := map[chess.Piece]int{}
+	total := map[chess.Color]int{}
+	for sq := chess.A1; sq <= chess.H8; sq++ {
+		p := board.Piece(sq)
+		if p == chess.NoPiece {
+			continue
+		}
+		counts[p]++
+		total[p.Color()]++
+		if p.Type() == chess.King {
+			kings[p.Color()] = sq
+		}
+		if p.Type() == chess.Pawn && (sq.Rank() == chess.Rank1 || sq.Rank() == chess.Rank8) {
+			problems = append(problems, fmt.Sprintf("%s pawn on %s, pawns can't stand on the first or last rank", strings.ToLower(p.Color().Name()), sq))
+		}
+	}
+	for _, color := range []chess.Color{chess.White, chess.Black} {
+		if n := counts[chess.NewPiece(chess.King, color)]; n != 1 {
+			problems = append(problems, fmt.Sprintf("%s has %d kings, not one", color.Name(), n))
+		}
+		if n := counts[chess.NewPiece(chess.Pawn, color)]; n > 8 {
+			problems = append(problems, fmt.Sprintf("%s has %d pawns, more than 8", color.Name(), n))
+		}
+		if total[color] > 16 {
+			problems = append(problems, fmt.Sprintf("%s has %d pieces, more than 16", color.Name(), total[color]))
+		}
+	}
+
+	// the side that just moved can't have left its king in check; the move
+	// generator needs both kings to tell
+	if counts[chess.WhiteKing] == 1 && counts[chess.BlackKing] == 1 {
+		waiting := pos.Turn().Other()
+		for _, mv := range pos.ValidMoves() {
+			if mv.S2() == kings[waiting] {
+				problems = append(problems, fmt.Sprintf("%s is in check but it is %s's turn", waiting.Name(), pos.Turn().Name()))
+				break
+			}
+		}
+	}
+
+	if len(fields) > 2 && fields[2] != "-" {
+		for _, r := range fields[2] {
+			c, ok := castlingSquares[r]
+			if !ok {
+				continue
+			}
+			if board.Piece(c.king) != chess.NewPiece(chess.King, c.color) {
+				problems = append(problems, fmt.Sprintf("%s can't castle %s: the king isn't on %s", c.color.Name(), c.side, c.king))
+			} else if board.Piece(c.rook) != chess.NewPiece(chess.Rook, c.color) {
+				problems = append(problems, fmt.Sprintf("%s can't castle %s: there is no rook on %s", c.color.Name(), c.side, c.rook))
+			}
+		}
+	}
+
+	if len(fields) > 3 && fields[3] != "-" {
+		if sq, ok := parseSquare(fields[3]); ok && !followsDoublePush(board, sq, pos.Turn()) {
+			problems = append(problems, fmt.Sprintf("en passant square %s doesn't follow a double pawn push", sq))
+		}
+	}
+	return problems, nil
+}
+
+// checkFEN returns the reason a FEN can't be played from: why it can't be
+// read, or the problems that make its position impossible.
+func checkFEN(s string) error {
+	problems, err := fenProblems(s)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return errors.New("impossible position: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// fenSyntaxError explains why a FEN can't be read, more precisely than the
+// move generator's errors do, or returns nil if nothing is found.
+func fenSyntaxError(s string) error {
+	fields := strings.Fields(s)
+	if len(fields) != 6 {
+		return fmt.Errorf("a FEN has 6 fields (board, turn, castling, en passant, halfmove clock, move number), this one has %d", len(fields))
+	}
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != 8 {
+		return fmt.Errorf("the board has %d ranks, not 8", len(ranks))
+	}
+	for i, rank := range ranks {
+		squares := 0
+		for _, r := range rank {
+			switch {
+			case r >= '1' && r <= '8':
+				squares += int(r - '0')
+			case strings.ContainsRune("pnbrqkPNBRQK", r):
+				squares++
+			default:
+				return fmt.Errorf("rank %d has %q, which is neither a piece nor a number of empty squares", 8-i, r)
+			}
+		}
+		if squares != 8 {
+			return fmt.Errorf("rank %d has %d squares, not 8", 8-i, squares)
+		}
+	}
+	if fields[1] != "w" && fields[1] != "b" {
+		return fmt.Errorf("the side to move is %q, want w or b", fields[1])
+	}
+	if fields[2] != "-" && strings.Trim(fields[2], "KQkq") != "" {
+		return fmt.Errorf("the castling rights are %q, want letters of KQkq or -", fields[2])
+	}
+	if _, ok := parseSquare(fields[3]); fields[3] != "-" && !ok {
+		return fmt.Errorf("the en passant square is %q, want a square such as e3 or -", fields[3])
+	}
+	if n, err := strconv.Atoi(fields[4]); err != nil || n < 0 {
+		return fmt.Errorf("the halfmove clock is %q, want a number from 0", fields[4])
+	}
+	if n, err := strconv.Atoi(fields[5]); err != nil || n < 1 {
+		return fmt.Errorf("the move number is %q, want a number from 1", fields[5])
+	}
+	return nil
+}
+
+// followsDoublePush reports whether the board is consistent with the
+// opponent of the side to move having just pushed a pawn two squares over
+// sq: the pawn stands in front of sq and the squares it crossed are empty.
+func followsDoublePush(board *chess.Board, sq chess.Square, turn chess.Color) bool {
+	rank, pawnRank, fromRank := chess.Rank6, chess.Rank5, chess.Rank7
+	if turn == chess.Black {
+		rank, pawnRank, fromRank = chess.Rank3, chess.Rank4, chess.Rank2
+	}
+	if sq.Rank() != rank {
+		return false
+	}
+	pawn := chess.NewPiece(chess.Pawn, turn.Other())
+	return board.Piece(chess.NewSquare(sq.File(), pawnRank)) == pawn &&
+		board.Piece(sq) == chess.NoPiece &&
+		board.Piece(chess.NewSquare(sq.File(), fromRank)) == chess.NoPiece
+}
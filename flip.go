@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+const (
+	flipFrames        = 4
+	flipFrameInterval = 40 * time.Millisecond
+)
+
+// flipFrameMsg advances the flip animation started with the given sequence
+// number.
+type flipFrameMsg int
+
+// flipBoard toggles the orientation and starts a short wipe from the old
+// orientation to the new one.
+func (m *model) flipBoard() tea.Cmd {
+	m.flipped = !m.flipped
+	m.flipFrame = 1
+	m.flipSeq++
+	return nextFlipFrame(m.flipSeq)
+}
+
+func nextFlipFrame(seq int) tea.Cmd {
+	return tea.Tick(flipFrameInterval, func(time.Time) tea.Msg {
+		return flipFrameMsg(seq)
+	})
+}
+
+// handleFlipFrame moves the animation on, ignoring frames of an animation
+// that was skipped or restarted.
+func (m *model) handleFlipFrame(msg flipFrameMsg) tea.Cmd {
+	if int(msg) != m.flipSeq || m.flipFrame == 0 {
+		return nil
+	}
+	m.flipFrame++
+	if m.flipFrame >= flipFrames {
+		m.flipFrame = 0
+		return nil
+	}
+	return nextFlipFrame(m.flipSeq)
+}
+
+// renderFlippingBoard draws a frame of the flip animation: the rows above
+// the wipe already show the new orientation, the rows below the old one.
+func renderFlippingBoard(pos *chess.Position, width int, opts boardOptions, frame int) string {
+	next := strings.Split(renderBoard(pos, width, opts), "\n")
+	opts.flipped = !opts.flipped
+	prev := strings.Split(renderBoard(pos, width, opts), "\n")
+	cut := len(next) * frame / flipFrames
+	return strings.Join(append(next[:cut:cut], prev[cut:]...), "\n")
+}
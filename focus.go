@@ -0,0 +1,68 @@
+package main
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+var (
+	lastMoveFromStyle = lipgloss.NewStyle().Background(lipgloss.Color("#A9A26A"))
+	lastMoveToStyle   = lipgloss.NewStyle().Background(lipgloss.Color("#CDC66E"))
+)
+
+// shownMove is the move that led to the position shown while reviewing, or
+// nil at the start of the game and in the other modes.
+func (m model) shownMove() *chess.Move {
+	if m.mode != modeReview || m.viewPly == 0 {
+		return nil
+	}
+	return m.game.Moves()[m.viewPly-1]
+}
+
+// focusSquares are the squares of the pieces that moved last in the
+// position shown while reviewing with focus on: the moved piece and, after
+// castling, the rook. Pieces elsewhere are drawn faint.
+func (m model) focusSquares() map[chess.Square]bool {
+	if !m.focus {
+		return nil
+	}
+	mv := m.shownMove()
+	if mv == nil {
+		return nil
+	}
+	squares := map[chess.Square]bool{mv.S2(): true}
+	switch {
+	case mv.HasTag(chess.KingSideCastle):
+		squares[chess.NewSquare(chess.FileF, mv.S2().Rank())] = true
+	case mv.HasTag(chess.QueenSideCastle):
+		squares[chess.NewSquare(chess.FileD, mv.S2().Rank())] = true
+	}
+	return squares
+}
+
+// lastMoveHighlights marks where the last move came from and went to while
+// reviewing with focus on.
+func (m model) lastMoveHighlights() map[chess.Square]lipgloss.Style {
+	if !m.focus {
+		return nil
+	}
+	mv := m.shownMove()
+	if mv == nil {
+		return nil
+	}
+	return map[chess.Square]lipgloss.Style{
+		mv.S1(): lastMoveFromStyle,
+		mv.S2(): lastMoveToStyle,
+	}
+}
+
+// toggleFocus turns dimming the pieces that didn't take part in the last
+// move on or off.
+func (m *model) toggleFocus() {
+	m.focus = !m.focus
+	if m.focus {
+		m.status = "Focus on the last move while reviewing"
+	} else {
+		m.status = "Focus off"
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/notnil/chess"
+)
+
+// onlyMove returns the move of a position with a single legal move, nil
+// when there are none or several.
+func onlyMove(pos *chess.Position) *chess.Move {
+	if moves := pos.ValidMoves(); len(moves) == 1 {
+		return moves[0]
+	}
+	return nil
+}
+
+// forcedPlies counts the moves of the game from ply on that were the only
+// legal move, stopping at the first that wasn't.
+func forcedPlies(game *chess.Game, ply int) int {
+	positions := game.Positions()
+	n := 0
+	for ply+n < len(game.Moves()) && onlyMove(positions[ply+n]) != nil {
+		n++
+	}
+	return n
+}
+
+// onlyMoveNote points out an only move in the position on the board while
+// reviewing or analysing, and how long the forced sequence runs in review.
+func (m model) onlyMoveNote() string {
+	if m.mode != modeReview && m.mode != modeAnalysis {
+		return ""
+	}
+	pos := m.displayedPosition()
+	mv := onlyMove(pos)
+	if mv == nil {
+		return ""
+	}
+	note := "only move: " + chess.AlgebraicNotation{}.Encode(pos, mv)
+	if m.mode == modeReview {
+		if n := forcedPlies(m.game, m.viewPly); n > 1 {
+			note += fmt.Sprintf(", %d forced plies (f skips them)", n)
+		}
+	}
+	return note
+}
+
+// skipForced steps the review past the only moves ahead.
+func (m *model) skipForced() {
+	n := forcedPlies(m.game, m.viewPly)
+	if n == 0 {
+		m.status = "The next move wasn't forced"
+		return
+	}
+	m.viewPly += n
+}
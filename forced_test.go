@@ -0,0 +1,99 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// boxedKings is a position where White's king can only go to b1 and then
+// Black's only to g8, the rest of the pieces being blocked.
+const boxedKings = "7k/7p/7P/8/8/1p6/1P6/K7 w - - 0 1"
+
+func TestOnlyMove(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		moves []string
+		want  string // the only move, "" for none
+	}{
+		{"boxed king", boxedKings, nil, "Kb1"},
+		{"boxed king of Black", boxedKings, []string{"Kb1"}, "Kg8"},
+		{"two moves", boxedKings, []string{"Kb1", "Kg8"}, ""},
+		{"the start", startFEN, nil, ""},
+		{"one way out of check", "k7/8/1K6/8/8/8/8/R7 b - - 0 1", nil, "Kb8"},
+		{"checkmate", startFEN, []string{"f3", "e5", "g4", "Qh4#"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := newTestGameFrom(t, tt.fen, tt.moves...).Position()
+			got := ""
+			if mv := onlyMove(pos); mv != nil {
+				got = chess.AlgebraicNotation{}.Encode(pos, mv)
+			}
+			if got != tt.want {
+				t.Errorf("onlyMove = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForcedPlies(t *testing.T) {
+	game := newTestGameFrom(t, boxedKings, "Kb1", "Kg8", "Kc1", "Kf8")
+	for ply, want := range []int{2, 1, 0, 0, 0} {
+		if got := forcedPlies(game, ply); got != want {
+			t.Errorf("forcedPlies from ply %d = %d, want %d", ply, got, want)
+		}
+	}
+}
+
+// forcedReview reviews the boxed kings game from its start.
+func forcedReview(t *testing.T) *uiModel {
+	t.Helper()
+	u := newUIModel(t)
+	u.m.startGame(newTestGameFrom(t, boxedKings, "Kb1", "Kg8", "Kc1", "Kf8"))
+	u.send(tea.KeyMsg{Type: tea.KeyF3})
+	u.send(tea.KeyMsg{Type: tea.KeyHome})
+	if u.m.mode != modeReview || u.m.viewPly != 0 {
+		t.Fatalf("reviewing ply %d in mode %v, want the start in review", u.m.viewPly, u.m.mode)
+	}
+	return u
+}
+
+func TestOnlyMoveNote(t *testing.T) {
+	u := forcedReview(t)
+	if got, want := u.m.onlyMoveNote(), "only move: Kb1, 2 forced plies (f skips them)"; got != want {
+		t.Errorf("note at the start %q, want %q", got, want)
+	}
+	u.send(tea.KeyMsg{Type: tea.KeyRight})
+	if got, want := u.m.onlyMoveNote(), "only move: Kg8"; got != want {
+		t.Errorf("note after Kb1 %q, want %q", got, want)
+	}
+	u.send(tea.KeyMsg{Type: tea.KeyRight})
+	if got := u.m.onlyMoveNote(); got != "" {
+		t.Errorf("note after Kg8 %q, want none", got)
+	}
+
+	// only pointed out while looking back at the game
+	play := newUIModel(t)
+	play.m.startGame(newTestGameFrom(t, boxedKings))
+	if got := play.m.onlyMoveNote(); got != "" {
+		t.Errorf("note while playing %q, want none", got)
+	}
+}
+
+func TestSkipForced(t *testing.T) {
+	u := forcedReview(t)
+	u.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	if u.m.viewPly != 2 {
+		t.Errorf("f skipped to ply %d, want 2", u.m.viewPly)
+	}
+	u.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	if u.m.viewPly != 2 {
+		t.Errorf("f skipped an unforced move, to ply %d", u.m.viewPly)
+	}
+	if u.m.status != "The next move wasn't forced" {
+		t.Errorf("status %q, want the next move not forced", u.m.status)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+var gameOverSelectedStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#FFFDF5")).
+	Background(lipgloss.Color("#BC7342")).
+	Padding(0, 1)
+
+// gameOverAction is an entry of the menu shown when a game ends.
+type gameOverAction struct {
+	label string
+	run   func(m *model) tea.Cmd
+}
+
+var gameOverActions = []gameOverAction{
+	{"New game", func(m *model) tea.Cmd {
+		return m.newGame()
+	}},
+	{"Rematch", func(m *model) tea.Cmd {
+		return m.rematch()
+	}},
+	{"Save PGN", func(m *model) tea.Cmd {
+		m.error = m.writeGame(fmt.Sprintf("gochess-%d.pgn", time.Now().Unix()))
+		return nil
+	}},
+	{"Analyze", func(m *model) tea.Cmd {
+		m.setMode(modeReview)
+		return nil
+	}},
+	{"Quit", func(m *model) tea.Cmd {
+		return tea.Quit
+	}},
+}
+
+// rematch starts a new game with the colors swapped: the engine takes the
+// other side and the board turns around. The time control stays the same.
+func (m *model) rematch() tea.Cmd {
+	if m.engine != nil && m.engineColor != chess.NoColor {
+		m.engineColor = m.engineColor.Other()
+	}
+	m.flipped = !m.flipped
+	return m.newGame()
+}
+
+// gameOverMenu is the modal shown when the game being played ends.
+type gameOverMenu struct {
+	selected int
+}
+
+// updateGameOver moves through the menu with the arrow keys and runs the
+// selected action on enter. Esc closes the menu to look at the board.
+func (m model) updateGameOver(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.gameOver = nil
+	case "up", "shift+tab":
+		m.gameOver.selected = (m.gameOver.selected + len(gameOverActions) - 1) % len(gameOverActions)
+	case "down", "tab":
+		m.gameOver.selected = (m.gameOver.selected + 1) % len(gameOverActions)
+	case "enter":
+		action := gameOverActions[m.gameOver.selected]
+		m.gameOver = nil
+		return m, action.run(&m)
+	}
+	return m, nil
+}
+
+// updateGameEnded handles keys once the game is over and its menu has been
+// closed. Moves can't be entered any more: n starts a new game, esc quits,
+// and the history still scrolls. Commands work as usual.
+func (m model) updateGameEnded(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "n":
+		return m, m.newGame()
+	case "esc", "ctrl+c":
+		return m, tea.Quit
+	case "ctrl+x":
+		m.copyPGN()
+		return m, nil
+	case "ctrl+o":
+		m.copyLastMove()
+		return m, nil
+	case "ctrl+z":
+		m.error = m.undo()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// methodNames describes how a game ended.
+var methodNames = map[chess.Method]string{
+	chess.Checkmate:            "checkmate",
+	chess.Resignation:          "resignation",
+	chess.DrawOffer:            "agreement",
+	chess.Stalemate:            "stalemate",
+	chess.ThreefoldRepetition:  "threefold repetition",
+	chess.FivefoldRepetition:   "fivefold repetition",
+	chess.FiftyMoveRule:        "the fifty-move rule",
+	chess.SeventyFiveMoveRule:  "the seventy-five-move rule",
+	chess.InsufficientMaterial: "insufficient material",
+}
+
+// gameOverReason explains the result of the game.
+func (m model) gameOverReason() string {
+	game := m.liveGame()
+	switch {
+	case m.clock != nil && m.clock.flagged != chess.NoColor:
+		return m.clock.flagged.Name() + " ran out of time"
+	case game.Method() == chess.DrawOffer && insufficientMaterial(game.Position().Board()):
+		// dead positions are claimed as agreed draws
+		return "by insufficient material"
+	case game.Method() == chess.Resignation:
+		loser := chess.White
+		if game.Outcome() == chess.WhiteWon {
+			loser = chess.Black
+		}
+		if m.engine != nil && loser == m.engineColor {
+			return loser.Name() + " (the engine) resigned"
+		}
+		return loser.Name() + " resigned"
+	case game.Method() == chess.Checkmate:
+		return game.Position().Turn().Name() + "'s king is in check with no way out"
+	case game.Method() == chess.Stalemate:
+		return game.Position().Turn().Name() + " has no legal moves but isn't in check"
+	}
+	return "by " + methodNames[game.Method()]
+}
+
+func (g gameOverMenu) View(m model) string {
+	game := m.liveGame()
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Game over") + "\n\n")
+	sb.WriteString(resultString(game) + "\n")
+	sb.WriteString(statusMessageStyle.Render(m.gameOverReason()) + "\n")
+	sb.WriteString(statusMessageStyle.Faint(true).Render(fmt.Sprintf("%d moves", (len(game.Moves())+1)/2)) + "\n\n")
+	for i, action := range gameOverActions {
+		if i == g.selected {
+			sb.WriteString(gameOverSelectedStyle.Render(action.label) + "\n")
+		} else {
+			sb.WriteString(lipgloss.NewStyle().Padding(0, 1).Render(action.label) + "\n")
+		}
+	}
+	sb.WriteString("\n" + statusMessageStyle.Faint(true).Render("↑/↓ choose • enter select • esc close"))
+	return helpStyle.Render(sb.String())
+}
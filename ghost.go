@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/notnil/chess"
+)
+
+// ghostMove returns the move of the selected piece to the square under the
+// mouse while analysing, to preview without playing it, or nil.
+func (m model) ghostMove() *chess.Move {
+	if m.mode != modeAnalysis || !m.hovering {
+		return nil
+	}
+	from, ok := m.selectedSquare()
+	if !ok || from == m.hovered {
+		return nil
+	}
+	return findMove(m.validMoves, from, m.hovered)
+}
+
+// ghostBoard returns the position the move would lead to and the options to
+// draw it with: the moved piece is faint at its target, like a ghost of
+// the move, and the other pieces stay as they are. The turn isn't marked,
+// as the move hasn't been played.
+func (m model) ghostBoard(mv *chess.Move, opts boardOptions) (*chess.Position, boardOptions) {
+	pos := m.game.Position().Update(mv)
+	opts.turnMarker = false
+	opts.focus = map[chess.Square]bool{}
+	for sq := range pos.Board().SquareMap() {
+		if sq != mv.S2() {
+			opts.focus[sq] = true
+		}
+	}
+	return pos, opts
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// handicap is an odds position new games start from.
+type handicap struct {
+	fen   string
+	label string // what is missing, e.g. "White without the queen on d1"
+}
+
+// handicapPieces are the pieces that can be named in a handicap, with the
+// squares White gives them up from: the queenside rook, knight and bishop
+// and the f-pawn, as in the traditional odds. Black gives up the same
+// pieces from the other end of the board.
+var handicapPieces = map[string][]chess.Square{
+	"queen":   {chess.D1},
+	"rook":    {chess.A1},
+	"rooks":   {chess.A1, chess.H1},
+	"knight":  {chess.B1},
+	"knights": {chess.B1, chess.G1},
+	"bishop":  {chess.C1},
+	"bishops": {chess.C1, chess.F1},
+	"pawn":    {chess.F2},
+}
+
+var handicapNames = map[chess.PieceType]string{
+	chess.Queen:  "queen",
+	chess.Rook:   "rook",
+	chess.Bishop: "bishop",
+	chess.Knight: "knight",
+	chess.Pawn:   "pawn",
+}
+
+// parseHandicap reads a handicap of comma separated squares or pieces, such
+// as "d1", "queen", "both knights", "black rook" or "queen,f7": a piece is
+// White's unless black is said, and both takes the pair.
+func parseHandicap(s string) (handicap, error) {
+	var squares []string
+	for _, item := range strings.Split(s, ",") {
+		words := strings.Fields(strings.ToLower(item))
+		if len(words) == 1 {
+			if _, ok := parseSquare(words[0]); ok {
+				squares = append(squares, words[0])
+				continue
+			}
+		}
+		side := chess.White
+		if len(words) > 0 && (words[0] == "white" || words[0] == "black") {
+			if words[0] == "black" {
+				side = chess.Black
+			}
+			words = words[1:]
+		}
+		if len(words) == 2 && words[0] == "both" {
+			words = []string{strings.TrimSuffix(words[1], "s") + "s"}
+		}
+		var from []chess.Square
+		if len(words) == 1 {
+			from = handicapPieces[words[0]]
+		}
+		if from == nil {
+			return handicap{}, fmt.Errorf("unknown handicap %q (want a square such as d1, or a piece such as queen, both knights or black rook)", strings.TrimSpace(item))
+		}
+		for _, sq := range from {
+			if side == chess.Black {
+				sq = chess.NewSquare(sq.File(), 7-sq.Rank())
+			}
+			squares = append(squares, sq.String())
+		}
+	}
+	fen, err := handicapFEN(squares)
+	if err != nil {
+		return handicap{}, err
+	}
+	return handicap{fen: fen, label: handicapLabel(squares)}, nil
+}
+
+// handicapLabel describes the pieces removed from the starting position,
+// e.g. "White without the queen on d1 and the knight on b1".
+func handicapLabel(squares []string) string {
+	pieces := chess.StartingPosition().Board().SquareMap()
+	missing := map[chess.Color][]string{}
+	for _, s := range squares {
+		sq, _ := parseSquare(strings.TrimSpace(s))
+		p := pieces[sq]
+		missing[p.Color()] = append(missing[p.Color()], fmt.Sprintf("the %s on %s", handicapNames[p.Type()], sq))
+	}
+	var parts []string
+	for _, side := range []chess.Color{chess.White, chess.Black} {
+		if list := missing[side]; len(list) > 0 {
+			parts = append(parts, side.Name()+" without "+joinAnd(list))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// joinAnd joins a list as in "a, b and c".
+func joinAnd(list []string) string {
+	if len(list) == 1 {
+		return list[0]
+	}
+	return strings.Join(list[:len(list)-1], ", ") + " and " + list[len(list)-1]
+}
+
+// handicapFEN returns the standard starting position without the pieces
+// on the given squares, for odds games: d1 gives queen odds, b1 knight odds,
+// a1 rook odds and f7 pawn odds to White. Castling rights are dropped with
+// the rook they need.
+func handicapFEN(squares []string) (string, error) {
+	pieces := chess.StartingPosition().Board().SquareMap()
+	removed := map[chess.Square]bool{}
+	for _, s := range squares {
+		sq, ok := parseSquare(strings.TrimSpace(s))
+		if !ok {
+			return "", fmt.Errorf("invalid square %q", s)
+		}
+		p, ok := pieces[sq]
+		switch {
+		case !ok:
+			return "", fmt.Errorf("there is no piece on %s to remove", sq)
+		case p.Type() == chess.King:
+			return "", fmt.Errorf("the king on %s can't be removed", sq)
+		}
+		delete(pieces, sq)
+		removed[sq] = true
+	}
+
+	castling := ""
+	for _, r := range "KQkq" {
+		if !removed[castlingSquares[r].rook] {
+			castling += string(r)
+		}
+	}
+	if castling == "" {
+		castling = "-"
+	}
+	fen := chess.NewBoard(pieces).String() + " w " + castling + " - 0 1"
+	problems, err := fenProblems(fen)
+	if err != nil {
+		return "", err
+	}
+	if len(problems) > 0 {
+		return "", fmt.Errorf("impossible handicap position: %s", strings.Join(problems, "; "))
+	}
+	return fen, nil
+}
+
+// playingHandicap reports whether the game started from the handicap
+// position.
+func (m model) playingHandicap() bool {
+	return m.handicap.fen != "" && m.game.Positions()[0].String() == m.handicap.fen
+}
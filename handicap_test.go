@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseHandicap(t *testing.T) {
+	tests := []struct {
+		input string
+		fen   string
+		label string
+	}{
+		{"queen", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNB1KBNR w KQkq - 0 1", "White without the queen on d1"},
+		{"d1", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNB1KBNR w KQkq - 0 1", "White without the queen on d1"},
+		{"both knights", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/R1BQKB1R w KQkq - 0 1", "White without the knight on b1 and the knight on g1"},
+		{"Both Knight", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/R1BQKB1R w KQkq - 0 1", "White without the knight on b1 and the knight on g1"},
+		{"white bishop", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RN1QKBNR w KQkq - 0 1", "White without the bishop on c1"},
+		{"black rook", "1nbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQk - 0 1", "Black without the rook on a8"},
+		{"both rooks", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/1NBQKBN1 w kq - 0 1", "White without the rook on a1 and the rook on h1"},
+		{"h8, a8", "1nbqkbn1/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQ - 0 1", "Black without the rook on h8 and the rook on a8"},
+		{"queen,f7", "rnbqkbnr/ppppp1pp/8/8/8/8/PPPPPPPP/RNB1KBNR w KQkq - 0 1", "White without the queen on d1, Black without the pawn on f7"},
+		{"d1, black knight", "r1bqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNB1KBNR w KQkq - 0 1", "White without the queen on d1, Black without the knight on b8"},
+	}
+	for _, tt := range tests {
+		h, err := parseHandicap(tt.input)
+		if err != nil {
+			t.Errorf("parseHandicap(%q): %v", tt.input, err)
+			continue
+		}
+		if h.fen != tt.fen {
+			t.Errorf("parseHandicap(%q) starts from %s, want %s", tt.input, h.fen, tt.fen)
+		}
+		if h.label != tt.label {
+			t.Errorf("parseHandicap(%q) is labelled %q, want %q", tt.input, h.label, tt.label)
+		}
+	}
+}
+
+func TestParseHandicapErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{"e1", "the king on e1 can't be removed"},
+		{"queen, e8", "the king on e8 can't be removed"},
+		{"king", "unknown handicap"},
+		{"black king", "unknown handicap"},
+		{"both queens", "unknown handicap"},
+		{"e4", "no piece on e4"},
+		{"", "unknown handicap"},
+	}
+	for _, tt := range tests {
+		if _, err := parseHandicap(tt.input); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("parseHandicap(%q) error %v, want %q", tt.input, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// heatmapMode is whose control of the squares the heatmap shades.
+type heatmapMode int
+
+const (
+	heatmapOff   heatmapMode = iota
+	heatmapWhite             // the squares White attacks, darker the more attackers
+	heatmapBlack             // the same for Black
+	heatmapNet               // White's attackers less Black's, in the color of the side ahead
+)
+
+var heatmapNames = []string{"off", "white", "black", "net"}
+
+func parseHeatmapMode(s string) (heatmapMode, error) {
+	for i, name := range heatmapNames {
+		if s == name {
+			return heatmapMode(i), nil
+		}
+	}
+	return heatmapOff, fmt.Errorf("unknown heatmap %q (want white, black, net or off)", s)
+}
+
+const (
+	// heatmapStep is how far each attacker moves a square's color toward
+	// its side's tint, up to heatmapMaxAttackers of them.
+	heatmapStep         = 0.18
+	heatmapMaxAttackers = 4
+)
+
+var heatmapTints = map[chess.Color]lipgloss.Color{
+	chess.White: "#3B82C4",
+	chess.Black: "#C8553D",
+}
+
+var (
+	knightJumps = [][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	kingSteps   = [][2]int{{0, 1}, {1, 1}, {1, 0}, {1, -1}, {0, -1}, {-1, -1}, {-1, 0}, {-1, 1}}
+	rookRays    = [][2]int{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+	bishopRays  = [][2]int{{1, 1}, {1, -1}, {-1, -1}, {-1, 1}}
+)
+
+// attackCounts returns how many of the side's pieces attack each square,
+// whether the square is empty or holds a piece of either side. Pins are
+// ignored: a pinned piece still controls the squares it eyes.
+func attackCounts(board *chess.Board, side chess.Color) map[chess.Square]int {
+	counts := map[chess.Square]int{}
+	add := func(file, rank int) bool {
+		if file < 0 || file > 7 || rank < 0 || rank > 7 {
+			return false
+		}
+		sq := chess.NewSquare(chess.File(file), chess.Rank(rank))
+		counts[sq]++
+		return board.Piece(sq) == chess.NoPiece
+	}
+	for sq, piece := range board.SquareMap() {
+		if piece.Color() != side {
+			continue
+		}
+		file, rank := int(sq.File()), int(sq.Rank())
+		var steps, rays [][2]int
+		switch piece.Type() {
+		case chess.Pawn:
+			forward := 1
+			if side == chess.Black {
+				forward = -1
+			}
+			steps = [][2]int{{-1, forward}, {1, forward}}
+		case chess.Knight:
+			steps = knightJumps
+		case chess.King:
+			steps = kingSteps
+		case chess.Bishop:
+			rays = bishopRays
+		case chess.Rook:
+			rays = rookRays
+		case chess.Queen:
+			rays = append(append([][2]int{}, rookRays...), bishopRays...)
+		}
+		for _, d := range steps {
+			add(file+d[0], rank+d[1])
+		}
+		for _, d := range rays {
+			f, r := file+d[0], rank+d[1]
+			for add(f, r) {
+				f, r = f+d[0], r+d[1]
+			}
+		}
+	}
+	return counts
+}
+
+// heatmapHighlights shades the squares of the position on the board by the
+// control chosen with :heatmap.
+func (m model) heatmapHighlights() map[chess.Square]lipgloss.Style {
+	if m.heatmap == heatmapOff {
+		return nil
+	}
+	board := m.displayedPosition().Board()
+	white, black := attackCounts(board, chess.White), attackCounts(board, chess.Black)
+	hl := map[chess.Square]lipgloss.Style{}
+	for sq := chess.A1; sq <= chess.H8; sq++ {
+		side, n := chess.White, white[sq]
+		switch m.heatmap {
+		case heatmapBlack:
+			side, n = chess.Black, black[sq]
+		case heatmapNet:
+			n -= black[sq]
+			if n < 0 {
+				side, n = chess.Black, -n
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		bg, _ := m.theme.squareStyle((int(sq.File())+int(sq.Rank()))%2 == 0).GetBackground().(lipgloss.Color)
+		if _, ok := luminance(bg); !ok {
+			bg = "#808080"
+		}
+		shade := mixColor(bg, heatmapTints[side], heatmapStep*float64(min(n, heatmapMaxAttackers)))
+		hl[sq] = lipgloss.NewStyle().Background(shade)
+	}
+	return hl
+}
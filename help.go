@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var helpStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("#BC7342")).
+	Padding(1, 2)
+
+// keyHelp lists the key bindings shown in the help overlay.
+var keyHelp = [][2]string{
+	{"enter", "play the typed move (or a pasted line of moves or FEN)"},
+	{"(=) / resign", "type to offer or claim a draw / to resign"},
+	{"↑/↓ pgup/pgdn", "scroll the history"},
+	{"[ / ]", "shrink / grow the history"},
+	{"ctrl+l", "toggle the legal moves panel"},
+	{"shift+↑/↓", "scroll the legal moves"},
+	{"ctrl+z / ctrl+y", "undo / redo a move"},
+	{"ctrl+r", "save an asciicast replay"},
+	{"ctrl+x", "copy the PGN to the clipboard"},
+	{"ctrl+o", "copy the last move, e.g. 12... Nf6"},
+	{"ctrl+g", "ask the engine for a hint"},
+	{"ctrl+p", "show or hide the phase of the game (opening, middlegame, endgame)"},
+	{":", "open the command palette (tab completes)"},
+	{"ctrl+t", "open a game in a new tab (:tab close closes it)"},
+	{"ctrl+pgup/pgdn", "previous / next tab (alt+1-9 to jump)"},
+	{"f2", "play mode"},
+	{"f3 / tab", "toggle review mode (←/→ to step, home/end or g/G to the start/end, f past only moves, / to find moves and n/N to go through them, b to play on from there in a new tab, x to copy the PGN of the moves from there)"},
+	{"f4", "analysis mode (moves don't count)"},
+	{"f5", "edit the position"},
+	{"ctrl+s", "in analysis or the editor, give the move to the other side"},
+	{"f6", "toggle engine analysis of the board (starts stockfish without -engine)"},
+	{"f7", "load one of the last games saved or loaded"},
+	{"f8", "list moves in SAN, coordinates (g1f3) or descriptive notation (N-KB3, which can be typed too)"},
+	{"f9", "hide or show the history"},
+	{"f10", "toggle the analysis layout: the board as large as it fits, the engine's lines and the moves with their evaluations"},
+	{"right-click/drag", "circle a square / draw an arrow (review, analysis)"},
+	{"del", "clear the arrows and circles (review)"},
+	{"i / esc", "with -vim: type a move / back to normal mode"},
+	{"N/B/R/Q/K", "with -vim, in normal mode: pick the next move of that piece, enter plays it"},
+	{"space / s", "with -match: pause or resume the match / play one move while paused"},
+	{"?", "toggle this help"},
+	{"esc / ctrl+c", "clear the typed move, or quit (with -triple-esc, esc three times quickly resigns or quits)"},
+}
+
+func renderHelp() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Keys") + "\n\n")
+	for _, kh := range keyHelp {
+		sb.WriteString(statusMessageStyle.Width(16).Render(kh[0]) + kh[1] + "\n")
+	}
+	sb.WriteString("\nPress any key to close")
+	return helpStyle.Render(sb.String())
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// highlightKind is a kind of highlight whose style -highlight sets.
+type highlightKind int
+
+// The kinds go from the lowest precedence to the highest: where two of
+// them drawn the same way fall on one square, the later one is drawn.
+const (
+	highlightLastMove  highlightKind = iota // the last move while reviewing with focus
+	highlightCheck                          // the king in check or checkmated
+	highlightSelection                      // the move being dragged, staged or picked
+)
+
+var highlightKindNames = []string{"last", "check", "selection"}
+
+// highlightStyle is how a highlight is drawn on its squares.
+type highlightStyle int
+
+const (
+	styleFill      highlightStyle = iota // the square's background
+	styleUnderline                       // the piece underlined
+	styleBrackets                        // brackets around the piece, underlined where they don't fit
+	styleOff                             // not at all
+)
+
+var highlightStyleNames = []string{"fill", "underline", "brackets", "off"}
+
+// highlightStyles maps the kinds of highlight to their styles. Kinds left
+// out are filled.
+type highlightStyles map[highlightKind]highlightStyle
+
+// parseHighlightStyles parses a list such as "last=underline,check=fill".
+func parseHighlightStyles(s string) (highlightStyles, error) {
+	styles := highlightStyles{}
+	for _, field := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		kind := slices.Index(highlightKindNames, name)
+		if !ok || kind < 0 {
+			return nil, fmt.Errorf("unknown highlight %q (want last, check or selection=style)", field)
+		}
+		style := slices.Index(highlightStyleNames, value)
+		if style < 0 {
+			return nil, fmt.Errorf("unknown highlight style %q (want fill, underline, brackets or off)", value)
+		}
+		styles[highlightKind(kind)] = highlightStyle(style)
+	}
+	return styles, nil
+}
+
+// decoration is how a square is marked besides its background.
+type decoration struct {
+	underline bool
+	brackets  bool
+}
+
+// filled returns the squares of a highlight if it is drawn as a fill, for
+// moveHighlights to lay over the others.
+func (m model) filled(kind highlightKind, squares map[chess.Square]lipgloss.Style) map[chess.Square]lipgloss.Style {
+	if m.highlightStyles[kind] != styleFill {
+		return nil
+	}
+	return squares
+}
+
+// decorations marks the squares of the highlights drawn other than as a
+// fill. Marks don't cover each other the way fills do, so a selection in
+// brackets and the last move underlined both show on a square they share.
+func (m model) decorations() map[chess.Square]decoration {
+	layers := map[highlightKind]map[chess.Square]lipgloss.Style{
+		highlightLastMove:  m.lastMoveHighlights(),
+		highlightCheck:     m.checkHighlight(),
+		highlightSelection: m.selectionHighlights(),
+	}
+	var marks map[chess.Square]decoration
+	for kind := highlightLastMove; kind <= highlightSelection; kind++ {
+		style := m.highlightStyles[kind]
+		if style == styleFill || style == styleOff {
+			continue
+		}
+		for sq := range layers[kind] {
+			if marks == nil {
+				marks = map[chess.Square]decoration{}
+			}
+			d := marks[sq]
+			switch style {
+			case styleUnderline:
+				d.underline = true
+			case styleBrackets:
+				d.brackets = true
+			}
+			marks[sq] = d
+		}
+	}
+	return marks
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+var (
+	hintFromStyle = lipgloss.NewStyle().Background(lipgloss.Color("#5B9BD5"))
+	hintToStyle   = lipgloss.NewStyle().Background(lipgloss.Color("#8FBCE6"))
+)
+
+// hintMsg carries the move the engine suggests in a position.
+type hintMsg struct {
+	engine *uciEngine
+	fen    string
+	result searchResult
+	err    error
+}
+
+// requestHint asks the engine for the best move in the position being
+// played, to show it without playing it. With -hints only so many hints
+// may be asked for each game.
+func (m *model) requestHint() (tea.Cmd, error) {
+	if m.studying() {
+		// the study's own hint is more use than the engine's move
+		return nil, m.showStudyHint()
+	}
+	switch {
+	case m.engine == nil:
+		return nil, errors.New("hints need an engine: start gochess with -engine or press f6")
+	case m.mode != modePlay && m.mode != modeAnalysis:
+		return nil, errors.New("hints are only given in play or analysis mode")
+	case m.game.Outcome() != chess.NoOutcome:
+		return nil, errors.New("the game is over")
+	case m.hintLimit > 0 && m.hintsUsed >= m.hintLimit:
+		return nil, fmt.Errorf("no hints left, all %d have been used in this game", m.hintLimit)
+	case m.hintPending:
+		return nil, nil
+	}
+	m.stopPondering()
+	m.hintPending = true
+	m.status = "Looking for a hint…"
+	pos := m.game.Position()
+	engine, fen := m.engine, pos.String()
+	return func() tea.Msg {
+		result, err := engine.search(pos, playMovetime)
+		return hintMsg{engine: engine, fen: fen, result: result, err: err}
+	}, nil
+}
+
+// handleHint shows the suggested move, unless the game has moved on since
+// it was asked for.
+func (m *model) handleHint(msg hintMsg) tea.Cmd {
+	if msg.engine != m.engine {
+		return nil
+	}
+	m.hintPending = false
+	if msg.err != nil {
+		return m.engineFailed(msg.err)
+	}
+	pos := m.game.Position()
+	if pos.String() != msg.fen {
+		m.status = ""
+		return nil
+	}
+	for _, mv := range m.validMoves {
+		if mv.String() != msg.result.bestMove {
+			continue
+		}
+		m.hint = mv
+		m.hintsUsed++
+		m.status = "Hint: " + m.locale.translateSAN(chess.AlgebraicNotation{}.Encode(pos, mv))
+		if m.hintLimit > 0 {
+			m.status += fmt.Sprintf(" (%d of %d hints left)", m.hintLimit-m.hintsUsed, m.hintLimit)
+		}
+		return nil
+	}
+	m.status = ""
+	m.error = fmt.Errorf("the engine suggested %q, which isn't a legal move", msg.result.bestMove)
+	return nil
+}
+
+// hintHighlights marks the squares of the hinted move until a move is made.
+func (m model) hintHighlights() map[chess.Square]lipgloss.Style {
+	if m.hint == nil || m.displayedPosition() != m.game.Position() {
+		return nil
+	}
+	return map[chess.Square]lipgloss.Style{
+		m.hint.S1(): hintFromStyle,
+		m.hint.S2(): hintToStyle,
+	}
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+const (
+	historyDesiredWidth = 20
+	historyMinWidth     = 14
+	historyWidthStep    = 2
+	historyGap          = 2 // columns between the board and the history panel
+)
+
+// historyFormat controls how moves are laid out in the history panel.
+type historyFormat int
+
+const (
+	historyPairs   historyFormat = iota // "1. e4 e5", a move pair per line
+	historyPlies                        // "1. e4" and "1... e5", a ply per line
+	historyInline                       // all moves wrapped as running text
+	historyColumns                      // move pairs in as many columns as fit once they overflow the panel
+)
+
+func parseHistoryFormat(s string) (historyFormat, error) {
+	switch s {
+	case "pairs":
+		return historyPairs, nil
+	case "plies":
+		return historyPlies, nil
+	case "inline":
+		return historyInline, nil
+	case "columns":
+		return historyColumns, nil
+	default:
+		return historyPairs, fmt.Errorf("unknown history format %q (want pairs, plies, inline or columns)", s)
+	}
+}
+
+var historyStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("#BC7342"))
+
+func newHistoryViewport() viewport.Model {
+	vp := viewport.New(historyDesiredWidth, 0)
+	// Only non-printable keys scroll the history so typing moves is unaffected.
+	vp.KeyMap = viewport.KeyMap{
+		PageDown: key.NewBinding(key.WithKeys("pgdown")),
+		PageUp:   key.NewBinding(key.WithKeys("pgup")),
+		Up:       key.NewBinding(key.WithKeys("up")),
+		Down:     key.NewBinding(key.WithKeys("down")),
+	}
+	return vp
+}
+
+// maxHistoryWidth is the widest the history panel can get without pushing
+// the board out of the window.
+func (m model) maxHistoryWidth() int {
+	boardWidth, _ := m.boardSize()
+	available := m.width - docStyle.GetHorizontalFrameSize() - boardWidth - historyGap - historyStyle.GetHorizontalFrameSize() - m.legalMovesPanelWidth()
+	return max(available, historyMinWidth)
+}
+
+// resizeHistory clamps the requested history width to the window and
+// re-wraps the viewport content to match. The panel is as tall as the board.
+// The requested width is kept, so the panel widens again when the window
+// grows after shrinking.
+func (m *model) resizeHistory(width int) {
+	_, boardHeight := m.boardSize()
+	m.historyWidth = max(width, historyMinWidth)
+	m.viewport.Width = min(m.historyWidth, m.maxHistoryWidth())
+	m.viewport.Height = max(boardHeight-historyStyle.GetVerticalFrameSize(), 1)
+	m.updateHistoryViewport()
+}
+
+// historyToken is a word of the history panel. Moves carry the ply they
+// lead to so that clicking them can jump there; other words have ply 0.
+type historyToken struct {
+	text  string
+	ply   int
+	match bool            // the move matches the search of the history
+	style *lipgloss.Style // drawn in, nil for plain
+}
+
+// render draws the token's text, which may have been shortened to fit,
+// highlighted if the move matches the search.
+func (t historyToken) render(text string) string {
+	switch {
+	case t.match:
+		return historyMatchStyle.Render(text)
+	case t.style != nil:
+		return t.style.Render(text)
+	}
+	return text
+}
+
+// historySpan locates a move on a line of the history panel.
+type historySpan struct {
+	start, end int // columns
+	ply        int
+}
+
+// historyStart returns the index of the first move shown in the history
+// panel: with a limit only the last historyLimit move pairs are listed,
+// except while reviewing, when every move can be stepped to.
+func (m model) historyStart() int {
+	if m.historyLimit <= 0 || m.mode == modeReview {
+		return 0
+	}
+	// a game starting with Black to move has Black's move on its own first
+	odd := (m.startPly() + m.historyDrop) % 2
+	pairs := (len(m.history) + odd + 1) / 2
+	if pairs <= m.historyLimit {
+		return 0
+	}
+	return 2*(pairs-m.historyLimit) - odd
+}
+
+// startPly counts the plies before the position the game started from, as
+// its FEN tells, so that games set up from the middle are numbered from
+// there.
+func (m model) startPly() int {
+	return gameStartPly(m.game)
+}
+
+// gameStartPly counts the plies before the position a game started from:
+// 0 for the standard start, odd when Black moves first.
+func gameStartPly(game *chess.Game) int {
+	fields := strings.Fields(game.Positions()[0].String())
+	if len(fields) < 6 {
+		return 0
+	}
+	fullmove, err := strconv.Atoi(fields[5])
+	if err != nil || fullmove < 1 {
+		return 0
+	}
+	ply := 2 * (fullmove - 1)
+	if fields[1] == "b" {
+		ply++
+	}
+	return ply
+}
+
+// plyNumber formats the number of the move made at ply, counted from the
+// start of the game as gameStartPly does: "5." for White's move and "5..."
+// for Black's.
+func plyNumber(ply int) string {
+	if ply%2 == 1 {
+		return fmt.Sprintf("%d...", ply/2+1)
+	}
+	return fmt.Sprintf("%d.", ply/2+1)
+}
+
+// moveNumber returns the number of the move at index i of the history and
+// whether it is Black's.
+func (m model) moveNumber(i int) (int, bool) {
+	ply := m.startPly() + m.historyDrop + i
+	return ply/2 + 1, ply%2 == 1
+}
+
+// setHistory replaces the moves of the history, keeping only the latest
+// with -history-max.
+func (m *model) setHistory(history []string) {
+	m.history, m.historyDrop = history, 0
+	m.trimHistory()
+}
+
+// trimHistory bounds the moves kept for the history to the last
+// -history-max pairs, so that it doesn't grow without end in sessions
+// running for hours. The game still has every move for the rules,
+// reviewing and export. Moves are dropped once twice as many have piled up
+// so that most moves are appended without copying.
+func (m *model) trimHistory() {
+	limit := 2 * m.historyMax
+	if limit <= 0 || len(m.history) <= 2*limit {
+		return
+	}
+	// whole pairs are dropped so that White's moves stay at even indexes
+	drop := (len(m.history) - limit) &^ 1
+	m.history = append([]string(nil), m.history[drop:]...)
+	m.historyDrop += drop
+}
+
+func (m *model) updateHistoryViewport() {
+	first := m.historyStart()
+	// moves dropped with -history-max still count for numbering
+	base := m.historyDrop
+	// Each entry starts on a new line and wraps if it doesn't fit.
+	var entries [][]historyToken
+	switch m.historyFormat {
+	case historyPairs, historyColumns:
+		for i := first; i < len(m.history); {
+			number, black := m.moveNumber(i)
+			if black {
+				// Black's move without White's before it, as in 1... e5
+				entries = append(entries, append([]historyToken{{text: fmt.Sprintf("%d...", number)}}, m.moveTokens(i)...))
+				i++
+				continue
+			}
+			entry := append([]historyToken{{text: fmt.Sprintf("%d.", number)}}, m.moveTokens(i)...)
+			if i+1 < len(m.history) {
+				entry = append(entry, m.moveTokens(i+1)...)
+			}
+			entries = append(entries, entry)
+			i += 2
+		}
+	case historyPlies:
+		for i := first; i < len(m.history); i++ {
+			number, black := m.moveNumber(i)
+			text := fmt.Sprintf("%d.", number)
+			if black {
+				text = fmt.Sprintf("%d...", number)
+			}
+			entries = append(entries, append([]historyToken{{text: text}}, m.moveTokens(i)...))
+		}
+	case historyInline:
+		var entry []historyToken
+		for i := first; i < len(m.history); i++ {
+			number, black := m.moveNumber(i)
+			if !black {
+				entry = append(entry, historyToken{text: fmt.Sprintf("%d.", number)})
+			} else if i == first {
+				entry = append(entry, historyToken{text: fmt.Sprintf("%d...", number)})
+			}
+			entry = append(entry, m.moveTokens(i)...)
+		}
+		if entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+
+	matched := map[int]bool{}
+	for _, ply := range m.historyMatches() {
+		matched[ply] = true
+	}
+	for _, entry := range entries {
+		for i := range entry {
+			entry[i].match = matched[entry[i].ply]
+		}
+	}
+
+	// Wrap by hand rather than with lipgloss so that we know where each
+	// move ends up. Widths are counted in cells, and nothing is left for
+	// lipgloss to wrap, which would shift the lines under the spans.
+	width := m.viewport.Width
+	lines := []string{fitWidth("Game History:", width), ""}
+	if m.historySearch != "" {
+		lines[1] = coordStyle.Render(fitWidth("Find: "+m.historySearch, width))
+	}
+	m.historySpans = [][]historySpan{nil, nil}
+	if base+first > 0 {
+		lines = append(lines, coordStyle.Render(fitWidth(fmt.Sprintf("… %d earlier moves", (base+first)/2), width)))
+		m.historySpans = append(m.historySpans, nil)
+	}
+	var body []string
+	var spans [][]historySpan
+	ok := false
+	if m.historyFormat == historyColumns {
+		body, spans, ok = columnEntries(entries, width, m.viewport.Height-len(lines))
+	}
+	if !ok {
+		body, spans = wrapEntries(entries, width)
+	}
+	lines = append(lines, body...)
+	m.historySpans = append(m.historySpans, spans...)
+
+	// new moves scroll into view unless the user scrolled up to read
+	// earlier ones
+	follow := m.historyJump || m.viewport.AtBottom()
+	content := lipgloss.NewStyle().Width(width).Render(strings.Join(lines, "\n"))
+	m.viewport.SetContent(content)
+	if follow {
+		m.viewport.GotoBottom()
+	}
+}
+
+// wrapEntries lays out the entries one after the other, each starting on
+// a new line and wrapping onto more lines where it doesn't fit.
+func wrapEntries(entries [][]historyToken, width int) ([]string, [][]historySpan) {
+	var lines []string
+	var lineSpans [][]historySpan
+	for _, entry := range entries {
+		var line strings.Builder
+		var spans []historySpan
+		col := 0
+		for _, tok := range entry {
+			text := fitWidth(tok.text, width)
+			w := lipgloss.Width(text)
+			if col > 0 && col+1+w > width {
+				lines = append(lines, line.String())
+				lineSpans = append(lineSpans, spans)
+				line.Reset()
+				spans, col = nil, 0
+			}
+			if col > 0 {
+				line.WriteString(" ")
+				col++
+			}
+			if tok.ply > 0 {
+				spans = append(spans, historySpan{start: col, end: col + w, ply: tok.ply})
+			}
+			line.WriteString(tok.render(text))
+			col += w
+		}
+		lines = append(lines, line.String())
+		lineSpans = append(lineSpans, spans)
+	}
+	return lines, lineSpans
+}
+
+// historyColumnGap is the space between the columns of the columns layout.
+const historyColumnGap = 2
+
+// columnEntries lays out the entries in columns, filled top to bottom and
+// then left to right, using as many columns as fit in the width once the
+// entries overflow the given number of rows. It reports false when one
+// column is all there is room for, leaving the entries to wrapEntries.
+func columnEntries(entries [][]historyToken, width, rows int) ([]string, [][]historySpan, bool) {
+	texts := make([]string, len(entries))
+	entryWidth := 0
+	for i, entry := range entries {
+		words := make([]string, len(entry))
+		for j, tok := range entry {
+			words[j] = tok.render(tok.text)
+		}
+		texts[i] = strings.Join(words, " ")
+		entryWidth = max(entryWidth, lipgloss.Width(texts[i]))
+	}
+	rows = max(rows, 1)
+	fit := (width + historyColumnGap) / (entryWidth + historyColumnGap)
+	columns := min(fit, (len(entries)+rows-1)/rows)
+	if columns < 2 {
+		return nil, nil, false
+	}
+	rows = (len(entries) + columns - 1) / columns
+
+	lines := make([]string, rows)
+	lineSpans := make([][]historySpan, rows)
+	for i, entry := range entries {
+		row, start := i%rows, (i/rows)*(entryWidth+historyColumnGap)
+		line := lines[row] + strings.Repeat(" ", start-lipgloss.Width(lines[row]))
+		col := start
+		for j, tok := range entry {
+			if j > 0 {
+				col++
+			}
+			w := lipgloss.Width(tok.text)
+			if tok.ply > 0 {
+				lineSpans[row] = append(lineSpans[row], historySpan{start: col, end: col + w, ply: tok.ply})
+			}
+			col += w
+		}
+		lines[row] = line + texts[i]
+	}
+	return lines, lineSpans, true
+}
+
+// fitWidth shortens s with an ellipsis to at most width cells.
+func fitWidth(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// historyOrigin returns the screen cell of the first character of the
+// history viewport, following the layout of View.
+func (m model) historyOrigin() (x, y int) {
+	boardX, boardY := m.boardOrigin()
+	boardWidth, _ := m.boardSize()
+	x = boardX + boardWidth + m.infoPanelWidth() + historyGap + historyStyle.GetBorderLeftSize() + historyStyle.GetPaddingLeft()
+	y = boardY + historyStyle.GetBorderTopSize() + historyStyle.GetPaddingTop()
+	return x, y
+}
+
+// historyPlyAt maps a mouse position to the ply of the move drawn there.
+func (m model) historyPlyAt(x, y int) (int, bool) {
+	if m.hideHistory || m.analysisLayout {
+		return 0, false
+	}
+	originX, originY := m.historyOrigin()
+	x, y = x-originX, y-originY
+	if x < 0 || y < 0 || x >= m.viewport.Width || y >= m.viewport.Height {
+		return 0, false
+	}
+	line := y + m.viewport.YOffset
+	if line >= len(m.historySpans) {
+		return 0, false
+	}
+	for _, span := range m.historySpans[line] {
+		if x >= span.start && x < span.end {
+			return span.ply, true
+		}
+	}
+	return 0, false
+}
+
+func (m model) renderHistory() string {
+	return historyStyle.Render(m.viewport.View())
+}
+
+// historyPanelWidth is the horizontal space the history panel takes up
+// next to the board, none while it is hidden.
+func (m model) historyPanelWidth() int {
+	if m.hideHistory {
+		return 0
+	}
+	return historyGap + m.viewport.Width + historyStyle.GetHorizontalFrameSize()
+}
+
+// toggleHistory hides the history panel, leaving the board on its own, or
+// shows it again sized to the window as it is now.
+func (m *model) toggleHistory() {
+	m.hideHistory = !m.hideHistory
+	if m.hideHistory {
+		m.status = "History hidden, f9 to show it"
+		return
+	}
+	m.resizeHistory(m.historyWidth)
+	m.status = ""
+}
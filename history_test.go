@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/notnil/chess"
+)
+
+// blackToMove is a position at move 34 with Black to move.
+const blackToMove = "r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 3 34"
+
+func TestBlackToMoveNumbering(t *testing.T) {
+	game := newTestGameFrom(t, blackToMove, "Nf6", "Nc3", "Bc5")
+	if got := gameStartPly(game); got != 67 {
+		t.Errorf("gameStartPly = %d, want 67", got)
+	}
+	if got := gameStartPly(chess.NewGame()); got != 0 {
+		t.Errorf("gameStartPly of the standard start = %d, want 0", got)
+	}
+	for ply, want := range map[int]string{0: "1.", 1: "1...", 66: "34.", 67: "34...", 68: "35."} {
+		if got := plyNumber(ply); got != want {
+			t.Errorf("plyNumber(%d) = %q, want %q", ply, got, want)
+		}
+	}
+
+	u := newUIModel(t)
+	u.m.startGame(game)
+	for i, want := range []struct {
+		number int
+		black  bool
+	}{{34, true}, {35, false}, {35, true}} {
+		if number, black := u.m.moveNumber(i); number != want.number || black != want.black {
+			t.Errorf("move %d of the history numbered %d (Black %t), want %d (Black %t)", i, number, black, want.number, want.black)
+		}
+	}
+	u.wantView("34...", "35.")
+}
+
+func TestBlackToMoveExport(t *testing.T) {
+	game := newTestGameFrom(t, blackToMove, "Nf6", "Nc3", "Bc5")
+	pgn := exportPGN(game, nil, engineNotes{})
+	if !strings.Contains(pgn, "\n34... Nf6 35. Nc3 Bc5 ") {
+		t.Errorf("movetext not numbered from 34... in\n%s", pgn)
+	}
+	if strings.Contains(pgn, "1. ") {
+		t.Errorf("movetext numbered from move 1 in\n%s", pgn)
+	}
+}
+
+func TestBlackToMoveClock(t *testing.T) {
+	u := newUIModel(t)
+	// time odds, so that charging the move to White shows
+	u.m.clock = newChessClock(timeControl{initial: 5 * time.Minute}, timeControl{initial: 3 * time.Minute})
+	u.m.startGame(newTestGameFrom(t, blackToMove))
+	u.tick(time.Minute)
+	if got := u.m.clock.remaining[chess.Black]; got != 2*time.Minute {
+		t.Errorf("Black has %s left after a minute on the first move, want 2m0s", got)
+	}
+	if got := u.m.clock.remaining[chess.White]; got != 5*time.Minute {
+		t.Errorf("White has %s left before moving, want 5m0s", got)
+	}
+	u.enter("Nf6")
+	if len(u.m.clock.spent) != 1 || u.m.clock.spent[0] != time.Minute {
+		t.Errorf("time spent by move %v, want Black's first move to take 1m0s", u.m.clock.spent)
+	}
+	u.tick(30 * time.Second)
+	if got := u.m.clock.remaining[chess.White]; got != 4*time.Minute+30*time.Second {
+		t.Errorf("White has %s left after 30 seconds on its move, want 4m30s", got)
+	}
+}
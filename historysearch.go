@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// bareMove leaves the capture and check marks out of a move.
+var bareMove = strings.NewReplacer("x", "", ":", "", "+", "", "#", "")
+
+var historyMatchStyle = lipgloss.NewStyle().
+	Background(lipgloss.Color("#BC7342")).
+	Foreground(lipgloss.Color("#000000"))
+
+// historyMatches returns the plies of the moves in the history that match
+// the search, in order. A number such as 25 or 25. finds the moves of that
+// move number; anything else finds the moves that start with it, as listed
+// and leaving out captures and checks: Nf3 finds Nxf3+ too, N every knight
+// move and b4 the pawn moves to b4 but not Bb4.
+func (m model) historyMatches() []int {
+	query := m.historySearch
+	if query == "" {
+		return nil
+	}
+	base := m.historyDrop
+	var plies []int
+	if number, err := strconv.Atoi(strings.TrimRight(query, ".")); err == nil {
+		for i := range m.history {
+			if n, _ := m.moveNumber(i); n == number {
+				plies = append(plies, base+i+1)
+			}
+		}
+		return plies
+	}
+	query = bareMove.Replace(query)
+	for i := range m.history {
+		if strings.HasPrefix(bareMove.Replace(m.moveText(i)), query) {
+			plies = append(plies, base+i+1)
+		}
+	}
+	return plies
+}
+
+// findInHistory searches the history of the game for the query and reviews
+// the first matching move. An empty query ends the search.
+func (m *model) findInHistory(query string) error {
+	// the search is of the game, not of the moves tried in analysis
+	if m.stashedGame != nil {
+		m.setMode(modeReview)
+	}
+	m.historySearch = query
+	m.updateHistoryViewport()
+	if query == "" {
+		m.status = "Search of the history cleared"
+		return nil
+	}
+	matches := m.historyMatches()
+	if len(matches) == 0 {
+		return fmt.Errorf("no move in the history matches %q", query)
+	}
+	m.setMode(modeReview)
+	m.showHistoryMatch(matches, 0)
+	return nil
+}
+
+// nextHistoryMatch reviews the next matching move after the one shown, or
+// the one before with step -1, going round at the ends.
+func (m *model) nextHistoryMatch(step int) error {
+	matches := m.historyMatches()
+	if len(matches) == 0 {
+		return errors.New("no search of the history, :find <move> to search")
+	}
+	i := 0
+	if step > 0 {
+		for i < len(matches) && matches[i] <= m.viewPly {
+			i++
+		}
+		i %= len(matches)
+	} else {
+		i = len(matches) - 1
+		for i >= 0 && matches[i] >= m.viewPly {
+			i--
+		}
+		i = (i + len(matches)) % len(matches)
+	}
+	m.showHistoryMatch(matches, i)
+	return nil
+}
+
+// showHistoryMatch reviews the i-th matching move and scrolls the history
+// to it.
+func (m *model) showHistoryMatch(matches []int, i int) {
+	ply := matches[i]
+	m.viewPly = ply
+	for line, spans := range m.historySpans {
+		for _, span := range spans {
+			if span.ply == ply {
+				m.viewport.SetYOffset(max(line-m.viewport.Height/2, 0))
+			}
+		}
+	}
+	m.status = fmt.Sprintf("%s: match %d of %d, n/N for the next/previous", m.historySearch, i+1, len(matches))
+}
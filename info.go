@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// infoWidth is the width of the info block of a side beside the board.
+const infoWidth = 16
+
+var infoNameStyle = lipgloss.NewStyle().Bold(true)
+
+// showInfo reports whether the sides' clocks and captures are drawn in info
+// blocks on either side of the board. They are when there is a clock or
+// captured pieces to show and the window is wide enough for both blocks
+// next to the board and its panels, outside the analysis layout; otherwise
+// they collapse back to the line under the board and the clocks below it.
+func (m model) showInfo() bool {
+	if m.analysisLayout || m.clock == nil && (m.captureOrder == capturesOff || m.materialView == materialBar) {
+		return false
+	}
+	boardWidth, _ := m.boardSize()
+	needed := boardWidth + 2*(historyGap+infoWidth) + m.historyPanelWidth() + m.legalMovesPanelWidth()
+	return m.width-docStyle.GetHorizontalFrameSize() >= needed
+}
+
+// infoPanelWidth is the width each info block takes up beside the board,
+// 0 when they are collapsed.
+func (m model) infoPanelWidth() int {
+	if !m.showInfo() {
+		return 0
+	}
+	return historyGap + infoWidth
+}
+
+// playerName names whoever plays side, if anyone is known: an engine of a
+// match, the player of a loaded game or the engine the user plays against.
+func (m model) playerName(side chess.Color) string {
+	if e := m.match; e != nil {
+		i := e.whiteEngine()
+		if side == chess.Black {
+			i = 1 - i
+		}
+		return e.names[i]
+	}
+	if tag := m.game.GetTagPair(side.Name()); tag != nil && tag.Value != "" && tag.Value != "?" {
+		return tag.Value
+	}
+	if m.engine != nil && side == m.engineColor {
+		return "Engine"
+	}
+	return ""
+}
+
+// shownCaptures returns the pieces taken up to the position on the board.
+func (m model) shownCaptures() map[chess.Color][]chess.Piece {
+	ply := len(m.game.Moves())
+	if m.mode == modeReview {
+		ply = m.viewPly
+	}
+	return capturedPieces(m.game, ply)
+}
+
+// renderInfo draws the info block of side: its name and player, its clock,
+// the pieces it has taken and its material advantage, aligned toward the
+// board.
+func (m model) renderInfo(side chess.Color, captured map[chess.Color][]chess.Piece, align lipgloss.Position) string {
+	lines := []string{infoNameStyle.Render(side.Name())}
+	if name := m.playerName(side); name != "" {
+		lines = append(lines, coordStyle.Render(fitWidth(name, infoWidth)))
+	}
+	if m.clock != nil {
+		style := clockStyle
+		if side == m.game.Position().Turn() && m.game.Outcome() == chess.NoOutcome {
+			style = activeClockStyle
+		}
+		lines = append(lines, style.Render(formatClock(m.clock.remaining[side])))
+	}
+	if m.captureOrder != capturesOff && m.materialView == materialPieces {
+		if groups := m.capturedGroups(captured[side]); len(groups) > 0 {
+			lines = append(lines, strings.Join(groups, " "))
+		}
+	}
+	net := materialValue(captured[side]) - materialValue(captured[side.Other()])
+	if net > 0 {
+		lines = append(lines, coordStyle.Render(fmt.Sprintf("+%d", net)))
+	}
+	block := lipgloss.JoinVertical(align, lines...)
+	return lipgloss.NewStyle().Width(infoWidth).Align(align).Render(block)
+}
+
+// renderInfoSides puts the info blocks on either side of the board, which
+// is height rows tall without the lines under it: the side at the bottom of
+// the board on the left, level with its pieces, and the side at the top on
+// the right.
+func (m model) renderInfoSides(board string, height int) string {
+	bottom := chess.White
+	if m.boardFlipped() {
+		bottom = chess.Black
+	}
+	captured := m.shownCaptures()
+	left := lipgloss.PlaceVertical(height, lipgloss.Bottom, m.renderInfo(bottom, captured, lipgloss.Right))
+	right := lipgloss.PlaceVertical(height, lipgloss.Top, m.renderInfo(bottom.Other(), captured, lipgloss.Left))
+	gap := strings.Repeat(" ", historyGap)
+	return lipgloss.JoinHorizontal(lipgloss.Top, left, gap, board, gap, right)
+}
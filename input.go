@@ -0,0 +1,177 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// inputContext is what the input line is being typed into, which sets how
+// much it takes, how wide it is drawn and what enter does with it.
+type inputContext int
+
+const (
+	inputMove    inputContext = iota // a move, or a pasted line of moves or FEN
+	inputEdit                        // a piece to place in the editor, or a pasted FEN
+	inputCommand                     // a command of the palette
+	inputTrainer                     // a square named in the trainer
+)
+
+// inputSize is how many characters an input context takes, 0 for no
+// limit, and how wide its input is drawn at the least.
+type inputSize struct {
+	charLimit int
+	width     int
+}
+
+var inputSizes = map[inputContext]inputSize{
+	inputMove:    {moveCharLimit, 16},
+	inputEdit:    {editCharLimit, 16},
+	inputCommand: {0, commandInputWidth},
+	inputTrainer: {2, 16},
+}
+
+// pasteCharLimit bounds what can be pasted into the move and edit inputs,
+// plenty for a FEN or a long line of moves.
+const pasteCharLimit = 1024
+
+func (m model) inputContext() inputContext {
+	switch {
+	case m.commandMode:
+		return inputCommand
+	case m.trainer != nil:
+		return inputTrainer
+	case m.mode == modeEdit:
+		return inputEdit
+	}
+	return inputMove
+}
+
+// fitInput sizes the input for its context. A paste into the move or edit
+// input lifts the char limit so that a line of moves or a FEN fits, until
+// the input is short again.
+func (m *model) fitInput(paste bool) {
+	ctx := m.inputContext()
+	limit := inputSizes[ctx].charLimit
+	if (ctx == inputMove || ctx == inputEdit) && (paste || len(m.textInput.Value()) > limit) {
+		limit = pasteCharLimit
+	}
+	m.textInput.CharLimit = limit
+	// the input scrolls once it is as wide as it is drawn
+	m.textInput.Width = max(m.inputWidth()-lipgloss.Width(m.textInput.Prompt)-1, 0)
+}
+
+// inputWidth is how wide the input is drawn: the width of its context,
+// growing with what was pasted up to the width of the window.
+func (m model) inputWidth() int {
+	width := inputSizes[m.inputContext()].width
+	typed := lipgloss.Width(m.textInput.Prompt) + len([]rune(m.textInput.Value())) + 1
+	return max(width, min(typed, m.width-docStyle.GetHorizontalFrameSize()))
+}
+
+// submitInput handles enter in the move or edit input according to its
+// context.
+func (m *model) submitInput() tea.Cmd {
+	input := m.textInput.Value()
+	if m.inputContext() == inputEdit {
+		cmd, err := m.applyEdit(input)
+		m.error = err
+		m.textInput.Reset()
+		return cmd
+	}
+	if cmd, answered := m.answerAmbiguity(input); answered {
+		return cmd
+	}
+	if answered, err := m.answerResign(input); answered {
+		m.error = err
+		m.textInput.Reset()
+		return nil
+	}
+	if answered, err := m.answerDrawOffer(input); answered {
+		m.error = err
+		m.textInput.Reset()
+		return nil
+	}
+	if handled, err := m.inlineCommand(input); handled {
+		m.error = err
+		m.textInput.Reset()
+		return nil
+	}
+	if isFEN(input) {
+		cmd, err := m.runCommand("fen " + input)
+		m.error = err
+		if err == nil {
+			m.textInput.Reset()
+		}
+		return cmd
+	}
+	if len(movetextTokens(input)) > 1 {
+		cmd, err := m.applyMovetext(input)
+		m.error = err
+		if err == nil {
+			m.textInput.Reset()
+		}
+		return cmd
+	}
+	now := time.Now()
+	if m.repeatGuard.repeated(input, now) {
+		m.textInput.Reset()
+		m.status = strings.TrimSpace(input) + " was entered twice, the second time is ignored"
+		return nil
+	}
+	mv, err := m.locale.decodeMove(m.game.Position(), input)
+	if err != nil && m.notation == notationDescriptive {
+		// with the history in descriptive notation, moves can be typed in it
+		if descriptive, derr := decodeDescriptive(m.game.Position(), input); derr == nil {
+			mv, err = descriptive, nil
+		} else if descriptiveInput.MatchString(input) {
+			err = derr
+		}
+	}
+	if err != nil {
+		// Nd2 with two knights able to go there is asked about
+		if m.ambiguous = m.locale.ambiguousMoves(m.game.Position(), m.validMoves, input); m.ambiguous != nil {
+			m.textInput.Reset()
+			m.error = nil
+			return nil
+		}
+		m.error = err
+		return nil
+	}
+	played := len(m.game.Moves())
+	cmd := m.submitMove(mv)
+	if len(m.game.Moves()) > played {
+		m.repeatGuard.record(input, now)
+	}
+	return cmd
+}
+
+// repeatGuard ignores a move entered again right after the same move was
+// played, as happens when enter is held down or a move is pasted twice.
+// Only the time between the two counts, so the move can be played again
+// any time later.
+type repeatGuard struct {
+	window time.Duration // 0 turns the guard off
+	last   string
+	at     time.Time
+}
+
+// repeated reports whether input is the move last played, entered again
+// within the window.
+func (g repeatGuard) repeated(input string, now time.Time) bool {
+	return g.window > 0 && g.last != "" && strings.TrimSpace(input) == g.last && now.Sub(g.at) < g.window
+}
+
+// record notes the move played from input.
+func (g *repeatGuard) record(input string, now time.Time) {
+	g.last, g.at = strings.TrimSpace(input), now
+}
+
+// isFEN reports whether the input looks like a FEN rather than moves: its
+// first field has the eight ranks of a board.
+func isFEN(input string) bool {
+	fields := strings.Fields(input)
+	return len(fields) > 0 && strings.Count(fields[0], "/") == 7
+}
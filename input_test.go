@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRepeatGuard(t *testing.T) {
+	played := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		window time.Duration
+		input  string
+		after  time.Duration
+		want   bool
+	}{
+		{"inside the window", 300 * time.Millisecond, "e4", 100 * time.Millisecond, true},
+		{"with spaces around", 300 * time.Millisecond, " e4 ", 100 * time.Millisecond, true},
+		{"at the end of the window", 300 * time.Millisecond, "e4", 300 * time.Millisecond, false},
+		{"outside the window", 300 * time.Millisecond, "e4", time.Second, false},
+		{"a different move", 300 * time.Millisecond, "d4", 100 * time.Millisecond, false},
+		{"window 0", 0, "e4", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := repeatGuard{window: tt.window}
+			g.record("e4", played)
+			if got := g.repeated(tt.input, played.Add(tt.after)); got != tt.want {
+				t.Errorf("repeated(%q) %s after e4 = %t, want %t", tt.input, tt.after, got, tt.want)
+			}
+		})
+	}
+	if (repeatGuard{window: time.Second}).repeated("e4", played) {
+		t.Error("a move is repeated before any was played")
+	}
+}
+
+func TestRepeatGuardIgnoresSecondEntry(t *testing.T) {
+	u := newUIModel(t)
+	u.m.repeatGuard.window = time.Minute
+	u.enter("e4")
+	u.enter("e4")
+	if got := moveList(u.m.game); got != "e4" {
+		t.Errorf("moves %q, want e4 alone", got)
+	}
+	if !strings.Contains(u.m.status, "entered twice") {
+		t.Errorf("status %q doesn't say the move was entered twice", u.m.status)
+	}
+	if u.m.error != nil {
+		t.Errorf("error %q for the ignored move", u.m.error)
+	}
+}
+
+func TestRepeatGuardSkipsMovesNotPlayed(t *testing.T) {
+	u := newUIModel(t)
+	u.m.repeatGuard.window = time.Minute
+	u.enter("e5")
+	if u.m.repeatGuard.last != "" {
+		t.Errorf("rejected move %q recorded", u.m.repeatGuard.last)
+	}
+	u.send(tea.KeyMsg{Type: tea.KeyCtrlU})
+
+	// a staged move isn't played until it is confirmed
+	u.m.confirmMoves = true
+	u.enter("e4")
+	if u.m.stagedMove == nil {
+		t.Fatal("e4 not staged")
+	}
+	if u.m.repeatGuard.last != "" {
+		t.Errorf("staged move %q recorded", u.m.repeatGuard.last)
+	}
+}
+
+// pasteLine pastes text into the move input and enters it.
+func (u *uiModel) pasteLine(text string) {
+	u.t.Helper()
+	u.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text), Paste: true})
+	u.send(tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func TestPastedLineFollowsApply(t *testing.T) {
+	u := newUIModel(t)
+	u.pasteLine("1. e4 e5 2. Ke3 Nc6")
+	if got := moveList(u.m.game); got != "" {
+		t.Errorf("moves = %q, want none played because of the illegal Ke3", got)
+	}
+	if u.m.error == nil || !strings.Contains(u.m.error.Error(), "move 3 (Ke3)") {
+		t.Errorf("error %v doesn't point at move 3", u.m.error)
+	}
+
+	u = newUIModel(t)
+	u.pasteLine("1. e4 e5 2. Nf3")
+	if got := moveList(u.m.game); got != "e4 e5 Nf3" {
+		t.Errorf("moves = %q, want e4 e5 Nf3", got)
+	}
+	u.wantView("Played 3 moves")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+const legalMovesWidth = 22
+
+// legalMoveGroups is the order in which piece groups are listed.
+var legalMoveGroups = []struct {
+	piece chess.PieceType
+	name  string
+}{
+	{chess.King, "King"},
+	{chess.Queen, "Queen"},
+	{chess.Rook, "Rooks"},
+	{chess.Bishop, "Bishops"},
+	{chess.Knight, "Knights"},
+	{chess.Pawn, "Pawns"},
+}
+
+func newLegalMovesViewport() viewport.Model {
+	vp := viewport.New(legalMovesWidth, 0)
+	vp.MouseWheelEnabled = false
+	vp.KeyMap = viewport.KeyMap{
+		Up:   key.NewBinding(key.WithKeys("shift+up")),
+		Down: key.NewBinding(key.WithKeys("shift+down")),
+	}
+	return vp
+}
+
+// legalMovesPanelWidth is the horizontal space the legal moves panel takes up.
+func (m model) legalMovesPanelWidth() int {
+	if !m.showLegalMoves {
+		return 0
+	}
+	return historyGap + legalMovesWidth + historyStyle.GetHorizontalFrameSize()
+}
+
+func (m *model) updateLegalMovesViewport() {
+	pos := m.game.Position()
+	byPiece := map[chess.PieceType][]string{}
+	for _, mv := range m.validMoves {
+		piece := pos.Board().Piece(mv.S1()).Type()
+		byPiece[piece] = append(byPiece[piece], m.locale.translateSAN(chess.AlgebraicNotation{}.Encode(pos, mv)))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Legal Moves:\n")
+	for _, group := range legalMoveGroups {
+		moves := byPiece[group.piece]
+		if len(moves) == 0 {
+			continue
+		}
+		sb.WriteString("\n" + group.name + ":\n")
+		sb.WriteString(strings.Join(moves, " ") + "\n")
+	}
+
+	m.legalViewport.Height = m.viewport.Height
+	m.legalViewport.SetContent(lipgloss.NewStyle().Width(legalMovesWidth).Render(strings.TrimSuffix(sb.String(), "\n")))
+}
+
+func (m model) renderLegalMoves() string {
+	return historyStyle.Render(m.legalViewport.View())
+}
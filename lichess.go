@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/notnil/chess"
+)
+
+const lichessAnalysisURL = "https://lichess.org/analysis/"
+
+// lichessURL links to the game on the lichess analysis board. Games from the
+// standard starting position are passed as a move list; other games can only
+// be passed as the current position.
+func lichessURL(game *chess.Game) string {
+	if game.Positions()[0].String() != chess.StartingPosition().String() {
+		return lichessAnalysisURL + strings.ReplaceAll(game.Position().String(), " ", "_")
+	}
+	history := sanHistory(game)
+	for i := range history {
+		history[i] = url.PathEscape(history[i])
+	}
+	return lichessAnalysisURL + "pgn/" + strings.Join(history, "_")
+}
+
+// openURL opens the URL in the default browser.
+func openURL(u string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u)
+	default:
+		path, err := exec.LookPath("xdg-open")
+		if err != nil {
+			return errors.New("no browser opener found")
+		}
+		cmd = exec.Command(path, u)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// exportToLichess opens the game on lichess, falling back to copying the
+// link, and reports what happened in the status line.
+func (m *model) exportToLichess() {
+	u := lichessURL(m.game)
+	switch {
+	case openURL(u) == nil:
+		m.status = "Opened the game on lichess"
+	case clipboard.WriteAll(u) == nil:
+		m.status = "Copied " + u
+	default:
+		m.status = u
+	}
+}
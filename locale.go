@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// pieceLocale holds the piece letters of a language. The zero value is
+// English.
+type pieceLocale struct {
+	letters map[chess.PieceType]string
+}
+
+var locales = map[string]pieceLocale{
+	"en": {},
+	"de": {letters: map[chess.PieceType]string{chess.King: "K", chess.Queen: "D", chess.Rook: "T", chess.Bishop: "L", chess.Knight: "S", chess.Pawn: "B"}},
+	"fr": {letters: map[chess.PieceType]string{chess.King: "R", chess.Queen: "D", chess.Rook: "T", chess.Bishop: "F", chess.Knight: "C", chess.Pawn: "P"}},
+	"es": {letters: map[chess.PieceType]string{chess.King: "R", chess.Queen: "D", chess.Rook: "T", chess.Bishop: "A", chess.Knight: "C", chess.Pawn: "P"}},
+	"nl": {letters: map[chess.PieceType]string{chess.King: "K", chess.Queen: "D", chess.Rook: "T", chess.Bishop: "L", chess.Knight: "P", chess.Pawn: "O"}},
+}
+
+// sanPieces are the piece types named by a letter in SAN.
+var sanPieces = []chess.PieceType{chess.King, chess.Queen, chess.Rook, chess.Bishop, chess.Knight}
+
+func parseLocale(s string) (pieceLocale, error) {
+	l, ok := locales[s]
+	if !ok {
+		names := make([]string, 0, len(locales))
+		for name := range locales {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return pieceLocale{}, fmt.Errorf("unknown locale %q (want one of %s)", s, strings.Join(names, ", "))
+	}
+	return l, nil
+}
+
+// letter returns the letter of the piece type in the locale.
+func (l pieceLocale) letter(t chess.PieceType) string {
+	if l.letters == nil {
+		return pieceNotation[chess.NewPiece(t, chess.White)]
+	}
+	return l.letters[t]
+}
+
+// translateSAN rewrites the piece letters of an English SAN move. Files are
+// lowercase and castling uses O, so any other capital is a piece letter.
+func (l pieceLocale) translateSAN(san string) string {
+	if l.letters == nil {
+		return san
+	}
+	var sb strings.Builder
+	for _, r := range san {
+		sb.WriteString(l.mapLetter(string(r), pieceLocale{}))
+	}
+	return sb.String()
+}
+
+// parseSAN rewrites a move typed with the locale's piece letters into the
+// English SAN expected by the chess package.
+func (l pieceLocale) parseSAN(input string) string {
+	if l.letters == nil {
+		return input
+	}
+	var sb strings.Builder
+	for _, r := range input {
+		sb.WriteString(pieceLocale{}.mapLetter(string(r), l))
+	}
+	return sb.String()
+}
+
+// mapLetter maps a piece letter of the from locale to the letter of the same
+// piece in l. Other characters are kept.
+func (l pieceLocale) mapLetter(s string, from pieceLocale) string {
+	for _, t := range sanPieces {
+		if from.letter(t) == s {
+			return l.letter(t)
+		}
+	}
+	return s
+}
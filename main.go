@@ -1,10 +1,17 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/notnil/chess"
@@ -26,12 +33,10 @@ var (
 
 	lightSquare = lipgloss.NewStyle().
 			Background(lipgloss.Color("#DEBA90")).
-			Width(3).
 			Align(lipgloss.Center)
 
 	darkSquare = lipgloss.NewStyle().
 			Background(lipgloss.Color("#BC7342")).
-			Width(3).
 			Align(lipgloss.Center)
 
 	whitePiece = lipgloss.NewStyle().
@@ -40,6 +45,13 @@ var (
 	blackPiece = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#000000"))
 
+	coordStyle = lipgloss.NewStyle().
+			Faint(true)
+
+	enPassantStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#5A3A22")).
+			Italic(true)
+
 	// Piece notation (all uppercase)
 	pieceNotation = map[chess.Piece]string{
 		chess.WhiteKing:   "K",
@@ -65,54 +77,956 @@ var (
 			Foreground(lipgloss.Color("#000000"))
 )
 
+const (
+	movePrompt = "Enter move: "
+	// moveCharLimit fits promotions such as exd8=Q+ and the resign command
+	moveCharLimit = 7
+)
+
+// config holds the options parsed from the command line.
+type config struct {
+	hotSeat         bool      // orient the board toward the side to move
+	inputMask       inputMask // how moves are shown while typed
+	escGesture      escGesture
+	compat          compatMode
+	noColor         bool         // the terminal shows no colors
+	notation        moveNotation // of the history, as last chosen with f8
+	showCoords      bool         // label empty squares with their coordinates
+	focus           bool         // dim the pieces that didn't move last while reviewing
+	captured        captureOrder
+	material        materialView
+	highlights      highlightStyles
+	labels          labelPlacement
+	border          boardBorder
+	turnFormat      turnFormat
+	scale           boardScale
+	history         historyFormat
+	historyLimit    int // 0 lists every move
+	historyMax      int // 0 keeps every move
+	historyJump     bool
+	hintLimit       int           // hints allowed per game, 0 for any number
+	resignBelow     int           // centipawns below which the engine resigns, 0 for never
+	resignMoves     int           // moves the engine has to be below resignBelow for
+	repeatWindow    time.Duration // a move entered again within this long is ignored
+	multiPV         int           // candidate moves listed by analysis
+	candidates      bool
+	syzygy          string // Syzygy tablebase directories for the engine
+	locale          pieceLocale
+	theme           theme
+	timeControl     timeControl
+	blackTime       timeControl // Black's time control with time odds, zero when it is White's
+	handicap        handicap    // the odds position games start from, if any
+	play960         bool
+	chess960        int                   // Scharnagl number of the starting position with play960
+	sounds          map[soundEvent]string // nil when sound is disabled
+	errorTimeout    time.Duration         // how long errors stay on screen, 0 for until the next move
+	notice          string                // shown in the status line on startup
+	autosaveEvery   time.Duration         // 0 disables timed autosaves
+	autosaveMoves   int                   // 0 disables autosaving after moves
+	coach           bool                  // ask before moves that hang a queen or rook
+	clkComments     bool                  // write the clock times into exported PGN
+	evalDeltas      bool                  // annotate the history with the change in evaluation
+	animateCaptures bool                  // flash the square of a captured piece
+	overwrite       overwritePolicy       // what :save does when the file exists
+	confirmMoves    bool                  // preview moves and play them on a second enter
+	vim             bool                  // start in normal mode with the input unfocused
+	speak           bool                  // describe each move in words for screen readers
+	speakLog        string                // file the move descriptions are appended to
+	drill           *repertoire           // loaded with -drill
+	demo            bool
+	seed            uint64        // seeds the random choices, 0 for one from the time
+	screensaver     time.Duration // idle time before the screensaver, 0 for none
+	match           *engineMatch  // started with -match
+	game            *chess.Game   // loaded with -pgn, nil for a new game
+	engine          *uciEngine    // nil without -engine
+	ponder          bool          // the engine thinks on the user's time
+	side            chess.Color   // the side the user plays, if chosen
+	orientation     chess.Color   // the side at the bottom, if chosen
+}
+
+// flipped reports whether the board starts out drawn from Black's side.
+func (cfg config) flipped() bool {
+	if cfg.orientation != chess.NoColor {
+		return cfg.orientation == chess.Black
+	}
+	return cfg.side == chess.Black
+}
+
+// boardOptions controls how renderBoard draws a position.
+type boardOptions struct {
+	flipped    bool
+	showCoords bool
+	labels     labelPlacement
+	scale      boardScale
+	theme      theme // colors and piece set
+	noColor    bool  // no ANSI colors: black pieces are drawn in lowercase
+	locale     pieceLocale
+	// highlights replaces the background of individual squares
+	highlights map[chess.Square]lipgloss.Style
+	// decorations underline or bracket the pieces of individual squares
+	decorations map[chess.Square]decoration
+	// focus, when set, holds the squares whose pieces are drawn normally;
+	// the others are drawn faint
+	focus      map[chess.Square]bool
+	annotation annotation // arrows and circles drawn on the position
+	// turnMarker marks the rank label on the edge of the side to move
+	turnMarker bool
+}
+
 type model struct {
-	game      *chess.Game
-	error     error
-	width     int
-	height    int
-	textInput textinput.Model
+	*session            // the game in the active tab
+	sessions []*session // the games open in tabs
+	active   int        // index of the active tab
+
+	error           error
+	width           int
+	height          int
+	textInput       textinput.Model
+	status          string
+	hotSeat         bool
+	inputMask       inputMask
+	noColor         bool
+	showCoords      bool
+	focus           bool
+	labels          labelPlacement
+	border          boardBorder
+	turnFormat      turnFormat
+	scale           boardScale
+	historyWidth    int // as set with [ and ], kept while the window is too small to draw it
+	historyFormat   historyFormat
+	notation        moveNotation // the history lists moves as Nf3, g1f3 or N-KB3
+	historyLimit    int          // move pairs listed in the history, 0 for all
+	historyMax      int          // move pairs kept for the history, 0 for all
+	historyJump     bool         // scroll to the latest move even when scrolled up
+	hideHistory     bool         // the board is shown without the history, toggled with f9
+	analysisLayout  bool         // the board is laid out for analysis, toggled with f10
+	locale          pieceLocale
+	theme           theme
+	showHelp        bool
+	overlay         *textOverlay
+	paste           *pasteModal // PGN being pasted with :paste
+	recent          *recentMenu // games to load, opened with f7
+	themes          *themeSelector
+	commandMode     bool
+	heatmap         heatmapMode
+	highlightStyles highlightStyles
+	escGesture      escGesture
+	escs            escSequence  // Esc presses toward the gesture
+	captureOrder    captureOrder // of the pieces listed under the board, off to hide them
+	materialView    materialView // the captured pieces or a bar of the balance
+	showPhase       bool         // the phase of the game is shown, toggled with ctrl+p
+	evalDeltas      bool         // the history shows how much each move changed the evaluation
+
+	showLegalMoves bool
+	legalViewport  viewport.Model
+
+	dragging   bool
+	dragFrom   chess.Square
+	dragTarget chess.Square
+
+	// hovered is the square under the mouse, shown on the status line
+	hovered  chess.Square
+	hovering bool
+
+	trainer *trainer // the square naming game, when open
+
+	sounds map[soundEvent]string
+
+	engine         *uciEngine
+	engineThinking bool
+	engineProgress *searchProgress        // reported by the engine thinking about its move
+	stoppedEngine  *uciEngine             // the engine after it stopped, to restart it
+	evals          map[string]engineScore // by FEN
+	bestMoves      map[string]string      // found by analysis, in UCI notation, by FEN
+	evalPending    bool
+
+	// with -ponder the engine thinks about the reply it expects, ponderMove
+	// in the position ponderFEN, while the user is to play it
+	ponder     bool
+	ponderFEN  string
+	ponderMove string
+	pondering  *ponderSearch
+
+	// analysing shows the engine's verdict on the position on the board
+	analysing       bool
+	analysisEngine  bool // the engine was started for analysis only
+	analysisPending bool
+	analysisFEN     string // the position analysis is about
+	analysis        searchResult
+
+	flipFrame int // frame of the flip animation, 0 when not animating
+	flipSeq   int
+
+	animateCaptures bool // flash captures, with -animate-captures
+	capture         captureFlash
+
+	errorTimeout time.Duration
+	errorSeq     int // counts errors so that only the latest one is cleared
+
+	autosaveEvery      time.Duration
+	autosaveMoves      int
+	movesSinceAutosave int
+	clkComments        bool // [%clk] comments in exported PGN, with -clk
+
+	coach        bool
+	pendingMove  *chess.Move // a move the coach wants confirmed
+	overwrite    overwritePolicy
+	pendingSave  string // a file :save asks to overwrite
+	coachWarning string
+
+	confirmMoves bool
+	stagedMove   *chess.Move // a move previewed on the board until confirmed
+
+	hintLimit   int         // hints allowed per game, 0 for any number
+	resignBelow int         // the engine resigns when it is this many centipawns down…
+	resignMoves int         // …for this many of its moves in a row
+	repeatGuard repeatGuard // against the same move entered twice in a row
+	hintPending bool
+
+	handicap handicap // the odds position new games start from, if any
+
+	syzygy       string // Syzygy tablebase directories, given to engines started
+	syzygyPieces int    // pieces the tables cover, 0 without tables
+
+	multiPV          int  // candidate moves listed by analysis
+	candidateSquares bool // highlight the candidates on the board
+
+	vim        bool // -vim: keys are commands unless in insert mode
+	normalMode bool
+	cycledMove *chess.Move // picked with a piece letter in normal mode
+
+	ambiguous []*chess.Move // the moves a typed move may be, until one is picked
+
+	speak    bool   // describe each move in words
+	speakLog string // file the descriptions are appended to
+
+	snapshot  *chess.Board // board saved to compare positions against
+	comparing bool         // highlight the differences from the snapshot
+
+	drill *repertoire // the opening lines being drilled, nil when not drilling
+	study *studySet   // the positions loaded with :study
+
+	endgamesConverted []bool // by endgame preset, whether it was converted
+
+	demo    bool         // games play themselves until a key is pressed
+	demoDue bool         // the demo tick has come for the next random move
+	rng     *rand.Rand   // makes the random choices: demo moves, trainer squares
+	match   *engineMatch // the engines playing each other with -match
+
+	screensaverAfter time.Duration // without input for this long, famous games are played
+	lastInput        time.Time
+	screensaver      *screensaver // the famous game shown, nil when the user's game is
+	screensaverNext  int          // the famous game shown next
+	screensaverSeq   int
+
+	annotating   bool // a right-button drag is drawing an arrow
+	annotateFrom chess.Square
+}
+
+// session is a game open in a tab, with the state that belongs to it.
+type session struct {
+	game          *chess.Game
+	history       []string // SAN of the moves, only the latest with -history-max
+	historyDrop   int      // moves left out at the start of history
+	viewport      viewport.Model
+	historySpans  [][]historySpan // the moves on each line of the history viewport
+	historySearch string          // the moves to find in the history, set with :find
+	clock         *chessClock
+	mode          mode
+	viewPly       int // ply shown on the board while reviewing
+
+	// Each game has its own opponent and orientation, so that branching or
+	// a rematch in one tab leaves the others as they were.
+	engineColor chess.Color // the side the engine plays, NoColor when it only evaluates
+	flipped     bool
+
+	// The game set aside while analysing or editing a copy of it.
+	stashedGame    *chess.Game
+	stashedHistory []string
+	stashedDrop    int
+	editBoard      map[chess.Square]chess.Piece
+	editTurn       chess.Color
+
+	drawOffer     chess.Color // the side whose draw offer awaits an answer
+	confirmResign bool        // resign was typed and awaits a y/n answer
+
+	redo []*chess.Move // undone moves, the next one to redo last
+
+	chess960 int  // Scharnagl number of the starting position, -1 for standard chess
+	endgame  int  // index of the endgame preset being practised, -1 when not
+	studied  bool // the game is the current position of the study
+
+	// The game a branch was taken from with b while reviewing, and the ply
+	// it was taken at.
+	branchOf  *chess.Game
+	branchPly int
+
+	gameOver *gameOverMenu // shown when the game being played ends
+
+	annotations map[string]annotation // arrows and circles, by FEN
+
+	hint      *chess.Move // the engine's suggestion, shown until a move is made
+	hintsUsed int
+
+	// validMoves caches the legal moves of the live position. It must be
+	// refreshed whenever the game's position changes.
+	validMoves []*chess.Move
 }
 
-func initialModel() model {
+func initialModel(cfg config) model {
 	ti := textinput.New()
-	ti.Prompt = "Enter move: "
-	ti.CharLimit = 4
+	ti.Prompt = movePrompt
+	ti.CharLimit = moveCharLimit
+	ti.EchoMode = cfg.inputMask.echoMode()
+	ti.EchoCharacter = '•'
 	ti.Focus()
-	return model{
-		game:      chess.NewGame(),
-		textInput: ti,
+	m := model{
+		session:           newSession(),
+		textInput:         ti,
+		hotSeat:           cfg.hotSeat,
+		inputMask:         cfg.inputMask,
+		escGesture:        cfg.escGesture,
+		noColor:           cfg.noColor,
+		notation:          cfg.notation,
+		showCoords:        cfg.showCoords,
+		focus:             cfg.focus,
+		captureOrder:      cfg.captured,
+		materialView:      cfg.material,
+		highlightStyles:   cfg.highlights,
+		labels:            cfg.labels,
+		border:            cfg.border,
+		turnFormat:        cfg.turnFormat,
+		scale:             cfg.scale,
+		historyFormat:     cfg.history,
+		historyLimit:      cfg.historyLimit,
+		historyMax:        cfg.historyMax,
+		historyJump:       cfg.historyJump,
+		locale:            cfg.locale,
+		theme:             cfg.theme,
+		errorTimeout:      cfg.errorTimeout,
+		status:            cfg.notice,
+		autosaveEvery:     cfg.autosaveEvery,
+		autosaveMoves:     cfg.autosaveMoves,
+		coach:             cfg.coach,
+		clkComments:       cfg.clkComments,
+		evalDeltas:        cfg.evalDeltas,
+		animateCaptures:   cfg.animateCaptures,
+		overwrite:         cfg.overwrite,
+		confirmMoves:      cfg.confirmMoves,
+		speak:             cfg.speak || cfg.speakLog != "",
+		speakLog:          cfg.speakLog,
+		drill:             cfg.drill,
+		endgamesConverted: make([]bool, len(endgamePresets)),
+		demo:              cfg.demo,
+		ponder:            cfg.ponder,
+		rng:               newRNG(cfg.seed),
+		screensaverAfter:  cfg.screensaver,
+		lastInput:         time.Now(),
+		match:             cfg.match,
+		vim:               cfg.vim,
+		hintLimit:         cfg.hintLimit,
+		resignBelow:       cfg.resignBelow,
+		resignMoves:       cfg.resignMoves,
+		repeatGuard:       repeatGuard{window: cfg.repeatWindow},
+		syzygy:            cfg.syzygy,
+		syzygyPieces:      syzygyPieces(cfg.syzygy),
+		handicap:          cfg.handicap,
+		multiPV:           cfg.multiPV,
+		candidateSquares:  cfg.candidates,
+		historyWidth:      historyDesiredWidth,
+
+		legalViewport: newLegalMovesViewport(),
+		sounds:        cfg.sounds,
+		engine:        cfg.engine,
+		evals:         map[string]engineScore{},
+		bestMoves:     map[string]string{},
+	}
+	m.sessions = []*session{m.session}
+	if m.vim {
+		m.setNormalMode(true)
+	}
+	if cfg.timeControl.initial > 0 {
+		black := cfg.blackTime
+		if black.initial == 0 {
+			black = cfg.timeControl
+		}
+		m.clock = newChessClock(cfg.timeControl, black)
+	}
+	m.flipped = cfg.flipped()
+	m.chess960 = -1
+	if cfg.play960 {
+		m.chess960 = cfg.chess960
+	}
+	if m.engine != nil && cfg.side != chess.NoColor {
+		m.engineColor = cfg.side.Other()
+	}
+	if cfg.game != nil {
+		m.game = cfg.game
+		m.setHistory(sanHistory(cfg.game))
+		m.annotations = gameAnnotations(cfg.game)
+		if m.clock != nil {
+			m.clock.reset(cfg.game.Position().Turn())
+		}
+	}
+	m.positionChanged()
+	m.updateHistoryViewport()
+	// the book opens when the user drills Black
+	if m.drill != nil {
+		m.nextTurn()
+	}
+	return m
+}
+
+// modalActive reports whether an overlay is waiting for the user, during
+// which the clocks are paused.
+func (m model) modalActive() bool {
+	return m.showHelp || m.overlay != nil || m.paste != nil || m.recent != nil || m.themes != nil || m.gameOver != nil || m.trainer != nil
+}
+
+// promptPending reports whether a prompt under the board waits for the
+// user to confirm a resignation, a move or overwriting a file.
+func (m model) promptPending() bool {
+	return m.confirmResign || m.pendingMove != nil || m.stagedMove != nil || m.pendingSave != ""
+}
+
+// clockPaused reports whether the side to move should not lose time.
+func (m model) clockPaused() bool {
+	return m.modalActive() || m.promptPending() || m.mode == modeAnalysis || m.mode == modeEdit || m.match != nil && m.match.paused
+}
+
+// boardFlipped reports whether the board should be drawn from Black's side.
+func (m model) boardFlipped() bool {
+	hotSeatFlip := m.hotSeat && m.game.Position().Turn() == chess.Black
+	return m.flipped != hotSeatFlip
+}
+
+// displayedPosition is the position on the board: the live one, the one
+// being reviewed, or the preview of a staged move.
+func (m model) displayedPosition() *chess.Position {
+	switch m.mode {
+	case modeReview:
+		return m.game.Positions()[m.viewPly]
+	case modeEdit:
+		if pos, err := m.editPosition(); err == nil {
+			return pos
+		}
+	}
+	if m.stagedMove != nil {
+		// preview the staged move
+		return m.game.Position().Update(m.stagedMove)
+	}
+	return m.game.Position()
+}
+
+// newGame discards the current game and starts over from the initial position.
+// An endgame being practised is set up again, which fails once the engine is
+// gone.
+func (m *model) newGame() tea.Cmd {
+	if m.endgame >= 0 {
+		cmd, err := m.startEndgame(m.endgame)
+		if err != nil {
+			m.error = err
+		}
+		return cmd
+	}
+	if m.chess960 < 0 && m.handicap.fen != "" {
+		fen, _ := chess.FEN(m.handicap.fen)
+		return m.startGame(chess.NewGame(fen))
+	}
+	if m.chess960 < 0 {
+		return m.startGame(chess.NewGame())
+	}
+	// replay the same Chess960 position
+	id := m.chess960
+	game, _ := newGame960(id)
+	cmd := m.startGame(game)
+	m.chess960 = id
+	return cmd
+}
+
+// startGame replaces the current game with the given one and goes back to
+// play mode with fresh clocks.
+func (m *model) startGame(game *chess.Game) tea.Cmd {
+	m.setMode(modePlay)
+	claimDeadPosition(game)
+	m.game = game
+	m.chess960 = -1
+	m.endgame = -1
+	m.studied = false
+	m.branchOf = nil
+	m.redo = nil
+	m.hintsUsed = 0
+	m.historySearch = ""
+	m.setHistory(sanHistory(game))
+	m.annotations = gameAnnotations(game)
+	m.drawOffer = chess.NoColor
+	m.error = nil
+	m.status = ""
+	m.positionChanged()
+	m.updateHistoryViewport()
+	cmd := tea.Batch(m.nextEval(), m.startSound())
+	if m.clock != nil {
+		m.clock.reset(game.Position().Turn())
+		cmd = tea.Batch(cmd, m.clock.start())
+	}
+	return cmd
+}
+
+// boardFrame is the style of the border around the board.
+func (m model) boardFrame() lipgloss.Style {
+	return m.border.style(m.theme.border)
+}
+
+// boardSize is the space the board takes up in the layout, with its frame.
+func (m model) boardSize() (width, height int) {
+	width, height = m.boardOptions().size()
+	frame := m.boardFrame()
+	return width + frame.GetHorizontalFrameSize(), height + frame.GetVerticalFrameSize()
+}
+
+func (m model) boardOptions() boardOptions {
+	return boardOptions{
+		flipped:     m.boardFlipped(),
+		showCoords:  m.showCoords,
+		labels:      m.labels,
+		scale:       m.layoutScale(),
+		locale:      m.locale,
+		theme:       m.theme,
+		noColor:     m.noColor,
+		highlights:  m.moveHighlights(),
+		decorations: m.decorations(),
+		focus:       m.focusSquares(),
+		annotation:  m.shownAnnotation(),
+		turnMarker:  true,
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return textinput.Blink
+	cmds := []tea.Cmd{textinput.Blink, m.startSound()}
+	if m.clock != nil {
+		cmds = append(cmds, m.clock.start())
+	}
+	if m.engine != nil {
+		cmds = append(cmds, engineReady)
+	}
+	if m.autosaveEvery > 0 {
+		cmds = append(cmds, autosaveTick(m.autosaveEvery))
+	}
+	if m.demo {
+		cmds = append(cmds, demoTick(demoMoveDelay))
+	}
+	if m.screensaverAfter > 0 {
+		cmds = append(cmds, idleCheck())
+	}
+	if m.match != nil {
+		cmds = append(cmds, m.matchTurn())
+	}
+	return tea.Batch(cmds...)
 }
 
+// errorClearMsg clears the error shown since the given sequence number.
+type errorClearMsg int
+
+// Update handles a message, opens the game over menu when the game being
+// played ends and, when errors are set to expire, schedules clearing any new
+// error.
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	prev := m.error
+	game := m.liveGame()
+	wasOver := game.Outcome() != chess.NoOutcome
+	next, cmd := m.update(msg)
+	nm, ok := next.(model)
+	if !ok {
+		return next, cmd
+	}
+	// the side to move is asked for its move before the game is checked
+	// for its end, as a move played at once may end it
+	if turn := nm.nextTurn(); turn != nil {
+		cmd = tea.Batch(cmd, turn)
+	}
+	if !wasOver && !nm.demo && nm.liveGame() == game && game.Outcome() != chess.NoOutcome {
+		cmd = tea.Batch(cmd, nm.endSound())
+		if restart, failed := nm.endgameEnded(); failed {
+			cmd = tea.Batch(cmd, restart)
+		} else {
+			if !nm.studying() && nm.match == nil && nm.screensaver == nil {
+				nm.gameOver = &gameOverMenu{}
+			}
+		}
+	}
+	// analysis follows whatever the board shows
+	if analyse := nm.nextAnalysis(); analyse != nil {
+		cmd = tea.Batch(cmd, analyse)
+	}
+	if nm.errorTimeout <= 0 || nm.error == nil || errors.Is(nm.error, prev) {
+		return nm, cmd
+	}
+	// a new error restarts the timer; the pending clear for the old one
+	// is ignored
+	nm.errorSeq++
+	seq := nm.errorSeq
+	return nm, tea.Batch(cmd, tea.Tick(nm.errorTimeout, func(time.Time) tea.Msg {
+		return errorClearMsg(seq)
+	}))
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case errorClearMsg:
+		if int(msg) == m.errorSeq {
+			m.error = nil
+		}
+		return m, nil
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.resizeHistory(m.historyWidth)
+		m.updateLegalMovesViewport()
+		return m, nil
+	case engineReadyMsg:
+		return m, m.nextEval()
+	case evalMsg:
+		return m, m.handleEval(msg)
+	case engineMoveMsg:
+		return m, m.handleEngineMove(msg)
+	case matchMoveMsg:
+		return m, m.handleMatchMove(msg)
+	case matchNextMsg:
+		return m, m.handleMatchNext(msg)
+	case engineRestartedMsg:
+		return m, m.handleEngineRestarted(msg)
+	case engineStartedMsg:
+		return m, m.handleEngineStarted(msg)
+	case analysisMsg:
+		return m, m.handleAnalysis(msg)
+	case hintMsg:
+		return m, m.handleHint(msg)
+	case ponderDoneMsg:
+		return m, m.handlePonderDone(msg)
+	case engineProgressMsg:
+		return m, m.handleEngineProgress(msg)
+	case clockTickMsg:
+		game := m.liveGame()
+		if game.Outcome() != chess.NoOutcome {
+			m.clock.running = false
+			return m, nil
+		}
+		turn := game.Position().Turn()
+		if m.clock.tick(time.Time(msg), turn, m.clockPaused()) {
+			game.Resign(turn)
+			m.clock.running = false
+			if e := m.match; e != nil {
+				// the flagged engine's move is of no use any more
+				e.engines[e.toMove(game.Position())].stop()
+				return m, m.matchGameOver()
+			}
+			return m, nil
+		}
+		return m, clockTick()
+	case autosaveTickMsg:
+		if m.screensaver != nil {
+			// the user's game hasn't changed while away
+			return m, autosaveTick(m.autosaveEvery)
+		}
+		return m, tea.Batch(m.autosave(), autosaveTick(m.autosaveEvery))
+	case autosavedMsg:
+		m.handleAutosaved(msg)
 		return m, nil
+	case flipFrameMsg:
+		return m, m.handleFlipFrame(msg)
+	case captureFrameMsg:
+		return m, m.handleCaptureFrame(msg)
+	case demoTickMsg:
+		return m, m.handleDemoTick()
+	case idleCheckMsg:
+		return m, m.handleIdleCheck()
+	case screensaverTickMsg:
+		return m, m.handleScreensaverTick(msg)
+	case trainerTickMsg:
+		return m, m.handleTrainerTick(msg)
 	case tea.KeyMsg:
+		// any key skips the animations and is handled as usual
+		m.flipFrame = 0
+		m.capture.frame = 0
+		if msg.Type != tea.KeyEsc {
+			m.escs = escSequence{}
+		}
+		m.lastInput = time.Now()
+		if m.screensaver != nil {
+			m.stopScreensaver()
+			return m, nil
+		}
+		if m.demo && msg.Type != tea.KeyCtrlC {
+			m.stopDemo()
+			return m, nil
+		}
+		if m.overlay != nil {
+			return m.updateOverlay(msg)
+		}
+		if m.paste != nil {
+			return m.updatePaste(msg)
+		}
+		if m.recent != nil {
+			return m.updateRecent(msg)
+		}
+		if m.themes != nil {
+			return m.updateThemes(msg)
+		}
+		if m.gameOver != nil {
+			return m.updateGameOver(msg)
+		}
+		if m.trainer != nil {
+			return m.updateTrainer(msg)
+		}
+		if m.showHelp {
+			if msg.Type == tea.KeyCtrlC {
+				return m, tea.Quit
+			}
+			m.showHelp = false
+			return m, nil
+		}
+		if m.pendingSave != "" {
+			return m, m.answerOverwrite(msg)
+		}
+		if m.stagedMove != nil {
+			if cmd, ok := m.answerStaged(msg); ok {
+				return m, cmd
+			}
+		}
+		if m.pendingMove != nil {
+			if cmd, ok := m.answerCoach(msg); ok {
+				return m, cmd
+			}
+		}
+
+		if m.commandMode {
+			return m.updateCommandMode(msg)
+		}
+		if m.match != nil && m.matchKey(msg) {
+			return m, nil
+		}
+		if m.vim && m.normalMode {
+			if cmd, ok := m.normalKey(msg); ok {
+				return m, cmd
+			}
+		} else if m.vim && msg.Type == tea.KeyEsc {
+			m.setNormalMode(true)
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "f2":
+			m.setMode(modePlay)
+			return m, nil
+		case "f3", "tab":
+			if m.mode == modeReview {
+				m.setMode(modePlay)
+			} else {
+				m.setMode(modeReview)
+			}
+			return m, nil
+		case "f4":
+			m.setMode(modeAnalysis)
+			return m, nil
+		case "f5":
+			m.setMode(modeEdit)
+			return m, nil
+		case "f6":
+			cmd, err := m.toggleAnalysis()
+			m.error = err
+			return m, cmd
+		case "esc":
+			// a half-typed move goes first, like in an editor
+			if m.textInput.Value() != "" {
+				m.textInput.Reset()
+				m.error = nil
+				return m, nil
+			}
+			if m.ambiguous != nil {
+				m.ambiguous = nil
+				m.error = nil
+				return m, nil
+			}
+			if m.mode != modePlay {
+				m.setMode(modePlay)
+				return m, nil
+			}
+			if m.escGesture != escOff {
+				return m, m.escPressed(time.Now())
+			}
+		case ":":
+			if m.textInput.Value() == "" {
+				m.enterCommandMode()
+				return m, nil
+			}
+		case "?":
+			m.showHelp = true
+			return m, nil
+		case "f7":
+			m.error = m.openRecent()
+			return m, nil
+		case "f8":
+			m.error = m.toggleNotation()
+			return m, nil
+		case "f9":
+			m.toggleHistory()
+			return m, nil
+		case "f10":
+			m.toggleAnalysisLayout()
+			return m, nil
+		case "ctrl+t":
+			cmd, err := m.openTab()
+			m.error = err
+			return m, cmd
+		case "ctrl+pgdown":
+			return m, m.switchTab((m.active + 1) % len(m.sessions))
+		case "ctrl+pgup":
+			return m, m.switchTab((m.active + len(m.sessions) - 1) % len(m.sessions))
+		case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9":
+			return m, m.switchTab(int(msg.Runes[0] - '1'))
+		case "[":
+			m.resizeHistory(m.viewport.Width - historyWidthStep)
+			return m, nil
+		case "]":
+			m.resizeHistory(m.viewport.Width + historyWidthStep)
+			return m, nil
+		}
+
+		if m.mode == modeReview {
+			return m.updateReviewMode(msg)
+		}
+		if m.mode == modePlay && m.game.Outcome() != chess.NoOutcome {
+			return m.updateGameEnded(msg)
+		}
+
 		switch msg.Type {
 		case tea.KeyCtrlC, tea.KeyEsc:
 			return m, tea.Quit
-		case tea.KeyEnter:
-			err := m.game.MoveStr(m.textInput.Value())
-			if err != nil {
+		case tea.KeyCtrlL:
+			m.toggleLegalMoves()
+			return m, nil
+		case tea.KeyCtrlX:
+			m.copyPGN()
+			return m, nil
+		case tea.KeyCtrlO:
+			m.copyLastMove()
+			return m, nil
+		case tea.KeyCtrlP:
+			m.togglePhase()
+			return m, nil
+		case tea.KeyCtrlS:
+			cmd, err := m.passTurn()
+			m.error = err
+			return m, cmd
+		case tea.KeyCtrlG:
+			cmd, err := m.requestHint()
+			m.error = err
+			return m, cmd
+		case tea.KeyCtrlZ:
+			m.error = m.undo()
+			return m, nil
+		case tea.KeyCtrlY:
+			cmd, err := m.redoMove()
+			m.error = err
+			return m, cmd
+		case tea.KeyCtrlR:
+			path := fmt.Sprintf("gochess-%d.cast", time.Now().Unix())
+			if err := writeCast(m.game, path, m.boardOptions()); err != nil {
 				m.error = err
 			} else {
 				m.error = nil
-				m.textInput.Reset() // Clear input after successful move
+				m.status = "Replay saved to " + path
+			}
+			return m, nil
+		case tea.KeyEnter:
+			cmd := m.submitInput()
+			m.fitInput(false)
+			return m, cmd
+		}
+	case tea.MouseMsg:
+		m.lastInput = time.Now()
+		if m.screensaver != nil {
+			if msg.Action == tea.MouseActionPress {
+				m.stopScreensaver()
 			}
 			return m, nil
 		}
+		if cmd := m.handleMouse(msg); cmd != nil {
+			return m, cmd
+		}
 	}
 
+	var cmds []tea.Cmd
 	var cmd tea.Cmd
+	if key, ok := msg.(tea.KeyMsg); ok && key.Paste {
+		m.fitInput(true)
+	}
 	m.textInput, cmd = m.textInput.Update(msg)
-	return m, cmd
+	m.fitInput(false)
+	cmds = append(cmds, cmd)
+	m.viewport, cmd = m.viewport.Update(msg)
+	cmds = append(cmds, cmd)
+	if m.showLegalMoves {
+		m.legalViewport, cmd = m.legalViewport.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// moveApplied updates everything that depends on the game after a move was
+// played.
+func (m *model) moveApplied() tea.Cmd {
+	m.error = nil
+	m.status = ""
+	m.drawOffer = chess.NoColor
+	m.redo = nil
+	if m.clock != nil && m.mode == modePlay {
+		m.clock.moved(m.game.Position().Turn().Other(), len(m.game.Moves())-1)
+	}
+	m.history = append(m.history, lastMoveSAN(m.game))
+	if m.inputMask != maskOff {
+		// the move was hidden while typed
+		m.status = m.game.Position().Turn().Other().Name() + " played " + lastMoveSAN(m.game)
+	}
+	m.trimHistory()
+	claimDeadPosition(m.game)
+	m.positionChanged()
+	m.updateHistoryViewport()
+	if m.mode == modePlay {
+		// before the evaluation, which stops pondering on any other move
+		m.ponderHit()
+	}
+
+	moves := m.game.Moves()
+	return tea.Batch(playSound(m.sounds, moveSoundEvent(moves[len(moves)-1])), m.announceMove(), m.nextEval(), m.autosaveAfterMove(), m.startCaptureFlash())
+}
+
+// positionChanged refreshes the state derived from the live position.
+func (m *model) positionChanged() {
+	m.pendingMove = nil
+	m.stagedMove = nil
+	m.cycledMove = nil
+	m.ambiguous = nil
+	m.hint = nil
+	m.capture.frame = 0
+	m.validMoves = m.game.ValidMoves()
+	m.updateLegalMovesViewport()
+}
+
+func (m *model) toggleLegalMoves() {
+	m.showLegalMoves = !m.showLegalMoves
+	m.resizeHistory(m.historyWidth)
+	m.updateLegalMovesViewport()
+}
+
+// lastMoveSAN returns the most recent move of the game in algebraic notation.
+func lastMoveSAN(game *chess.Game) string {
+	moves := game.Moves()
+	positions := game.Positions()
+	if len(moves) == 0 {
+		return ""
+	}
+	return chess.AlgebraicNotation{}.Encode(positions[len(positions)-2], moves[len(moves)-1])
 }
 
 func (m model) View() string {
@@ -123,50 +1037,216 @@ func (m model) View() string {
 	var sb strings.Builder
 
 	// Title
-	title := titleStyle.Render("Go Chess")
+	titleText := "Go Chess"
+	if m.chess960 >= 0 {
+		titleText += fmt.Sprintf(" · Chess960 #%d", m.chess960)
+	}
+	if m.drill != nil {
+		done, total := m.drill.progress()
+		titleText += fmt.Sprintf(" · Drill %d/%d", done, total)
+	}
+	if m.endgame >= 0 {
+		titleText += " · " + endgamePresets[m.endgame].name
+	}
+	if m.playingHandicap() {
+		titleText += " · " + m.handicap.label
+	}
+	if m.studying() {
+		done, total := m.study.progress()
+		titleText += fmt.Sprintf(" · Study %d/%d", done, total)
+	}
+	if m.match != nil {
+		titleText += " · " + m.match.title()
+	}
+	if m.mode != modePlay {
+		titleText += " · " + m.mode.String()
+	}
+	title := titleStyle.Render(titleText)
+	if len(m.sessions) > 1 {
+		title = m.renderTabBar(titleText)
+	}
 	sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, title))
 	sb.WriteString("\n\n")
 
-	// Board
-	board := renderBoard(m.game, m.width)
-	sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, board))
+	// Board and history side by side
+	opts := m.boardOptions()
+	boardWidth, _ := opts.size()
+	shown := m.displayedPosition()
+	if mv := m.ghostMove(); mv != nil {
+		shown, opts = m.ghostBoard(mv, opts)
+	}
+	if m.capture.frame > 0 && m.mode != modeReview {
+		shown, opts = m.captureBoard(opts)
+	}
+	board := renderBoard(shown, boardWidth, opts)
+	if m.flipFrame > 0 {
+		board = renderFlippingBoard(m.displayedPosition(), boardWidth, opts, m.flipFrame)
+	}
+	board = m.boardFrame().Render(board)
+	framedHeight := lipgloss.Height(board)
+	board = lipgloss.JoinVertical(lipgloss.Left, board, renderCastlingRights(m.displayedPosition(), lipgloss.Width(board)))
+	showInfo := m.showInfo()
+	if m.materialView == materialBar {
+		board = lipgloss.JoinVertical(lipgloss.Left, board, m.renderMaterialBar(lipgloss.Width(board)))
+	} else if m.captureOrder != capturesOff && !showInfo {
+		board = lipgloss.JoinVertical(lipgloss.Left, board, m.renderCaptured(lipgloss.Width(board)))
+	}
+	if showInfo {
+		board = m.renderInfoSides(board, framedHeight)
+	}
+	body := board
+	if m.analysisLayout {
+		body = m.renderAnalysisLayout(board)
+	}
+	if !m.hideHistory && !m.analysisLayout {
+		body = lipgloss.JoinHorizontal(lipgloss.Top, board, strings.Repeat(" ", historyGap), m.renderHistory())
+	}
+	if m.showLegalMoves && !m.analysisLayout {
+		body = lipgloss.JoinHorizontal(lipgloss.Top, body, strings.Repeat(" ", historyGap), m.renderLegalMoves())
+	}
+	if m.engine != nil && !m.modalActive() && !m.analysisLayout {
+		body = lipgloss.JoinVertical(lipgloss.Left, body, m.renderEvalGraph(lipgloss.Width(body)))
+	}
+	if m.studying() {
+		body = lipgloss.JoinVertical(lipgloss.Left, body, m.renderStudyPanel(lipgloss.Width(body)))
+	}
+	if m.showHelp {
+		body = renderHelp()
+	}
+	if m.gameOver != nil {
+		body = m.gameOver.View(m)
+	}
+	if m.overlay != nil {
+		body = m.overlay.View()
+	}
+	if m.paste != nil {
+		body = m.paste.View()
+	}
+	if m.recent != nil {
+		body = m.recent.View()
+	}
+	if m.themes != nil {
+		body = m.themes.View(m)
+	}
+	if m.trainer != nil {
+		body = m.trainer.View(m)
+	}
+	sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, body))
 	sb.WriteString("\n\n")
 
+	if m.clock != nil {
+		clocks := m.renderClocks()
+		if showInfo {
+			// the clocks are beside the board, only the time control is left
+			clocks = clockStyle.Faint(true).Render(m.clock.label())
+		}
+		sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, clocks))
+		if summary := m.renderTimeSummary(); summary != "" {
+			sb.WriteString("\n" + lipgloss.PlaceHorizontal(m.width, lipgloss.Center, summary))
+		}
+		sb.WriteString("\n\n")
+	}
+
 	// Game status
-	if m.game.Outcome() != chess.NoOutcome {
-		status := statusMessageStyle.Render(fmt.Sprintf("Game over! %s\n\nPress 'n' to start a new game or 'esc' to quit", outcomeString(m.game.Outcome())))
+	if m.screensaver != nil {
+		sb.WriteString(m.renderScreensaverStatus())
+	} else if m.trainer != nil {
+		sb.WriteString(m.renderInput())
+	} else if m.game.Outcome() != chess.NoOutcome {
+		result := resultString(m.game)
+		if m.clock != nil && m.clock.flagged != chess.NoColor {
+			result = fmt.Sprintf("%s (%s ran out of time)", result, m.clock.flagged.Name())
+		}
+		status := statusMessageStyle.Render(fmt.Sprintf("Game over! %s\n\nPress 'n' to start a new game, ':' for commands or 'esc' to quit", result))
 		sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, status))
+		if m.pendingSave != "" {
+			sb.WriteString("\n\n" + lipgloss.PlaceHorizontal(m.width, lipgloss.Center, statusMessageStyle.Bold(true).Render(m.overwritePrompt())))
+		}
+		// moves can't be entered any more, but commands can
+		if m.commandMode {
+			sb.WriteString("\n" + m.renderInput())
+		}
+		if m.error != nil {
+			sb.WriteString("\n\n")
+			sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, errorStyle.Render(m.error.Error())))
+		}
 	} else {
 		// Current turn
-		turnStyle := turnWhite
-		turn := "White"
-		if m.game.Position().Turn() == chess.Black {
-			turnStyle = turnBlack
-			turn = "Black"
+		turnStatus := m.renderTurn(m.game.Position().Turn(), m.game.Position())
+		if m.engineThinking && m.engineProgress != nil {
+			turnStatus += statusMessageStyle.Render(" (engine thinking: " + renderProgress(*m.engineProgress, m.game.Position()) + ")")
+		} else if m.engineThinking {
+			turnStatus += statusMessageStyle.Render(" (engine thinking…)")
+		}
+		if m.mode == modeReview {
+			turnStatus = statusMessageStyle.Render(fmt.Sprintf("Reviewing ply %d of %d", m.viewPly, len(m.game.Moves())))
+		}
+		if m.mode == modeEdit {
+			turnStatus = m.renderTurn(m.editTurn, m.game.Position())
+		}
+		if note := m.onlyMoveNote(); note != "" {
+			turnStatus += statusMessageStyle.Render(" · " + note)
+		}
+		if m.showPhase && m.mode != modeEdit {
+			turnStatus += statusMessageStyle.Render(" · " + positionPhase(m.displayedPosition()).String())
+		}
+		// a position without legal moves may be shown before the game
+		// has ended, e.g. while stepping through it
+		if verdict := positionVerdict(m.displayedPosition()); verdict != "" && m.mode != modeEdit {
+			turnStatus = statusMessageStyle.Bold(true).Render(verdict)
 		}
-
-		turnStatus := turnStyle.Render(fmt.Sprint(turn)) + statusMessageStyle.Render(" to move")
 		sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, turnStatus))
 		sb.WriteString("\n")
+		if m.ambiguous != nil {
+			sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, statusMessageStyle.Bold(true).Render(m.ambiguityPrompt())))
+			sb.WriteString("\n")
+		}
+		if m.confirmResign {
+			sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, statusMessageStyle.Bold(true).Render(m.game.Position().Turn().Name()+" resigns? Type y to confirm")))
+			sb.WriteString("\n")
+		}
+		if note := m.branchNote(); note != "" {
+			sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, statusMessageStyle.Faint(true).Render(note)))
+			sb.WriteString("\n")
+		}
+		if m.drawOffer != chess.NoColor && m.mode == modePlay {
+			sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, statusMessageStyle.Bold(true).Render(m.drawOfferPrompt())))
+			sb.WriteString("\n")
+		}
+		if m.pendingMove != nil {
+			sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, statusMessageStyle.Bold(true).Render(m.coachPrompt())))
+			sb.WriteString("\n")
+		}
+		if m.stagedMove != nil {
+			sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, statusMessageStyle.Bold(true).Render(m.stagedPrompt())))
+			sb.WriteString("\n")
+		}
+		if m.pendingSave != "" {
+			sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, statusMessageStyle.Bold(true).Render(m.overwritePrompt())))
+			sb.WriteString("\n")
+		}
+		if m.analysing && !m.analysisLayout {
+			sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, statusMessageStyle.Render(m.analysisLine())))
+			sb.WriteString("\n")
+			if list := m.candidateList(); list != "" {
+				sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, list))
+				sb.WriteString("\n")
+			}
+		}
+		if hint, ok := modeHints[m.mode]; ok {
+			sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, statusMessageStyle.Faint(true).Render(hint)))
+			sb.WriteString("\n")
+		}
 
-		inputWidth := 16 // Fixed width for input area
-		inputContainer := lipgloss.NewStyle().
-			Width(inputWidth).
-			Align(lipgloss.Left)
-
-		// Build the input line
-		inputLine := lipgloss.JoinHorizontal(
-			lipgloss.Left,
-			inputContainer.Render(m.textInput.View()),
-		)
-
-		// Center the entire line
-		centeredInput := lipgloss.PlaceHorizontal(
-			m.width,
-			lipgloss.Center,
-			inputLine,
-		)
-		sb.WriteString("\n" + centeredInput)
+		// The input is hidden while reviewing unless a command is being typed,
+		// and in the analysis layout until something is typed
+		typing := m.commandMode || m.textInput.Value() != ""
+		if (m.mode != modeReview && !m.analysisLayout) || typing {
+			sb.WriteString("\n" + m.renderInput())
+		}
+		if m.vim && !m.commandMode {
+			sb.WriteString("\n" + lipgloss.PlaceHorizontal(m.width, lipgloss.Center, statusMessageStyle.Faint(true).Render(m.vimStatus())))
+		}
 		// Error message
 		if m.error != nil {
 			sb.WriteString("\n\n")
@@ -174,9 +1254,52 @@ func (m model) View() string {
 		}
 	}
 
+	status := m.status
+	if selected := m.selectionStatus(); selected != "" {
+		status = strings.TrimPrefix(status+" · "+selected, " · ")
+	}
+	if m.hovering {
+		status = strings.TrimPrefix(status+" · "+m.hovered.String(), " · ")
+	}
+	if status != "" {
+		sb.WriteString("\n\n")
+		sb.WriteString(lipgloss.PlaceHorizontal(m.width, lipgloss.Center, statusMessageStyle.Render(status)))
+	}
+
 	return docStyle.Render(sb.String())
 }
 
+// renderInput draws the move or command input centered in the window.
+func (m model) renderInput() string {
+	inputContainer := lipgloss.NewStyle().
+		Width(m.inputWidth()).
+		Align(lipgloss.Left)
+
+	// Build the input line
+	inputLine := lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		inputContainer.Render(m.textInput.View()),
+	)
+
+	// Center the entire line
+	return lipgloss.PlaceHorizontal(
+		m.width,
+		lipgloss.Center,
+		inputLine,
+	)
+}
+
+func parseColor(s string) (chess.Color, error) {
+	switch s {
+	case "white":
+		return chess.White, nil
+	case "black":
+		return chess.Black, nil
+	default:
+		return chess.NoColor, fmt.Errorf("unknown side %q (want white or black)", s)
+	}
+}
+
 func outcomeString(outcome chess.Outcome) string {
 	switch outcome {
 	case chess.WhiteWon:
@@ -190,69 +1313,317 @@ func outcomeString(outcome chess.Outcome) string {
 	}
 }
 
-func renderBoard(game *chess.Game, width int) string {
-	board := game.Position().Board()
-	var sb strings.Builder
-
-	// The complete board line (including rank numbers) is exactly 26 characters:
-	// 2 (left rank) + 24 (8 squares × 3 chars) + 2 (right rank) = 28 chars
-	boardLineWidth := 28
-
-	// Center the entire board block
-	boardIndent := max((width-boardLineWidth)/2, 0)
-	indentStr := strings.Repeat(" ", boardIndent)
-
-	// File labels - perfectly aligned under squares
-	files := strings.Join([]string{"", "a", "b", "c", "d", "e", "f", "g", "h", ""}, "  ")
-	centeredFiles := lipgloss.PlaceHorizontal(width, lipgloss.Center, files)
-	sb.WriteString(centeredFiles)
-	sb.WriteString("\n")
-
-	for rank := 7; rank >= 0; rank-- {
-		sb.WriteString(indentStr)
-		sb.WriteString(fmt.Sprintf("%d ", rank+1))
-
-		for file := range 8 {
-			sq := chess.Square(file + rank*8)
-			piece := board.Piece(sq)
-
-			var squareStyle, pieceStyle lipgloss.Style
-			if (file+rank)%2 == 0 {
-				squareStyle = darkSquare
-			} else {
-				squareStyle = lightSquare
-			}
-
-			if piece != chess.NoPiece && piece.Color() == chess.White {
-				pieceStyle = whitePiece
-			} else {
-				pieceStyle = blackPiece
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		if err := runRender(os.Args[2:]); err != nil {
+			if !errors.Is(err, flag.ErrHelp) {
+				fmt.Fprintln(os.Stderr, err)
 			}
+			os.Exit(2)
+		}
+		return
+	}
 
-			if piece == chess.NoPiece {
-				sb.WriteString(squareStyle.Render(" "))
-			} else {
-				notation := pieceNotation[piece]
-				sb.WriteString(squareStyle.Render(pieceStyle.Render(notation)))
-			}
+	var cfg config
+	flag.BoolVar(&cfg.hotSeat, "hotseat", false, "flip the board after every move so the side to move is at the bottom")
+	flag.BoolVar(&cfg.showCoords, "coords", false, "debug: show coordinates inside empty squares")
+	flag.BoolVar(&cfg.focus, "focus", false, "while reviewing, dim the pieces that didn't take part in the last move")
+	flag.Func("labels", "where to draw rank/file labels: all, left-bottom or none", func(s string) error {
+		var err error
+		cfg.labels, err = parseLabelPlacement(s)
+		return err
+	})
+	flag.Func("border", "frame around the board: none, rounded or double (colored by the theme's border)", func(s string) error {
+		var err error
+		cfg.border, err = parseBoardBorder(s)
+		return err
+	})
+	flag.Func("compat", "for terminals the board looks broken in: 16 (basic colors) or mono (no colors), both without the alternate screen; auto detects", func(s string) error {
+		var err error
+		cfg.compat, err = parseCompatMode(s)
+		return err
+	})
+	flag.Func("captured", "list the pieces each side has taken beside the board, or under it when the window is narrow, by value: asc, desc or off", func(s string) error {
+		var err error
+		cfg.captured, err = parseCaptureOrder(s)
+		return err
+	})
+	flag.Func("material", "show the material balance under the board as the captured pieces (with -captured) or as a bar: pieces or bar", func(s string) error {
+		var err error
+		cfg.material, err = parseMaterialView(s)
+		return err
+	})
+	flag.Func("highlight", "how to draw the last move, check and selection highlights, e.g. last=underline,selection=brackets: fill, underline, brackets or off each", func(s string) error {
+		var err error
+		cfg.highlights, err = parseHighlightStyles(s)
+		return err
+	})
+	flag.Func("triple-esc", "what pressing Esc three times quickly does instead of a single Esc quitting: resign, quit or off", func(s string) error {
+		var err error
+		cfg.escGesture, err = parseEscGesture(s)
+		return err
+	})
+	flag.Func("mask", "hide moves while they are typed, for playing on a shared screen: dots, blank or off", func(s string) error {
+		var err error
+		cfg.inputMask, err = parseInputMask(s)
+		return err
+	})
+	flag.Func("turn", "how to show the side to move: text, dot, letter (as in FEN) or verbose (with the move number)", func(s string) error {
+		var err error
+		cfg.turnFormat, err = parseTurnFormat(s)
+		return err
+	})
+	flag.Func("scale", "board size: compact, small, normal or large", func(s string) error {
+		var err error
+		cfg.scale, err = parseBoardScale(s)
+		return err
+	})
+	flag.Func("history", "history panel layout: pairs, plies, inline or columns (pairs in columns once they overflow)", func(s string) error {
+		var err error
+		cfg.history, err = parseHistoryFormat(s)
+		return err
+	})
+	flag.BoolVar(&cfg.historyJump, "history-jump", false, "scroll the history to each new move even when scrolled up to earlier ones")
+	flag.IntVar(&cfg.historyMax, "history-max", 0, "keep only the last `n` move pairs for the history, for very long sessions (0 keeps all); the game keeps every move")
+	flag.IntVar(&cfg.historyLimit, "history-last", 0, "only list the last `n` move pairs in the history (0 lists all)")
+	flag.Func("locale", "piece letters to use: en, de, fr, es or nl", func(s string) error {
+		var err error
+		cfg.locale, err = parseLocale(s)
+		return err
+	})
+	themeGiven := false
+	flag.Func("theme", "load board colors and the piece set (letters, unicode or figurine) from this `file` (:theme picks one and keeps it)", func(s string) error {
+		var err error
+		cfg.theme, err = loadTheme(s)
+		themeGiven = true
+		return err
+	})
+	flag.Func("side", "the side you play, white or black; with -engine the engine plays the other side", func(s string) error {
+		var err error
+		cfg.side, err = parseColor(s)
+		return err
+	})
+	flag.Func("orientation", "the side shown at the bottom of the board, white or black (defaults to -side)", func(s string) error {
+		var err error
+		cfg.orientation, err = parseColor(s)
+		return err
+	})
+	flag.DurationVar(&cfg.timeControl.initial, "clock", 0, "time per player, e.g. 5m (0 disables the clocks)")
+	flag.DurationVar(&cfg.timeControl.increment, "increment", 0, "time added to a player's clock after each move, e.g. 3s")
+	flag.Func("960", "start from Chess960 position `id` (0-959, 518 is the standard position); castling is only possible in 518", func(s string) error {
+		id, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		if _, err := backRank960(id); err != nil {
+			return err
 		}
+		cfg.play960, cfg.chess960 = true, id
+		return nil
+	})
+	flag.DurationVar(&cfg.autosaveEvery, "autosave-every", 0, "autosave the game to the config directory this often, e.g. 1m")
+	flag.IntVar(&cfg.autosaveMoves, "autosave-moves", 0, "autosave the game to the config directory every this many moves")
+	flag.BoolVar(&cfg.speak, "speak", false, "describe every move in words in the status line, for screen readers")
+	flag.StringVar(&cfg.speakLog, "speak-log", "", "also append the move descriptions to this `file` (implies -speak)")
+	flag.IntVar(&cfg.resignBelow, "engine-resign", 0, "let the engine resign once its evaluation is `cp` centipawns or more against it, or it sees itself mated (0 never resigns)")
+	flag.IntVar(&cfg.resignMoves, "engine-resign-moves", 3, "how many of its moves in a row the engine has to be lost for before -engine-resign")
+	flag.DurationVar(&cfg.repeatWindow, "repeat-guard", 300*time.Millisecond, "ignore a move entered again this soon after the same move was played, as when enter is held down (0 turns it off)")
+	flag.IntVar(&cfg.hintLimit, "hints", 0, "allow only `n` engine hints (ctrl+g) per game (0 allows any number)")
+	flag.IntVar(&cfg.multiPV, "multipv", defaultMultiPV, "list the engine's `n` best moves while analysing (f6)")
+	flag.BoolVar(&cfg.ponder, "ponder", false, "let the engine think about the reply it expects while you are to move, and answer at once when you play it")
+	flag.StringVar(&cfg.syzygy, "syzygy", "", "let the engine probe the Syzygy tablebases in this `path` and show the exact result of endgames")
+	flag.BoolVar(&cfg.candidates, "candidates", false, "highlight the squares of the candidate moves while analysing, shaded by strength")
+	flag.BoolVar(&cfg.vim, "vim", false, "start in normal mode, where single keys are commands: i types a move and esc returns")
+	flag.BoolVar(&cfg.confirmMoves, "confirm", false, "preview each move on the board and play it when enter is pressed again")
+	flag.Func("overwrite", "what :save does when the file exists: ask, suffix (save as game-1.pgn, …) or always", func(s string) error {
+		var err error
+		cfg.overwrite, err = parseOverwritePolicy(s)
+		return err
+	})
+	flag.BoolVar(&cfg.coach, "coach", false, "ask for confirmation before a move that hangs your queen or a rook")
+	flag.BoolVar(&cfg.animateCaptures, "animate-captures", false, "flash the square of a captured piece for a moment before the capturing piece lands on it")
+	flag.BoolVar(&cfg.evalDeltas, "eval-deltas", false, "with -engine, follow each move in the history with how much it changed the evaluation, marking inaccuracies ?!, mistakes ? and blunders ??")
+	flag.BoolVar(&cfg.clkComments, "clk", false, "write the time left after each move into saved and copied PGN as [%clk] comments (with -clock or -tc)")
+	flag.DurationVar(&cfg.errorTimeout, "error-timeout", 0, "clear error messages after this long, e.g. 3s (0 keeps them until the next move)")
+	flag.Func("tc", "time control in minutes and seconds: 3+2 for a Fischer increment, 3d2 for a Bronstein delay;\nWhite:Black such as 5+0:1+0 gives time odds", func(s string) error {
+		var err error
+		cfg.timeControl, cfg.blackTime, err = parseTimeOdds(s)
+		return err
+	})
+	flag.Func("handicap", "start without these comma separated `pieces` or squares, e.g. queen, both knights, black rook or d1,f7", func(s string) error {
+		var err error
+		cfg.handicap, err = parseHandicap(s)
+		return err
+	})
+	flag.DurationVar(&cfg.screensaver, "screensaver", 0, "after this long without input, play through famous games until a key is pressed, e.g. 5m (0 disables it)")
+	flag.BoolVar(&cfg.demo, "demo", false, "play games automatically (with -engine, engine against engine) until a key is pressed")
+	flag.Uint64Var(&cfg.seed, "seed", 0, "seed the random demo moves and trainer squares with `n`, to see them again (0 seeds from the time)")
+	matchEngines := flag.String("match", "", "play a match between two UCI engines given as comma separated `paths`, e.g. stockfish,lc0\n(space pauses and resumes, s plays one move while paused)")
+	matchGames := flag.Int("match-games", 2, "number of games in a -match, the engines taking turns with White")
+	matchMovetime := flag.Duration("match-movetime", time.Second, "time each engine thinks per move in a -match without -clock or -tc")
+	sound := flag.Bool("sound", false, "play a sound (terminal bell by default) for captures, castling, promotions and checks, and when a game starts or ends")
+	pgnPath := flag.String("pgn", "", "load the game from a PGN file")
+	movesPath := flag.String("moves", "", "play the moves in this file (- for stdin), one per line, before starting")
+	headless := flag.Bool("headless", false, "print the final board instead of starting the UI")
+	elo := flag.Int("elo", 0, "limit the engine's strength to about this Elo rating")
+	skill := flag.Int("skill", 0, "set the engine's skill level (Stockfish: 0-20)")
+	movetime := flag.Int("movetime", 0, "let the engine think this many `ms` per search")
+	nodes := flag.Int("nodes", 0, "let the engine search this many nodes per search")
+	depth := flag.Int("depth", 0, "let the engine search to this depth")
+	drillPath := flag.String("drill", "", "drill the opening lines in this PGN or text file, playing -side (white by default)")
+	enginePath := flag.String("engine", "", "path to a UCI engine used to evaluate the game (and to play with -side)")
+	soundMap := flag.String("sound-map", "", "comma separated event=sound overrides, e.g. capture=bell:2,check=/path/check.wav\n(events: move, capture, castle, enpassant, promotion, check, and start, win, loss, draw for the game)")
+	flag.Parse()
 
-		sb.WriteString(fmt.Sprintf(" %d", rank+1))
-		sb.WriteString("\n")
+	cfg.notation = savedNotation()
+	if !themeGiven {
+		if t, ok := savedTheme(); ok {
+			cfg.theme = t
+		}
 	}
 
-	// File labels (same as top)
-	sb.WriteString(centeredFiles)
-	return sb.String()
-}
+	if cfg.resignMoves < 1 {
+		fmt.Fprintln(os.Stderr, "-engine-resign-moves must be at least 1")
+		os.Exit(2)
+	}
+	if *sound {
+		sounds, err := parseSoundMap(*soundMap)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		cfg.sounds = sounds
+	}
+	if *pgnPath != "" {
+		game, err := loadPGN(*pgnPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		cfg.game = game
+	}
+	if cfg.play960 {
+		if cfg.game != nil {
+			fmt.Fprintln(os.Stderr, "-960 and -pgn can't be combined")
+			os.Exit(2)
+		}
+		cfg.game, _ = newGame960(cfg.chess960)
+	}
+	if cfg.handicap.fen != "" {
+		if cfg.game != nil {
+			fmt.Fprintln(os.Stderr, "-handicap can't be combined with -pgn or -960")
+			os.Exit(2)
+		}
+		fen, _ := chess.FEN(cfg.handicap.fen)
+		cfg.game = chess.NewGame(fen)
+	}
+	if *movesPath != "" {
+		if cfg.game == nil {
+			cfg.game = chess.NewGame()
+		}
+		if err := readMoves(cfg.game, *movesPath); err != nil {
+			fmt.Fprintln(os.Stderr, "reading moves:", err)
+			os.Exit(1)
+		}
+	}
+	if *drillPath != "" {
+		if cfg.game != nil || *enginePath != "" {
+			fmt.Fprintln(os.Stderr, "-drill can't be combined with -pgn, -moves, -960 or -engine")
+			os.Exit(2)
+		}
+		side := cfg.side
+		if side == chess.NoColor {
+			side = chess.White
+		}
+		drill, err := loadRepertoire(*drillPath, side)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		cfg.drill = drill
+	}
+	if *matchEngines != "" {
+		if cfg.game != nil || *enginePath != "" || cfg.drill != nil || cfg.demo {
+			fmt.Fprintln(os.Stderr, "-match can't be combined with -pgn, -moves, -960, -handicap, -engine, -drill or -demo")
+			os.Exit(2)
+		}
+		if *headless {
+			fmt.Fprintln(os.Stderr, "-match needs the UI")
+			os.Exit(2)
+		}
+		match, err := startMatch(parseMatchEngines(*matchEngines), *matchGames, *matchMovetime)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		cfg.match = match
+	}
+	if *headless {
+		game := cfg.game
+		if game == nil {
+			game = chess.NewGame()
+		}
+		printGame(os.Stdout, game, boardOptions{flipped: cfg.flipped(), labels: cfg.labels, scale: cfg.scale, theme: cfg.theme})
+		return
+	}
+	limits := 0
+	for _, n := range []int{*movetime, *nodes, *depth} {
+		if n < 0 {
+			fmt.Fprintln(os.Stderr, "-movetime, -nodes and -depth can't be negative")
+			os.Exit(2)
+		}
+		if n > 0 {
+			limits++
+		}
+	}
+	if limits > 1 {
+		fmt.Fprintln(os.Stderr, "only one of -movetime, -nodes and -depth can be given")
+		os.Exit(2)
+	}
+	if *enginePath != "" {
+		engine, err := startEngine(*enginePath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "starting engine:", err)
+			os.Exit(1)
+		}
+		cfg.engine = engine
+		engine.limit = searchLimit{movetime: time.Duration(*movetime) * time.Millisecond, nodes: *nodes, depth: *depth}
+		if err := engine.limitStrength(*elo, *skill); err != nil {
+			// play on at full strength, but tell the user
+			cfg.notice = "Engine strength not limited: " + err.Error()
+		}
+		if cfg.ponder && engine.options["Ponder"] {
+			// tells the engine it will be pondering, for its time management
+			engine.setOption("Ponder", "true")
+		}
+		if cfg.syzygy != "" {
+			if err := engine.useSyzygy(cfg.syzygy); err != nil {
+				cfg.notice = strings.TrimPrefix(cfg.notice+"; Tablebases not used: "+err.Error(), "; ")
+			}
+		}
+	}
 
-func main() {
-	p := tea.NewProgram(
-		initialModel(),
-		tea.WithAltScreen(),
-		tea.WithMouseCellMotion(), // add mouse support for good measure
-	)
-	if _, err := p.Run(); err != nil {
+	cfg.noColor = cfg.compat.apply()
+	opts := []tea.ProgramOption{
+		tea.WithMouseAllMotion(), // report motion without a button held too, for hover coordinates
+	}
+	if cfg.compat.altScreen() {
+		opts = append(opts, tea.WithAltScreen())
+	}
+	if *movesPath == "-" {
+		// stdin has been used up by the moves, read keys from the terminal
+		opts = append(opts, tea.WithInputTTY())
+	}
+	p := tea.NewProgram(initialModel(cfg), opts...)
+	final, err := p.Run()
+	if err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 	}
+	// the engine may have been restarted, so close the one in use at the end
+	if m, ok := final.(model); ok && m.engine != nil {
+		m.engine.close()
+	}
+	if cfg.match != nil {
+		cfg.match.close()
+	}
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// inputMask is how a move is shown while it is typed, so that on a shared
+// screen the opponent doesn't read it before it is played.
+type inputMask int
+
+const (
+	maskOff   inputMask = iota // the move as typed
+	maskDots                   // a dot for each character
+	maskBlank                  // nothing at all
+)
+
+func parseInputMask(s string) (inputMask, error) {
+	switch s {
+	case "off":
+		return maskOff, nil
+	case "dots":
+		return maskDots, nil
+	case "blank":
+		return maskBlank, nil
+	default:
+		return maskOff, fmt.Errorf("unknown input mask %q (want dots, blank or off)", s)
+	}
+}
+
+// echoMode is the echo mode of the move input for the mask. Commands are
+// always shown as typed.
+func (k inputMask) echoMode() textinput.EchoMode {
+	switch k {
+	case maskDots:
+		return textinput.EchoPassword
+	case maskBlank:
+		return textinput.EchoNone
+	default:
+		return textinput.EchoNormal
+	}
+}
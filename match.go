@@ -0,0 +1,268 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// matchRestartDelay is how long a finished game of a match stays on screen
+// before the next one starts.
+const matchRestartDelay = 3 * time.Second
+
+// engineMatch is a match between two engines over a number of games, the
+// first engine playing White in the odd games and Black in the even ones.
+type engineMatch struct {
+	engines  [2]*uciEngine
+	names    [2]string
+	movetime time.Duration
+	games    int
+	played   int
+	score    [2]float64
+	thinking bool
+	paused   bool
+	step     bool // play one move while paused
+	over     bool
+}
+
+// startMatch starts the engines at the two paths for a match of games
+// games, each engine thinking movetime about every move unless the game
+// has clocks.
+func startMatch(paths []string, games int, movetime time.Duration) (*engineMatch, error) {
+	if len(paths) != 2 {
+		return nil, errors.New("a match needs two engines, as in -match stockfish,lc0")
+	}
+	if games < 1 {
+		return nil, errors.New("a match needs at least one game")
+	}
+	match := &engineMatch{games: games, movetime: movetime}
+	for i, path := range paths {
+		engine, err := startEngine(path)
+		if err != nil {
+			match.close()
+			return nil, fmt.Errorf("starting %s: %w", path, err)
+		}
+		match.engines[i] = engine
+		match.names[i] = filepath.Base(path)
+	}
+	if match.names[0] == match.names[1] {
+		match.names[0] += " (1)"
+		match.names[1] += " (2)"
+	}
+	return match, nil
+}
+
+func (e *engineMatch) close() {
+	for _, engine := range e.engines {
+		if engine != nil {
+			engine.close()
+		}
+	}
+}
+
+// whiteEngine returns the index of the engine playing White in the game
+// being played.
+func (e *engineMatch) whiteEngine() int {
+	return e.played % 2
+}
+
+// toMove returns the index of the engine to move in the position.
+func (e *engineMatch) toMove(pos *chess.Position) int {
+	if pos.Turn() == chess.White {
+		return e.whiteEngine()
+	}
+	return 1 - e.whiteEngine()
+}
+
+// record adds the result of a finished game to the score.
+func (e *engineMatch) record(outcome chess.Outcome) {
+	white := e.whiteEngine()
+	switch outcome {
+	case chess.WhiteWon:
+		e.score[white]++
+	case chess.BlackWon:
+		e.score[1-white]++
+	case chess.Draw:
+		e.score[0] += 0.5
+		e.score[1] += 0.5
+	}
+	e.played++
+	e.over = e.played == e.games
+}
+
+// points formats a score with halves, e.g. 2½.
+func points(score float64) string {
+	whole := int(score)
+	switch {
+	case score-float64(whole) == 0:
+		return fmt.Sprint(whole)
+	case whole == 0:
+		return "½"
+	}
+	return fmt.Sprintf("%d½", whole)
+}
+
+// title describes the match for the title bar, e.g.
+// "stockfish vs lc0 · game 3/4 · 1½–½".
+func (e *engineMatch) title() string {
+	game := min(e.played+1, e.games)
+	white := e.whiteEngine()
+	if e.over {
+		white = 0
+	}
+	text := fmt.Sprintf("%s vs %s · game %d/%d · %s–%s", e.names[white], e.names[1-white], game, e.games, points(e.score[white]), points(e.score[1-white]))
+	if e.paused {
+		text += " · paused"
+	}
+	return text
+}
+
+// matchMoveMsg carries the move an engine found in a game of the match.
+type matchMoveMsg struct {
+	match  *engineMatch
+	fen    string
+	result searchResult
+	err    error
+}
+
+// matchNextMsg starts the next game of the match.
+type matchNextMsg struct {
+	match *engineMatch
+}
+
+// matchTurn asks the engine to move for its move, unless one is already
+// thinking, the match is paused or the game over. It is the matchSource of
+// both sides, which Update asks after every message, so the match goes on
+// by itself; it waits while the game is set aside in another mode.
+func (m *model) matchTurn() tea.Cmd {
+	e := m.match
+	if e == nil || e.thinking || e.over || e.paused && !e.step || m.mode != modePlay || m.game.Outcome() != chess.NoOutcome {
+		return nil
+	}
+	e.step = false
+	e.thinking = true
+	pos := m.game.Position()
+	engine, fen, limit := e.engines[e.toMove(pos)], pos.String(), m.matchLimit()
+	return func() tea.Msg {
+		result, err := engine.searchWithin(pos, limit, 1, nil)
+		return matchMoveMsg{match: e, fen: fen, result: result, err: err}
+	}
+}
+
+// matchLimit bounds the search of the engine to move: by the clocks when
+// the match is played with a time control, by the time per move otherwise.
+func (m *model) matchLimit() searchLimit {
+	c := m.clock
+	if c == nil {
+		return searchLimit{movetime: m.match.movetime}
+	}
+	return searchLimit{
+		wtime: c.remaining[chess.White],
+		btime: c.remaining[chess.Black],
+		winc:  c.controls[chess.White].increment,
+		binc:  c.controls[chess.Black].increment,
+	}
+}
+
+// handleMatchMove plays the engine's move, and scores the game once it is
+// over and schedules the next one. Draws by repetition or the fifty-move
+// rule are claimed right away, as engines would otherwise shuffle on.
+func (m *model) handleMatchMove(msg matchMoveMsg) tea.Cmd {
+	e := m.match
+	if msg.match != e {
+		return nil
+	}
+	e.thinking = false
+	if msg.err != nil {
+		e.paused = true
+		m.error = fmt.Errorf("%s: %w", e.names[e.toMove(m.game.Position())], msg.err)
+		return nil
+	}
+	pos := m.game.Position()
+	// a game lost on time ends with the engine still thinking
+	if m.mode != modePlay || pos.String() != msg.fen || m.game.Outcome() != chess.NoOutcome {
+		return nil
+	}
+	mv, err := chess.UCINotation{}.Decode(pos, msg.result.bestMove)
+	if err == nil {
+		err = m.game.Move(mv)
+	}
+	if err != nil {
+		e.paused = true
+		m.error = fmt.Errorf("%s played %q: %w", e.names[e.toMove(pos)], msg.result.bestMove, err)
+		return nil
+	}
+	for _, method := range m.game.EligibleDraws() {
+		if method == chess.ThreefoldRepetition || method == chess.FiftyMoveRule {
+			m.game.Draw(method)
+			break
+		}
+	}
+	cmd := m.moveApplied()
+	if m.game.Outcome() == chess.NoOutcome {
+		return cmd
+	}
+	return tea.Batch(cmd, m.matchGameOver())
+}
+
+// matchGameOver scores the game of the match that just ended, on the board
+// or on time, and schedules the next one.
+func (m *model) matchGameOver() tea.Cmd {
+	e := m.match
+	game := m.liveGame()
+	e.record(game.Outcome())
+	if e.over {
+		m.status = fmt.Sprintf("Match over: %s %s, %s %s", e.names[0], points(e.score[0]), e.names[1], points(e.score[1]))
+		return nil
+	}
+	m.status = resultString(game)
+	return tea.Tick(matchRestartDelay, func(time.Time) tea.Msg {
+		return matchNextMsg{match: e}
+	})
+}
+
+// handleMatchNext sets up the next game of the match, the engines taking
+// the other colors.
+func (m *model) handleMatchNext(msg matchNextMsg) tea.Cmd {
+	if msg.match != m.match {
+		return nil
+	}
+	return m.startGame(chess.NewGame())
+}
+
+// matchKey handles the keys of a match: space pauses and resumes, s plays
+// a single move while paused. Other keys that would type a move are
+// ignored in play mode, as no moves can be played by hand. It reports
+// whether the key was used up.
+func (m *model) matchKey(msg tea.KeyMsg) bool {
+	e := m.match
+	switch msg.String() {
+	case " ":
+		e.paused = !e.paused
+		return true
+	case "s":
+		e.step = e.paused
+		return true
+	case ":", "?":
+		return false
+	case "enter", "backspace":
+		return m.mode == modePlay
+	}
+	return m.mode == modePlay && msg.Type == tea.KeyRunes
+}
+
+// parseMatchEngines splits the -match flag into engine paths.
+func parseMatchEngines(s string) []string {
+	var paths []string
+	for _, path := range strings.Split(s, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
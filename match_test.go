@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+func TestMatchLostOnTime(t *testing.T) {
+	// the engines think until they are stopped
+	think := func(command string) []string {
+		if command == "stop" {
+			return []string{"bestmove e2e4"}
+		}
+		return nil
+	}
+	white, black := newFakeEngine(t, think), newFakeEngine(t, think)
+	u := newUIModel(t)
+	u.m.match = &engineMatch{engines: [2]*uciEngine{white.uciEngine, black.uciEngine}, names: [2]string{"white", "black"}, games: 2}
+	tc := timeControl{initial: time.Minute}
+	u.m.clock = newChessClock(tc, tc)
+
+	search := u.m.matchTurn()
+	done := make(chan tea.Msg)
+	go func() { done <- search() }()
+	white.expect(t, "go wtime")
+
+	u.send(clockTickMsg(u.m.clock.lastTick.Add(2 * time.Minute)))
+	if got := u.m.game.Outcome(); got != chess.BlackWon {
+		t.Fatalf("outcome after White's flag = %s, want 0-1", got)
+	}
+	if e := u.m.match; e.played != 1 || e.score != [2]float64{0, 1} {
+		t.Errorf("match played %d with score %v, want 1 game won by black", e.played, e.score)
+	}
+
+	// the move the engine comes up with once stopped is dropped
+	white.expect(t, "stop")
+	u.send(<-done)
+	if n := len(u.m.game.Moves()); n != 0 {
+		t.Errorf("%d moves added to the game lost on time", n)
+	}
+	if u.m.match.thinking {
+		t.Error("the match still waits for a move")
+	}
+	if !strings.Contains(u.m.status, "Black wins") {
+		t.Errorf("status %q doesn't give the result", u.m.status)
+	}
+}
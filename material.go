@@ -0,0 +1,89 @@
+package main
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+var (
+	checkedKingStyle = lipgloss.NewStyle().Background(lipgloss.Color("#E07B67"))
+	matedKingStyle   = lipgloss.NewStyle().Background(lipgloss.Color("#C0392B"))
+)
+
+// insufficientMaterial reports whether neither side can possibly checkmate:
+// bare kings, a single minor piece, or only bishops that all stand on
+// squares of one color.
+func insufficientMaterial(board *chess.Board) bool {
+	var minors, knights int
+	bishopColors := map[bool]int{} // by whether the square is light
+	for sq, p := range board.SquareMap() {
+		switch p.Type() {
+		case chess.King:
+		case chess.Knight:
+			minors++
+			knights++
+		case chess.Bishop:
+			minors++
+			bishopColors[(int(sq.File())+int(sq.Rank()))%2 == 1]++
+		default:
+			// any pawn, rook or queen can still mate
+			return false
+		}
+	}
+	if minors <= 1 {
+		return true
+	}
+	return knights == 0 && len(bishopColors) == 1
+}
+
+// resultString describes how the game ended.
+func resultString(game *chess.Game) string {
+	if verdict := positionVerdict(game.Position()); verdict != "" {
+		return verdict
+	}
+	result := outcomeString(game.Outcome())
+	// Games loaded from PGN don't get automatic draws, so the board is
+	// checked as well.
+	if game.Outcome() == chess.Draw && (game.Method() == chess.InsufficientMaterial || insufficientMaterial(game.Position().Board())) {
+		result = "Draw — insufficient material"
+	}
+	return result
+}
+
+// positionVerdict tells checkmate from stalemate when the side to move has
+// no legal moves, whether or not the game has been ended yet. It returns ""
+// otherwise.
+func positionVerdict(pos *chess.Position) string {
+	if len(pos.ValidMoves()) > 0 {
+		return ""
+	}
+	if pos.Status() == chess.Checkmate {
+		return "Checkmate — " + pos.Turn().Other().Name() + " wins!"
+	}
+	return "Stalemate — draw"
+}
+
+// checkHighlight marks the king of the side in check on the board, darker
+// when it is checkmated.
+func (m model) checkHighlight() map[chess.Square]lipgloss.Style {
+	pos := m.displayedPosition()
+	style := checkedKingStyle
+	if pos.Status() == chess.Checkmate {
+		style = matedKingStyle
+	}
+	attacked := attackCounts(pos.Board(), pos.Turn().Other())
+	for sq, p := range pos.Board().SquareMap() {
+		if p == chess.NewPiece(chess.King, pos.Turn()) && attacked[sq] > 0 {
+			return map[chess.Square]lipgloss.Style{sq: style}
+		}
+	}
+	return nil
+}
+
+// claimDeadPosition ends the game as a draw when neither side can mate any
+// more, which the chess package doesn't check for games loaded from PGN.
+func claimDeadPosition(game *chess.Game) {
+	if game.Outcome() == chess.NoOutcome && insufficientMaterial(game.Position().Board()) {
+		game.Draw(chess.DrawOffer)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// swapCase turns White's pieces into Black's and the other way round.
+func swapCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+		return unicode.ToUpper(r)
+	}, s)
+}
+
+// mirrorFEN returns the color-reversed position: the board reflected
+// across the middle with the colors of the pieces swapped, the other side
+// to move, and the castling rights and en passant square following. Every
+// evaluation of the position is negated in the mirrored one, which makes it
+// a way to study a position from the other side.
+func mirrorFEN(s string) (string, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 6 {
+		return "", fmt.Errorf("invalid FEN %q", s)
+	}
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != 8 {
+		return "", fmt.Errorf("invalid FEN %q", s)
+	}
+	slices.Reverse(ranks)
+	fields[0] = swapCase(strings.Join(ranks, "/"))
+
+	switch fields[1] {
+	case "w":
+		fields[1] = "b"
+	case "b":
+		fields[1] = "w"
+	default:
+		return "", fmt.Errorf("invalid side to move %q", fields[1])
+	}
+
+	if fields[2] != "-" {
+		var castling strings.Builder
+		swapped := swapCase(fields[2])
+		for _, r := range "KQkq" {
+			if strings.ContainsRune(swapped, r) {
+				castling.WriteRune(r)
+			}
+		}
+		fields[2] = castling.String()
+	}
+
+	if fields[3] != "-" {
+		sq, ok := parseSquare(fields[3])
+		if !ok {
+			return "", fmt.Errorf("invalid en passant square %q", fields[3])
+		}
+		fields[3] = chess.NewSquare(sq.File(), 7-sq.Rank()).String()
+	}
+
+	mirrored := strings.Join(fields, " ")
+	problems, err := fenProblems(mirrored)
+	if err != nil {
+		return "", err
+	}
+	if len(problems) > 0 {
+		return "", errors.New("the mirrored position is impossible: " + strings.Join(problems, "; "))
+	}
+	return mirrored, nil
+}
+
+// mirrorPosition sets up the color-reversed position of the board in
+// analysis mode, where the game is set aside until f2 returns to it.
+func (m *model) mirrorPosition() (tea.Cmd, error) {
+	mirrored, err := mirrorFEN(m.displayedPosition().String())
+	if err != nil {
+		return nil, err
+	}
+	fen, err := chess.FEN(mirrored)
+	if err != nil {
+		return nil, err
+	}
+	m.setMode(modeAnalysis)
+	m.game = chess.NewGame(fen)
+	m.setHistory(nil)
+	m.positionChanged()
+	m.updateHistoryViewport()
+	m.status = "Mirrored position, f2 returns to the game"
+	return nil, nil
+}
+
+// passTurnFEN returns the position with the other side to move, as if the
+// side to move had passed. The en passant square is dropped, as the pawn
+// can no longer be taken.
+func passTurnFEN(s string) (string, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 6 {
+		return "", fmt.Errorf("invalid FEN %q", s)
+	}
+	switch fields[1] {
+	case "w":
+		fields[1] = "b"
+	case "b":
+		fields[1] = "w"
+		if n, err := strconv.Atoi(fields[5]); err == nil {
+			fields[5] = strconv.Itoa(n + 1)
+		}
+	default:
+		return "", fmt.Errorf("invalid side to move %q", fields[1])
+	}
+	fields[3] = "-"
+
+	passed := strings.Join(fields, " ")
+	problems, err := fenProblems(passed)
+	if err != nil {
+		return "", err
+	}
+	if len(problems) > 0 {
+		return "", errors.New("the other side can't move here: " + strings.Join(problems, "; "))
+	}
+	return passed, nil
+}
+
+// passTurn gives the move to the other side: in the editor by switching the
+// side to move, warning if that makes the position impossible, and in
+// analysis by setting up the position shown with the other side to move.
+func (m *model) passTurn() (tea.Cmd, error) {
+	switch m.mode {
+	case modeEdit:
+		m.editTurn = m.editTurn.Other()
+		m.status = m.editTurn.Name() + " to move"
+		if problems, err := fenProblems(m.editFEN()); err == nil && len(problems) > 0 {
+			return nil, errors.New("impossible position: " + strings.Join(problems, "; "))
+		}
+		return nil, nil
+	case modeAnalysis:
+	default:
+		return nil, errors.New("the turn can be passed in analysis (f4) or the editor (f5)")
+	}
+	passed, err := passTurnFEN(m.displayedPosition().String())
+	if err != nil {
+		return nil, err
+	}
+	fen, err := chess.FEN(passed)
+	if err != nil {
+		return nil, err
+	}
+	m.game = chess.NewGame(fen)
+	m.setHistory(nil)
+	m.positionChanged()
+	m.updateHistoryViewport()
+	m.status = m.game.Position().Turn().Name() + " to move, f2 returns to the game"
+	return nil, nil
+}
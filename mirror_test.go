@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMirrorFEN(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want string
+	}{
+		{"start", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1"},
+		{"en passant", "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", "rnbqkbnr/pppp1ppp/8/4p3/8/8/PPPPPPPP/RNBQKBNR w KQkq e6 0 1"},
+		{"some castling rights", "r3k2r/8/8/8/8/8/8/R3K2R w Kq - 4 20", "r3k2r/8/8/8/8/8/8/R3K2R b Qk - 4 20"},
+		{"no castling rights", "4k3/8/8/3q4/8/8/8/4K3 w - - 0 40", "4k3/8/8/8/3Q4/8/8/4K3 b - - 0 40"},
+		{"lopsided", "6k1/5ppp/8/8/8/8/1B6/K7 b - - 10 50", "k7/1b6/8/8/8/8/5PPP/6K1 w - - 10 50"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mirrorFEN(tt.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("mirrorFEN(%s)\n = %s\nwant %s", tt.fen, got, tt.want)
+			}
+			// mirrored twice is the position itself
+			back, err := mirrorFEN(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if back != tt.fen {
+				t.Errorf("mirrored back to %s, want %s", back, tt.fen)
+			}
+		})
+	}
+}
+
+func TestMirrorFENErrors(t *testing.T) {
+	tests := []struct {
+		fen string
+		err string
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -", "invalid FEN"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP w KQkq - 0 1", "invalid FEN"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1", "invalid side to move"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq z9 0 1", "invalid en passant square"},
+		{"8/8/8/8/8/8/8/K7 w - - 0 1", "the mirrored position is impossible"},
+	}
+	for _, tt := range tests {
+		if _, err := mirrorFEN(tt.fen); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("mirrorFEN(%s) error %v, want %q", tt.fen, err, tt.err)
+		}
+	}
+}
+
+func TestMirrorPosition(t *testing.T) {
+	u := newUIModel(t)
+	u.enter("e4")
+	u.command("mirror")
+	if u.m.mode != modeAnalysis {
+		t.Fatalf("mode %v after :mirror, want analysis", u.m.mode)
+	}
+	if got, want := u.m.game.Position().String(), "rnbqkbnr/pppp1ppp/8/4p3/8/8/PPPPPPPP/RNBQKBNR w KQkq e6 0 1"; got != want {
+		t.Errorf("mirrored to %s, want %s", got, want)
+	}
+	// the game is set aside, not changed
+	if got := moveList(u.m.liveGame()); got != "e4" {
+		t.Errorf("game has the moves %q, want e4", got)
+	}
+}
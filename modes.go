@@ -0,0 +1,279 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// mode is what the user is currently doing with the board.
+type mode int
+
+const (
+	// modePlay is the normal game.
+	modePlay mode = iota
+	// modeReview steps through the positions of the game.
+	modeReview
+	// modeAnalysis plays moves on a scratch copy of the game.
+	modeAnalysis
+	// modeEdit sets up a position piece by piece.
+	modeEdit
+)
+
+func (md mode) String() string {
+	switch md {
+	case modeReview:
+		return "Review"
+	case modeAnalysis:
+		return "Analysis"
+	case modeEdit:
+		return "Edit"
+	default:
+		return "Play"
+	}
+}
+
+const (
+	analysisPrompt = "Analyze: "
+	editPrompt     = "Place: "
+	editCharLimit  = 5
+)
+
+// modeHints are shown under the board outside of play mode.
+var modeHints = map[mode]string{
+	modeReview:   "←/→ step through the game • home/end or g/G to the start/end • f skips only moves • / finds moves, n/N the next/previous • b plays on from here • x copies the PGN from here • tab/f2 back to play",
+	modeAnalysis: "moves here don't affect the game • ctrl+s passes the turn • f2 back to play",
+	modeEdit:     "Qd4 white queen • qd4 black queen • -d4 clear • turn or ctrl+s • clear • paste a FEN\nenter on empty input starts from this position • esc cancels",
+}
+
+// liveGame is the game being played, even while a scratch copy is shown.
+func (s *session) liveGame() *chess.Game {
+	if s.stashedGame != nil {
+		return s.stashedGame
+	}
+	return s.game
+}
+
+// setMode switches to the given mode, setting aside the game for the modes
+// that work on a copy of it.
+func (m *model) setMode(next mode) {
+	if next == m.mode {
+		return
+	}
+	if m.stashedGame != nil {
+		m.game, m.history, m.historyDrop = m.stashedGame, m.stashedHistory, m.stashedDrop
+		m.stashedGame, m.stashedHistory = nil, nil
+		m.positionChanged()
+		m.updateHistoryViewport()
+	}
+
+	m.mode = next
+	m.redo = nil
+	m.pendingMove = nil
+	m.stagedMove = nil
+	m.confirmResign = false
+	m.error = nil
+	m.resetInput()
+
+	switch next {
+	case modeReview:
+		m.viewPly = len(m.game.Moves())
+	case modeAnalysis:
+		m.stashedGame, m.stashedHistory, m.stashedDrop = m.game, m.history, m.historyDrop
+		m.game = m.game.Clone()
+		m.history = append([]string(nil), m.history...)
+	case modeEdit:
+		m.stashedGame, m.stashedHistory, m.stashedDrop = m.game, m.history, m.historyDrop
+		m.editBoard = m.game.Position().Board().SquareMap()
+		m.editTurn = m.game.Position().Turn()
+	}
+	if m.historyLimit > 0 {
+		// reviewing lists every move
+		m.updateHistoryViewport()
+	}
+}
+
+// resetInput clears the input and sets up its prompt for the current mode.
+func (m *model) resetInput() {
+	m.textInput.Reset()
+	m.textInput.Prompt = movePrompt
+	switch m.mode {
+	case modeAnalysis:
+		m.textInput.Prompt = analysisPrompt
+	case modeEdit:
+		m.textInput.Prompt = editPrompt
+	}
+	m.fitInput(false)
+}
+
+func (m model) updateReviewMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "left":
+		m.viewPly = max(m.viewPly-1, 0)
+	case "right":
+		m.viewPly = min(m.viewPly+1, len(m.game.Moves()))
+	case "home", "g":
+		m.viewPly = 0
+		m.viewport.GotoTop()
+	case "end", "G":
+		m.viewPly = len(m.game.Moves())
+		m.viewport.GotoBottom()
+	case "f":
+		m.skipForced()
+	case "x":
+		m.error = m.copyPGNFrom(m.viewPly)
+	case "b":
+		cmd, err := m.branchHere()
+		m.error = err
+		return m, cmd
+	case "delete", "backspace":
+		m.clearAnnotation()
+	case "/":
+		m.enterCommandMode()
+		m.textInput.SetValue("find ")
+		m.textInput.CursorEnd()
+	case "n", "N":
+		step := 1
+		if msg.String() == "N" {
+			step = -1
+		}
+		m.error = m.nextHistoryMatch(step)
+	}
+	return m, nil
+}
+
+// editPosition is the position set up in the editor.
+func (m model) editPosition() (*chess.Position, error) {
+	fen, err := chess.FEN(m.editFEN())
+	if err != nil {
+		return nil, err
+	}
+	return chess.NewGame(fen).Position(), nil
+}
+
+// editFEN builds a FEN from the edited board, granting the castling rights
+// that the king and rook placement allows.
+func (m model) editFEN() string {
+	board := chess.NewBoard(m.editBoard)
+
+	castling := ""
+	homes := []struct {
+		right      string
+		king, rook chess.Square
+		piece      chess.Color
+	}{
+		{"K", chess.E1, chess.H1, chess.White},
+		{"Q", chess.E1, chess.A1, chess.White},
+		{"k", chess.E8, chess.H8, chess.Black},
+		{"q", chess.E8, chess.A8, chess.Black},
+	}
+	for _, h := range homes {
+		if board.Piece(h.king) == chess.NewPiece(chess.King, h.piece) && board.Piece(h.rook) == chess.NewPiece(chess.Rook, h.piece) {
+			castling += h.right
+		}
+	}
+	if castling == "" {
+		castling = "-"
+	}
+
+	return fmt.Sprintf("%s %s %s - 0 1", board, m.editTurn, castling)
+}
+
+// applyEdit handles one line of editor input.
+func (m *model) applyEdit(input string) (tea.Cmd, error) {
+	input = strings.TrimSpace(input)
+	switch {
+	case input == "":
+		return m.finishEdit()
+	case input == "turn":
+		m.editTurn = m.editTurn.Other()
+		return nil, nil
+	case input == "clear":
+		m.editBoard = map[chess.Square]chess.Piece{}
+		return nil, nil
+	case isFEN(input):
+		if err := checkFEN(input); err != nil {
+			return nil, err
+		}
+		fen, err := chess.FEN(strings.Join(strings.Fields(input), " "))
+		if err != nil {
+			return nil, err
+		}
+		pos := chess.NewGame(fen).Position()
+		m.editBoard = pos.Board().SquareMap()
+		m.editTurn = pos.Turn()
+		return nil, nil
+	}
+
+	sq, ok := parseSquare(input[1:])
+	if len(input) != 3 || !ok {
+		return nil, fmt.Errorf("can't understand %q", input)
+	}
+	if input[0] == '-' {
+		delete(m.editBoard, sq)
+		return nil, nil
+	}
+	piece, ok := pieceFromFENChar(input[0])
+	if !ok {
+		return nil, fmt.Errorf("unknown piece %q", input[0])
+	}
+	m.editBoard[sq] = piece
+	return nil, nil
+}
+
+// finishEdit starts a new game from the edited position.
+func (m *model) finishEdit() (tea.Cmd, error) {
+	if err := checkKings(m.editBoard); err != nil {
+		return nil, err
+	}
+	fen, err := chess.FEN(m.editFEN())
+	if err != nil {
+		return nil, err
+	}
+
+	m.stashedGame, m.stashedHistory = nil, nil
+	return m.startGame(chess.NewGame(fen)), nil
+}
+
+// checkKings rejects boards the move generator can't handle: each side
+// needs exactly one king.
+func checkKings(board map[chess.Square]chess.Piece) error {
+	kings := map[chess.Piece]int{}
+	for _, p := range board {
+		if p.Type() == chess.King {
+			kings[p]++
+		}
+	}
+	if kings[chess.WhiteKing] != 1 || kings[chess.BlackKing] != 1 {
+		return errors.New("each side needs exactly one king")
+	}
+	return nil
+}
+
+func parseSquare(s string) (chess.Square, bool) {
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'h' || s[1] < '1' || s[1] > '8' {
+		return chess.NoSquare, false
+	}
+	return chess.NewSquare(chess.File(s[0]-'a'), chess.Rank(s[1]-'1')), true
+}
+
+// pieceFromFENChar maps a FEN piece letter (uppercase for White) to a piece.
+func pieceFromFENChar(c byte) (chess.Piece, bool) {
+	types := map[byte]chess.PieceType{
+		'k': chess.King, 'q': chess.Queen, 'r': chess.Rook,
+		'b': chess.Bishop, 'n': chess.Knight, 'p': chess.Pawn,
+	}
+	color := chess.Black
+	if c >= 'A' && c <= 'Z' {
+		color = chess.White
+		c += 'a' - 'A'
+	}
+	t, ok := types[c]
+	if !ok {
+		return chess.NoPiece, false
+	}
+	return chess.NewPiece(t, color), true
+}
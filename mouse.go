@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+var (
+	dragSourceStyle = lipgloss.NewStyle().Background(lipgloss.Color("#7FA650"))
+	dragTargetStyle = lipgloss.NewStyle().Background(lipgloss.Color("#A9C76A"))
+)
+
+// bodyWidth is the width of the board together with the info blocks and
+// the side panels, or the analysis panel in the analysis layout.
+func (m model) bodyWidth() int {
+	boardWidth, _ := m.boardSize()
+	if m.analysisLayout {
+		return boardWidth + historyGap + m.analysisPanelWidth()
+	}
+	return boardWidth + 2*m.infoPanelWidth() + m.historyPanelWidth() + m.legalMovesPanelWidth()
+}
+
+// boardOrigin returns the screen cell of the top-left corner of the board,
+// following the layout of View.
+func (m model) boardOrigin() (x, y int) {
+	x = docStyle.GetMarginLeft() + max((m.width-m.bodyWidth())/2, 0) + m.infoPanelWidth()
+	// the title and a blank line precede the board
+	y = docStyle.GetMarginTop() + 2
+	return x, y
+}
+
+// boardContentOrigin returns the screen cell of the top-left corner of the
+// board inside its frame.
+func (m model) boardContentOrigin() (x, y int) {
+	x, y = m.boardOrigin()
+	frame := m.boardFrame()
+	return x + frame.GetBorderLeftSize(), y + frame.GetBorderTopSize()
+}
+
+// screenSquare maps a mouse position to the square under it.
+func (m model) screenSquare(x, y int) (chess.Square, bool) {
+	originX, originY := m.boardContentOrigin()
+	return m.boardOptions().squareAt(x-originX, y-originY)
+}
+
+// handleMouse implements press-and-drag move entry, and drawing arrows and
+// circles with the right button.
+func (m *model) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	sq, onBoard := m.screenSquare(msg.X, msg.Y)
+	m.hovered, m.hovering = sq, onBoard && !m.modalActive()
+	if m.handleAnnotationMouse(msg) {
+		return nil
+	}
+	if msg.Button != tea.MouseButtonLeft && msg.Action != tea.MouseActionRelease && msg.Action != tea.MouseActionMotion {
+		return nil
+	}
+
+	switch msg.Action {
+	case tea.MouseActionPress:
+		m.dragging = false
+		if ply, ok := m.historyPlyAt(msg.X, msg.Y); ok && !m.showHelp && (m.mode == modePlay || m.mode == modeReview) {
+			m.setMode(modeReview)
+			m.viewPly = ply
+			return nil
+		}
+		if !onBoard || m.mode == modeReview || m.mode == modeEdit || m.game.Outcome() != chess.NoOutcome {
+			return nil
+		}
+		piece := m.game.Position().Board().Piece(sq)
+		if piece == chess.NoPiece || piece.Color() != m.game.Position().Turn() {
+			return nil
+		}
+		m.dragging = true
+		m.dragFrom = sq
+		m.dragTarget = sq
+	case tea.MouseActionMotion:
+		if m.dragging && onBoard {
+			m.dragTarget = sq
+		}
+	case tea.MouseActionRelease:
+		if !m.dragging {
+			return nil
+		}
+		m.dragging = false
+		if !onBoard || sq == m.dragFrom {
+			return nil
+		}
+		if mv := findMove(m.validMoves, m.dragFrom, sq); mv != nil {
+			return m.submitMove(mv)
+		}
+	}
+	return nil
+}
+
+// findMove returns the legal move between two squares, promoting to a queen
+// when several promotions are possible.
+func findMove(moves []*chess.Move, from, to chess.Square) *chess.Move {
+	for _, mv := range moves {
+		if mv.S1() == from && mv.S2() == to && (mv.Promo() == chess.NoPieceType || mv.Promo() == chess.Queen) {
+			return mv
+		}
+	}
+	return nil
+}
+
+// moveHighlights marks the squares of the move being dragged, staged or
+// picked with a piece letter, over a hinted move, a king in check, the
+// engine's candidate moves, the differences from the snapshot when
+// comparing and the last move when reviewing with focus. The highlights
+// -highlight draws other than as fills are left to decorations.
+func (m model) moveHighlights() map[chess.Square]lipgloss.Style {
+	var hl map[chess.Square]lipgloss.Style
+	for _, layer := range []map[chess.Square]lipgloss.Style{
+		m.heatmapHighlights(),
+		m.filled(highlightLastMove, m.lastMoveHighlights()),
+		m.snapshotHighlights(),
+		m.candidateHighlights(),
+		m.filled(highlightCheck, m.checkHighlight()),
+		m.hintHighlights(),
+		m.filled(highlightSelection, m.selectionHighlights()),
+	} {
+		if layer == nil {
+			continue
+		}
+		if hl == nil {
+			hl = map[chess.Square]lipgloss.Style{}
+		}
+		maps.Copy(hl, layer)
+	}
+	return hl
+}
+
+// selectionHighlights marks the squares of the move being dragged, staged
+// or picked with a piece letter.
+func (m model) selectionHighlights() map[chess.Square]lipgloss.Style {
+	if move := m.stagedHighlights(); move != nil {
+		return move
+	}
+	if move := m.dragHighlights(); move != nil {
+		return move
+	}
+	return m.cycledHighlights()
+}
+
+// selectedSquare returns the square of the piece being dragged or whose
+// move is staged or picked, if any.
+func (m model) selectedSquare() (chess.Square, bool) {
+	switch {
+	case m.dragging:
+		return m.dragFrom, true
+	case m.stagedMove != nil:
+		return m.stagedMove.S1(), true
+	case m.cycledMove != nil:
+		return m.cycledMove.S1(), true
+	}
+	return chess.NoSquare, false
+}
+
+// selectionStatus describes the selected piece and how many moves it has,
+// e.g. "Selected: White Knight on f3 — 5 moves", or returns "" when no
+// piece is selected.
+func (m model) selectionStatus() string {
+	sq, ok := m.selectedSquare()
+	if !ok {
+		return ""
+	}
+	piece := m.game.Position().Board().Piece(sq)
+	if piece == chess.NoPiece {
+		return ""
+	}
+	n := 0
+	for _, mv := range m.validMoves {
+		if mv.S1() == sq {
+			n++
+		}
+	}
+	moves := fmt.Sprintf("%d moves", n)
+	switch n {
+	case 0:
+		moves = "can't move"
+	case 1:
+		moves = "1 move"
+	}
+	return fmt.Sprintf("Selected: %s %s on %s — %s", piece.Color().Name(), pieceName(piece.Type()), sq, moves)
+}
+
+func (m model) dragHighlights() map[chess.Square]lipgloss.Style {
+	if !m.dragging {
+		return nil
+	}
+	return map[chess.Square]lipgloss.Style{
+		m.dragFrom:   dragSourceStyle,
+		m.dragTarget: dragTargetStyle,
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+func TestSquareAt(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    boardOptions
+		x, y    int
+		want    chess.Square
+		onBoard bool
+	}{
+		{"top-left", boardOptions{scale: scaleNormal, labels: labelsNone}, 0, 0, chess.A8, true},
+		{"top-left flipped", boardOptions{scale: scaleNormal, labels: labelsNone, flipped: true}, 0, 0, chess.H1, true},
+		{"bottom-right", boardOptions{scale: scaleNormal, labels: labelsNone}, 23, 7, chess.H1, true},
+		{"bottom-right flipped", boardOptions{scale: scaleNormal, labels: labelsNone, flipped: true}, 23, 7, chess.A8, true},
+		{"inside e2", boardOptions{scale: scaleNormal, labels: labelsNone}, 14, 6, chess.E2, true},
+		{"inside e2 flipped", boardOptions{scale: scaleNormal, labels: labelsNone, flipped: true}, 9, 1, chess.E2, true},
+		{"second line of a large square", boardOptions{scale: scaleLarge, labels: labelsNone}, 4, 15, chess.A1, true},
+		{"second line of a large square flipped", boardOptions{scale: scaleLarge, labels: labelsNone, flipped: true}, 4, 15, chess.H8, true},
+		{"compact", boardOptions{scale: scaleCompact, labels: labelsNone}, 4, 3, chess.E5, true},
+		{"compact flipped", boardOptions{scale: scaleCompact, labels: labelsNone, flipped: true}, 4, 3, chess.D4, true},
+		{"after the rank labels", boardOptions{scale: scaleNormal, labels: labelsLeftBottom}, 2, 0, chess.A8, true},
+		{"after the file labels", boardOptions{scale: scaleNormal, labels: labelsAll, flipped: true}, 2, 1, chess.H1, true},
+		{"on the rank labels", boardOptions{scale: scaleNormal, labels: labelsLeftBottom}, 1, 0, chess.NoSquare, false},
+		{"on the file labels", boardOptions{scale: scaleNormal, labels: labelsAll}, 2, 0, chess.NoSquare, false},
+		{"right of the board", boardOptions{scale: scaleNormal, labels: labelsNone}, 24, 0, chess.NoSquare, false},
+		{"below the board", boardOptions{scale: scaleNormal, labels: labelsNone}, 0, 8, chess.NoSquare, false},
+		{"left of the board", boardOptions{scale: scaleNormal, labels: labelsNone}, -1, 0, chess.NoSquare, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sq, ok := tt.opts.squareAt(tt.x, tt.y)
+			if sq != tt.want || ok != tt.onBoard {
+				t.Errorf("squareAt(%d, %d) = %s, %t, want %s, %t", tt.x, tt.y, sq, ok, tt.want, tt.onBoard)
+			}
+		})
+	}
+}
+
+// squareCell returns the screen cell in the middle of the first line of sq,
+// and the width of the square.
+func (u *uiModel) squareCell(sq chess.Square) (x, y, width int) {
+	opts := u.m.boardOptions()
+	width, height := opts.scale.squareSize()
+	originX, originY := u.m.boardContentOrigin()
+	offX, offY := opts.boardOffset()
+	col, row := int(sq.File()), 7-int(sq.Rank())
+	if opts.flipped {
+		col, row = 7-col, 7-row
+	}
+	return originX + offX + col*width + width/2, originY + offY + row*height, width
+}
+
+func TestMouseMapsSquares(t *testing.T) {
+	for _, flipped := range []bool{false, true} {
+		u := newUIModel(t)
+		u.m.scale = scaleLarge
+		u.m.flipped = flipped
+		lines := strings.Split(u.view(), "\n")
+		opts := u.m.boardOptions()
+		for sq := chess.A1; sq <= chess.H8; sq++ {
+			x, y, width := u.squareCell(sq)
+			// the piece of the start position is drawn where the mouse
+			// finds the square
+			if piece := chess.StartingPosition().Board().Piece(sq); piece != chess.NoPiece {
+				cells := []rune(lines[y])
+				if got := string(cells[x-width/2 : x-width/2+width]); !strings.Contains(got, opts.pieceSymbol(piece)) {
+					t.Errorf("flipped %t: %s drawn as %q, want %s", flipped, sq, got, opts.pieceSymbol(piece))
+				}
+			}
+
+			u.send(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionMotion})
+			if !u.m.hovering || u.m.hovered != sq {
+				t.Errorf("flipped %t: cursor over %s hovers %s (%t)", flipped, sq, u.m.hovered, u.m.hovering)
+			}
+		}
+	}
+}
+
+func TestMouseDragsMove(t *testing.T) {
+	for _, flipped := range []bool{false, true} {
+		u := newUIModel(t)
+		u.m.flipped = flipped
+		x, y, _ := u.squareCell(chess.E2)
+		u.send(tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+		if !u.m.dragging || u.m.dragFrom != chess.E2 {
+			t.Fatalf("flipped %t: pressing on e2 drags from %s (%t)", flipped, u.m.dragFrom, u.m.dragging)
+		}
+		x, y, _ = u.squareCell(chess.E4)
+		u.send(tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionMotion})
+		if u.m.dragTarget != chess.E4 {
+			t.Errorf("flipped %t: dragging over e4 targets %s", flipped, u.m.dragTarget)
+		}
+		u.send(tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionRelease})
+		if got := moveList(u.m.game); got != "e4" {
+			t.Errorf("flipped %t: dragging e2 to e4 played %q", flipped, got)
+		}
+	}
+}
+
+func TestMouseOffBoard(t *testing.T) {
+	u := newUIModel(t)
+	x, y := u.m.boardOrigin()
+	u.send(tea.MouseMsg{X: x - 1, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	if u.m.dragging || u.m.hovering {
+		t.Error("pressing left of the board picks a square")
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// moveSource is whoever makes the moves of one side of the game being
+// played. Update asks the source of the side to move for its move after
+// every message, so a source has to tell for itself whether it is ready:
+// one already thinking or waiting for its turn returns nil.
+type moveSource interface {
+	// play makes the move of the side to move, or returns a command that
+	// makes it once it is known.
+	play(m *model) tea.Cmd
+}
+
+// humanSource is the user at the keyboard or mouse, whose moves come in
+// through the input rather than being asked for. Meanwhile the engine may
+// ponder on the move it expects.
+type humanSource struct{}
+
+func (humanSource) play(m *model) tea.Cmd { return m.startPondering() }
+
+// engineSource is the UCI engine playing against the user, or both sides in
+// demo mode.
+type engineSource struct{}
+
+func (engineSource) play(m *model) tea.Cmd {
+	if m.engine == nil || m.engineThinking || m.ponderHit() {
+		return nil
+	}
+	m.stopPondering()
+	m.engineThinking = true
+	m.engineProgress = nil
+	pos := m.game.Position()
+	engine, fen := m.engine, pos.String()
+	progress := make(chan searchProgress, 1)
+	return tea.Batch(func() tea.Msg {
+		result, err := engine.searchReporting(pos, playMovetime, 1, func(p searchProgress) {
+			// a report the UI hasn't picked up yet is not worth waiting for
+			select {
+			case progress <- p:
+			default:
+			}
+		})
+		close(progress)
+		return engineMoveMsg{engine: engine, fen: fen, result: result, err: err}
+	}, listenProgress(engine, progress))
+}
+
+// matchSource is the engines of a match, which play both sides.
+type matchSource struct{}
+
+func (matchSource) play(m *model) tea.Cmd { return m.matchTurn() }
+
+// bookSource is the repertoire being drilled, which answers the user with
+// its first move for the line until the line runs out.
+type bookSource struct{}
+
+func (bookSource) play(m *model) tea.Cmd {
+	// the book is looked up by the moves from the start of the game
+	if m.historyDrop > 0 {
+		return nil
+	}
+	book := m.drill.bookMoves(m.history)
+	if len(book) == 0 {
+		return nil
+	}
+	mv, err := chess.AlgebraicNotation{}.Decode(m.game.Position(), book[0])
+	if err == nil {
+		err = m.game.Move(mv)
+	}
+	if err != nil {
+		m.error = err
+		return nil
+	}
+	cmd := m.moveApplied()
+	m.drillProgress()
+	return cmd
+}
+
+// solutionSource is the solution of the position being studied, which
+// answers the user's moves with its own.
+type solutionSource struct{}
+
+func (solutionSource) play(m *model) tea.Cmd {
+	solution := m.study.positions[m.study.current].solution
+	ply := len(m.game.Moves())
+	if ply >= len(solution) {
+		return nil
+	}
+	mv, err := chess.AlgebraicNotation{}.Decode(m.game.Position(), solution[ply])
+	if err == nil {
+		err = m.game.Move(mv)
+	}
+	if err != nil {
+		m.error = err
+		return nil
+	}
+	cmd := m.moveApplied()
+	m.studyProgress()
+	return cmd
+}
+
+// randomSource plays random moves for both sides of a demo without an
+// engine, one each time the demo tick comes.
+type randomSource struct{}
+
+func (randomSource) play(m *model) tea.Cmd {
+	if !m.demoDue {
+		return nil
+	}
+	m.demoDue = false
+	mv := m.validMoves[m.rng.IntN(len(m.validMoves))]
+	if err := m.game.Move(mv); err != nil {
+		m.error = err
+		return nil
+	}
+	cmd := m.moveApplied()
+	delay := demoMoveDelay
+	if m.game.Outcome() != chess.NoOutcome {
+		delay = demoRestartDelay
+	}
+	return tea.Batch(cmd, demoTick(delay))
+}
+
+// sourceFor returns the source of the moves of side in the game being
+// played. There is no source for network or built-in opponents yet; they
+// would be picked here.
+func (m *model) sourceFor(side chess.Color) moveSource {
+	switch {
+	case m.match != nil:
+		return matchSource{}
+	case m.demo && m.engine != nil:
+		return engineSource{}
+	case m.demo:
+		return randomSource{}
+	case m.studying():
+		if side != m.game.Positions()[0].Turn() {
+			return solutionSource{}
+		}
+	case m.drill != nil:
+		if side != m.drill.side {
+			return bookSource{}
+		}
+	case m.engine != nil && side == m.engineColor:
+		return engineSource{}
+	}
+	return humanSource{}
+}
+
+// nextTurn asks the source of the side to move for its move. Moves are only
+// asked for in the game being played, and not while the screensaver has
+// the board.
+func (m *model) nextTurn() tea.Cmd {
+	if m.screensaver != nil || m.mode != modePlay || m.game.Outcome() != chess.NoOutcome {
+		return nil
+	}
+	return m.sourceFor(m.game.Position().Turn()).play(m)
+}
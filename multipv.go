@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// defaultMultiPV is how many candidate moves analysis lists unless -multipv
+// says otherwise.
+const defaultMultiPV = 3
+
+// candidateShades color the candidate moves by how much worse than the best
+// move they are, from about as good to clearly worse.
+var candidateShades = []struct {
+	loss  int // centipawns lost against the best move, at most
+	color lipgloss.Color
+}{
+	{30, "#7FA650"},
+	{100, "#B5C25A"},
+	{250, "#D9A54C"},
+	{-1, "#C8553D"},
+}
+
+// candidate is a legal move from the engine's list of candidates.
+type candidate struct {
+	move  *chess.Move
+	san   string
+	score engineScore
+	shade lipgloss.Color
+}
+
+// cpFor turns a score into centipawns for the side to move, ranking mates
+// above any material advantage and faster mates above slower ones.
+func cpFor(s engineScore, side chess.Color) int {
+	cp := s.cp
+	switch {
+	case s.mate > 0:
+		cp = 100000 - s.mate
+	case s.mate < 0:
+		cp = -100000 - s.mate
+	}
+	if side == chess.Black {
+		cp = -cp
+	}
+	return cp
+}
+
+// candidates are the engine's best moves in the position shown, once it has
+// been analysed. Moves that aren't legal there are left out.
+func (m model) candidates() []candidate {
+	pos := m.displayedPosition()
+	if m.engine == nil || m.analysisFEN != pos.String() || len(m.analysis.lines) < 2 {
+		return nil
+	}
+	side := pos.Turn()
+	best := cpFor(m.analysis.lines[0].score, side)
+	var list []candidate
+	for _, line := range m.analysis.lines {
+		i := slices.IndexFunc(pos.ValidMoves(), func(mv *chess.Move) bool { return mv.String() == line.move })
+		if i < 0 {
+			continue
+		}
+		mv := pos.ValidMoves()[i]
+		c := candidate{
+			move:  mv,
+			san:   m.locale.translateSAN(chess.AlgebraicNotation{}.Encode(pos, mv)),
+			score: line.score,
+		}
+		loss := best - cpFor(line.score, side)
+		for _, shade := range candidateShades {
+			c.shade = shade.color
+			if loss <= shade.loss {
+				break
+			}
+		}
+		list = append(list, c)
+	}
+	return list
+}
+
+// candidateList ranks the candidate moves with their scores, each in the
+// shade of its strength.
+func (m model) candidateList() string {
+	var parts []string
+	for i, c := range m.candidates() {
+		style := lipgloss.NewStyle().Foreground(c.shade)
+		parts = append(parts, style.Render(fmt.Sprintf("%d. %s %s", i+1, c.san, c.score)))
+	}
+	return strings.Join(parts, statusMessageStyle.Render("  "))
+}
+
+// candidateHighlights marks the squares of the candidate moves with
+// -candidates, a stronger move's shade winning a square two moves share.
+func (m model) candidateHighlights() map[chess.Square]lipgloss.Style {
+	if !m.candidateSquares || !m.analysing {
+		return nil
+	}
+	list := m.candidates()
+	if list == nil {
+		return nil
+	}
+	hl := map[chess.Square]lipgloss.Style{}
+	for i := len(list) - 1; i >= 0; i-- {
+		style := lipgloss.NewStyle().Background(list[i].shade)
+		hl[list[i].move.S1()] = style
+		hl[list[i].move.S2()] = style
+	}
+	return hl
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// notationFile keeps the notation of the history, san, uci or descriptive,
+// between runs.
+const notationFile = "notation"
+
+// moveNotation is how the history lists moves.
+type moveNotation int
+
+const (
+	notationSAN         moveNotation = iota // Nf3, in the locale's letters
+	notationUCI                             // coordinates such as g1f3
+	notationDescriptive                     // the older English notation, N-KB3
+)
+
+var notationNames = []string{"san", "uci", "descriptive"}
+
+func (n moveNotation) String() string {
+	return notationNames[n]
+}
+
+// moveText is the i-th move of the history as listed: in the locale's SAN,
+// or in coordinates such as g1f3 or descriptive notation such as N-KB3 once
+// f8 has switched the notation.
+func (m model) moveText(i int) string {
+	if m.notation != notationSAN {
+		if moves := m.game.Moves(); m.historyDrop+i < len(moves) {
+			if m.notation == notationUCI {
+				return moves[m.historyDrop+i].String()
+			}
+			return encodeDescriptive(m.game.Positions()[m.historyDrop+i], moves[m.historyDrop+i])
+		}
+	}
+	return m.locale.translateSAN(m.history[i])
+}
+
+// toggleNotation switches the history to the next notation, from SAN to
+// coordinates to descriptive and back, and keeps the choice for the next
+// start.
+func (m *model) toggleNotation() error {
+	m.notation = (m.notation + 1) % moveNotation(len(notationNames))
+	m.updateHistoryViewport()
+	m.status = "History in " + strings.ToUpper(m.notation.String())
+	if m.notation == notationDescriptive {
+		m.status = "History in descriptive notation, which moves can be typed in too"
+	}
+	path, err := configPath(notationFile)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, []byte(m.notation.String()+"\n"))
+}
+
+// savedNotation returns the notation the history was last switched to.
+func savedNotation() moveNotation {
+	path, err := configPath(notationFile)
+	if err != nil {
+		return notationSAN
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return notationSAN
+	}
+	for i, name := range notationNames {
+		if strings.TrimSpace(string(data)) == name {
+			return moveNotation(i)
+		}
+	}
+	return notationSAN
+}
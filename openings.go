@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// opening is a named opening and the moves leading to its starting
+// position.
+type opening struct {
+	name  string
+	moves string
+}
+
+var openings = []opening{
+	{"Italian Game", "1. e4 e5 2. Nf3 Nc6 3. Bc4"},
+	{"Ruy Lopez", "1. e4 e5 2. Nf3 Nc6 3. Bb5"},
+	{"Scotch Game", "1. e4 e5 2. Nf3 Nc6 3. d4"},
+	{"Petrov Defense", "1. e4 e5 2. Nf3 Nf6"},
+	{"King's Gambit", "1. e4 e5 2. f4"},
+	{"Sicilian Najdorf", "1. e4 c5 2. Nf3 d6 3. d4 cxd4 4. Nxd4 Nf6 5. Nc3 a6"},
+	{"Sicilian Dragon", "1. e4 c5 2. Nf3 d6 3. d4 cxd4 4. Nxd4 Nf6 5. Nc3 g6"},
+	{"French Defense", "1. e4 e6 2. d4 d5"},
+	{"Caro-Kann Defense", "1. e4 c6 2. d4 d5"},
+	{"Scandinavian Defense", "1. e4 d5"},
+	{"Pirc Defense", "1. e4 d6 2. d4 Nf6 3. Nc3 g6"},
+	{"Queen's Gambit Declined", "1. d4 d5 2. c4 e6"},
+	{"Queen's Gambit Accepted", "1. d4 d5 2. c4 dxc4"},
+	{"Slav Defense", "1. d4 d5 2. c4 c6"},
+	{"London System", "1. d4 d5 2. Nf3 Nf6 3. Bf4"},
+	{"King's Indian Defense", "1. d4 Nf6 2. c4 g6 3. Nc3 Bg7 4. e4 d6"},
+	{"Nimzo-Indian Defense", "1. d4 Nf6 2. c4 e6 3. Nc3 Bb4"},
+	{"Grünfeld Defense", "1. d4 Nf6 2. c4 g6 3. Nc3 d5"},
+	{"Dutch Defense", "1. d4 f5"},
+	{"English Opening", "1. c4"},
+	{"Réti Opening", "1. Nf3 d5 2. c4"},
+}
+
+// findOpenings returns the openings whose names contain query, ignoring
+// case. A name equal to the query is the only match.
+func findOpenings(query string) []opening {
+	query = strings.ToLower(strings.TrimSpace(query))
+	var found []opening
+	for _, o := range openings {
+		name := strings.ToLower(o.name)
+		if name == query {
+			return []opening{o}
+		}
+		if strings.Contains(name, query) {
+			found = append(found, o)
+		}
+	}
+	return found
+}
+
+// openingList lists openings with their moves for :opening.
+func openingList(list []opening) string {
+	var sb strings.Builder
+	for _, o := range list {
+		fmt.Fprintf(&sb, "%-24s %s\n", o.name, o.moves)
+	}
+	sb.WriteString("\n:opening <name> to play from one, tab completes")
+	return sb.String()
+}
+
+// loadOpening starts a game from the opening's position, its moves in the
+// history.
+func (m *model) loadOpening(query string) (tea.Cmd, error) {
+	found := findOpenings(query)
+	switch {
+	case query == "":
+		found = openings
+	case len(found) == 0:
+		return nil, fmt.Errorf("no opening matches %q", query)
+	case len(found) == 1:
+		game := chess.NewGame()
+		for _, san := range movetextTokens(found[0].moves) {
+			if err := game.MoveStr(san); err != nil {
+				return nil, fmt.Errorf("%s: %w", found[0].name, err)
+			}
+		}
+		m.stashedGame, m.stashedHistory = nil, nil
+		cmd := m.startGame(game)
+		m.status = found[0].name
+		return cmd, nil
+	}
+	m.overlay = newTextOverlay("Openings", openingList(found))
+	return nil, nil
+}
+
+// completeOpening completes the opening name typed after :opening, up to
+// where the names it may be start to differ.
+func (m *model) completeOpening(prefix string) {
+	var names []string
+	for _, o := range openings {
+		if strings.HasPrefix(strings.ToLower(o.name), strings.ToLower(prefix)) {
+			names = append(names, o.name)
+		}
+	}
+	if len(names) == 0 {
+		m.status = "no matching opening"
+		return
+	}
+	common := names[0]
+	for _, name := range names[1:] {
+		for !strings.HasPrefix(name, common) {
+			r := []rune(common)
+			common = string(r[:len(r)-1])
+		}
+	}
+	if len([]rune(common)) > len([]rune(prefix)) {
+		m.textInput.SetValue("opening " + common)
+		m.textInput.CursorEnd()
+	}
+	if len(names) > 1 {
+		slices.Sort(names)
+		m.status = strings.Join(names, "  ")
+	}
+}
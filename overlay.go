@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	overlayWidth  = 60
+	overlayHeight = 14
+)
+
+// textOverlay is a scrollable window of text shown in place of the board,
+// e.g. to let the user copy a PGN by hand.
+type textOverlay struct {
+	title    string
+	viewport viewport.Model
+}
+
+func newTextOverlay(title, text string) *textOverlay {
+	vp := viewport.New(overlayWidth, overlayHeight)
+	vp.KeyMap = viewport.KeyMap{
+		PageDown: key.NewBinding(key.WithKeys("pgdown")),
+		PageUp:   key.NewBinding(key.WithKeys("pgup")),
+		Up:       key.NewBinding(key.WithKeys("up")),
+		Down:     key.NewBinding(key.WithKeys("down")),
+	}
+	vp.SetContent(lipgloss.NewStyle().Width(overlayWidth).Render(text))
+	return &textOverlay{title: title, viewport: vp}
+}
+
+// updateOverlay scrolls the overlay; esc, enter and q close it.
+func (m model) updateOverlay(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "enter", "q":
+		m.overlay = nil
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.overlay.viewport, cmd = m.overlay.viewport.Update(msg)
+	return m, cmd
+}
+
+func (o *textOverlay) View() string {
+	return helpStyle.Render(titleStyle.Render(o.title) + "\n\n" + o.viewport.View() + "\n\n" + statusMessageStyle.Faint(true).Render("↑/↓ scroll • esc close"))
+}
@@ -0,0 +1,713 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+const (
+	commandPrompt     = ":"
+	commandInputWidth = 48
+)
+
+// command is an action that can be run from the command palette.
+type command struct {
+	usage string
+	run   func(m *model, args []string) (tea.Cmd, error)
+}
+
+var commands = map[string]command{
+	"new": {
+		usage: "new",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return m.newGame(), nil
+		},
+	},
+	"handicap": {
+		usage: "handicap <pieces>|off",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) == 0 {
+				return nil, errors.New("usage: handicap <pieces>|off, e.g. handicap queen or handicap black knights")
+			}
+			if len(args) == 1 && args[0] == "off" {
+				m.handicap = handicap{}
+				m.status = "New games start with all the pieces"
+				return nil, nil
+			}
+			h, err := parseHandicap(strings.Join(args, " "))
+			if err != nil {
+				return nil, err
+			}
+			m.handicap, m.chess960, m.endgame = h, -1, -1
+			cmd := m.newGame()
+			m.status = h.label
+			return cmd, nil
+		},
+	},
+	"rematch": {
+		usage: "rematch",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return m.rematch(), nil
+		},
+	},
+	"tab": {
+		usage: "tab new|close|next|prev|<n>",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) != 1 {
+				return nil, errors.New("usage: tab new|close|next|prev|<n>")
+			}
+			switch args[0] {
+			case "new":
+				return m.openTab()
+			case "close":
+				return m.closeTab()
+			case "next":
+				return m.switchTab((m.active + 1) % len(m.sessions)), nil
+			case "prev":
+				return m.switchTab((m.active + len(m.sessions) - 1) % len(m.sessions)), nil
+			}
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 || n > len(m.sessions) {
+				return nil, fmt.Errorf("no tab %q", args[0])
+			}
+			return m.switchTab(n - 1), nil
+		},
+	},
+	"flip": {
+		usage: "flip",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return m.flipBoard(), nil
+		},
+	},
+	"focus": {
+		usage: "focus",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			m.toggleFocus()
+			return nil, nil
+		},
+	},
+	"heatmap": {
+		usage: "heatmap [white|black|net|off]",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			switch len(args) {
+			case 0:
+				m.heatmap = (m.heatmap + 1) % heatmapMode(len(heatmapNames))
+			case 1:
+				mode, err := parseHeatmapMode(args[0])
+				if err != nil {
+					return nil, err
+				}
+				m.heatmap = mode
+			default:
+				return nil, errors.New("usage: heatmap [white|black|net|off]")
+			}
+			m.status = "Heatmap " + heatmapNames[m.heatmap]
+			return nil, nil
+		},
+	},
+	"captured": {
+		usage: "captured [asc|desc|off]",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			switch len(args) {
+			case 0:
+				if m.captureOrder == capturesOff {
+					m.captureOrder = capturesDescending
+				} else {
+					m.captureOrder = capturesOff
+				}
+			case 1:
+				order, err := parseCaptureOrder(args[0])
+				if err != nil {
+					return nil, err
+				}
+				m.captureOrder = order
+			default:
+				return nil, errors.New("usage: captured [asc|desc|off]")
+			}
+			return nil, nil
+		},
+	},
+	"deltas": {
+		usage: "deltas",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return nil, m.toggleEvalDeltas()
+		},
+	},
+	"material": {
+		usage: "material [pieces|bar]",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			switch len(args) {
+			case 0:
+				if m.materialView == materialBar {
+					m.materialView = materialPieces
+				} else {
+					m.materialView = materialBar
+				}
+			case 1:
+				view, err := parseMaterialView(args[0])
+				if err != nil {
+					return nil, err
+				}
+				m.materialView = view
+			default:
+				return nil, errors.New("usage: material [pieces|bar]")
+			}
+			if m.materialView == materialPieces && m.captureOrder == capturesOff {
+				m.captureOrder = capturesDescending
+			}
+			return nil, nil
+		},
+	},
+	"scale": {
+		usage: "scale compact|small|normal|large",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) != 1 {
+				return nil, errors.New("usage: scale compact|small|normal|large")
+			}
+			scale, err := parseBoardScale(args[0])
+			if err != nil {
+				return nil, err
+			}
+			m.scale = scale
+			m.resizeHistory(m.historyWidth)
+			m.updateLegalMovesViewport()
+			return nil, nil
+		},
+	},
+	"history": {
+		usage: "history pairs|plies|inline|columns|last <n>|all",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) == 2 && args[0] == "last" {
+				n, err := strconv.Atoi(args[1])
+				if err != nil || n < 1 {
+					return nil, fmt.Errorf("invalid number of moves %q", args[1])
+				}
+				m.historyLimit = n
+				m.updateHistoryViewport()
+				return nil, nil
+			}
+			if len(args) == 1 && args[0] == "all" {
+				m.historyLimit = 0
+				m.updateHistoryViewport()
+				return nil, nil
+			}
+			if len(args) != 1 {
+				return nil, errors.New("usage: history pairs|plies|inline|columns|last <n>|all")
+			}
+			format, err := parseHistoryFormat(args[0])
+			if err != nil {
+				return nil, err
+			}
+			m.historyFormat = format
+			m.updateHistoryViewport()
+			return nil, nil
+		},
+	},
+	"locale": {
+		usage: "locale <name>",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) != 1 {
+				return nil, errors.New("usage: locale <name>")
+			}
+			locale, err := parseLocale(args[0])
+			if err != nil {
+				return nil, err
+			}
+			m.locale = locale
+			m.updateHistoryViewport()
+			m.updateLegalMovesViewport()
+			return nil, nil
+		},
+	},
+	"save": {
+		usage: "save <file>",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) != 1 {
+				return nil, errors.New("usage: save <file>")
+			}
+			return nil, m.saveGame(args[0])
+		},
+	},
+	"copy": {
+		usage: "copy",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			m.copyPGN()
+			return nil, nil
+		},
+	},
+	"diagram": {
+		usage: "diagram",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			m.copyDiagram()
+			return nil, nil
+		},
+	},
+	"uci": {
+		usage: "uci [file]",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			switch len(args) {
+			case 0:
+				m.copyUCI()
+				return nil, nil
+			case 1:
+				if err := os.WriteFile(args[0], []byte(uciMoves(m.game)+"\n"), 0o644); err != nil {
+					return nil, err
+				}
+				m.status = "Moves saved to " + args[0]
+				return nil, nil
+			}
+			return nil, errors.New("usage: uci [file]")
+		},
+	},
+	"load": {
+		usage: "load <file>",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) != 1 {
+				return nil, errors.New("usage: load <file>")
+			}
+			return m.loadGame(args[0])
+		},
+	},
+	"endgame": {
+		usage: "endgame [n]",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			switch len(args) {
+			case 0:
+				m.overlay = newTextOverlay("Endgames", m.endgameList())
+				return nil, nil
+			case 1:
+				n, err := strconv.Atoi(args[0])
+				if err != nil {
+					return nil, errors.New("usage: endgame [n]")
+				}
+				return m.startEndgame(n - 1)
+			}
+			return nil, errors.New("usage: endgame [n]")
+		},
+	},
+	"theme": {
+		usage: "theme",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			m.openThemes()
+			return nil, nil
+		},
+	},
+	"opening": {
+		usage: "opening [name]",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return m.loadOpening(strings.Join(args, " "))
+		},
+	},
+	"recent": {
+		usage: "recent",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return nil, m.openRecent()
+		},
+	},
+	"paste": {
+		usage: "paste",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			m.openPaste()
+			return nil, nil
+		},
+	},
+	"drill": {
+		usage: "drill <file>|off",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) != 1 {
+				return nil, errors.New("usage: drill <file>|off")
+			}
+			if args[0] == "off" {
+				m.drill = nil
+				return nil, nil
+			}
+			// drill the side at the bottom of the board
+			side := chess.White
+			if m.flipped {
+				side = chess.Black
+			}
+			drill, err := loadRepertoire(args[0], side)
+			if err != nil {
+				return nil, err
+			}
+			m.drill = drill
+			m.stashedGame, m.stashedHistory = nil, nil
+			return m.startGame(chess.NewGame()), nil
+		},
+	},
+	"study": {
+		usage: "study <file>|next|prev|<n>|hint|solution|off",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) != 1 {
+				return nil, errors.New("usage: study <file>|next|prev|<n>|hint|solution|off")
+			}
+			return m.studyCommand(args[0])
+		},
+	},
+	"arrow": {
+		usage: "arrow <from><to>",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) != 1 || len(args[0]) != 4 {
+				return nil, errors.New("usage: arrow <from><to>, e.g. arrow e2e4")
+			}
+			from, ok1 := parseSquare(args[0][:2])
+			to, ok2 := parseSquare(args[0][2:])
+			if !ok1 || !ok2 || from == to {
+				return nil, fmt.Errorf("invalid arrow %q", args[0])
+			}
+			return nil, m.annotate(from, to)
+		},
+	},
+	"circle": {
+		usage: "circle <square>",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) != 1 {
+				return nil, errors.New("usage: circle <square>")
+			}
+			sq, ok := parseSquare(args[0])
+			if !ok {
+				return nil, fmt.Errorf("invalid square %q", args[0])
+			}
+			return nil, m.annotate(sq, sq)
+		},
+	},
+	"unmark": {
+		usage: "unmark",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			m.clearAnnotation()
+			return nil, nil
+		},
+	},
+	"engine": {
+		usage: "engine restart",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) != 1 || args[0] != "restart" {
+				return nil, errors.New("usage: engine restart")
+			}
+			return m.restartEngine()
+		},
+	},
+	"hint": {
+		usage: "hint",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return m.requestHint()
+		},
+	},
+	"train": {
+		usage: "train",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return m.startTrainer(), nil
+		},
+	},
+	"svg": {
+		usage: "svg <file>",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) != 1 {
+				return nil, errors.New("usage: svg <file>")
+			}
+			return nil, m.exportSVG(args[0])
+		},
+	},
+	"snapshot": {
+		usage: "snapshot",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			m.takeSnapshot()
+			return nil, nil
+		},
+	},
+	"compare": {
+		usage: "compare",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return nil, m.toggleCompare()
+		},
+	},
+	"apply": {
+		usage: "apply <moves>",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) == 0 {
+				return nil, errors.New("usage: apply <moves>, e.g. apply 1. e4 e5 2. Nf3")
+			}
+			return m.applyMovetext(strings.Join(args, " "))
+		},
+	},
+	"cast": {
+		usage: "cast <file>",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) != 1 {
+				return nil, errors.New("usage: cast <file>")
+			}
+			if err := writeCast(m.game, args[0], m.boardOptions()); err != nil {
+				return nil, err
+			}
+			m.status = "Replay saved to " + args[0]
+			return nil, nil
+		},
+	},
+	"fen": {
+		usage: "fen [<fen>]",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) == 0 {
+				m.status = m.displayedPosition().String()
+				return nil, nil
+			}
+			// check before parsing so a bad FEN leaves the current game alone
+			if err := checkFEN(strings.Join(args, " ")); err != nil {
+				return nil, err
+			}
+			fen, err := chess.FEN(strings.Join(args, " "))
+			if err != nil {
+				return nil, err
+			}
+			game := chess.NewGame(fen)
+			m.stashedGame, m.stashedHistory = nil, nil
+			cmd := m.startGame(game)
+			m.status = "Loaded position from FEN"
+			return cmd, nil
+		},
+	},
+	"mirror": {
+		usage: "mirror",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return m.mirrorPosition()
+		},
+	},
+	"checkfen": {
+		usage: "checkfen <fen>",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) == 0 {
+				return nil, errors.New("usage: checkfen <fen>")
+			}
+			problems, err := fenProblems(strings.Join(args, " "))
+			if err != nil {
+				return nil, err
+			}
+			if len(problems) > 0 {
+				return nil, errors.New(strings.Join(problems, "; "))
+			}
+			m.status = "The FEN looks legal"
+			return nil, nil
+		},
+	},
+	"lichess": {
+		usage: "lichess",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			m.exportToLichess()
+			return nil, nil
+		},
+	},
+	"undo": {
+		usage: "undo",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return nil, m.undo()
+		},
+	},
+	"redo": {
+		usage: "redo",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return m.redoMove()
+		},
+	},
+	"resign": {
+		usage: "resign",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if m.mode != modePlay {
+				return nil, errors.New("you can only resign in play mode")
+			}
+			if m.game.Outcome() != chess.NoOutcome {
+				return nil, errors.New("the game is already over")
+			}
+			if m.engineToMove() {
+				return nil, errEngineToMove
+			}
+			m.resign(m.game.Position().Turn())
+			return nil, nil
+		},
+	},
+	"draw": {
+		usage: "draw",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return nil, m.offerDraw(m.game.Position().Turn())
+		},
+	},
+	"accept": {
+		usage: "accept",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return nil, m.acceptDraw()
+		},
+	},
+	"decline": {
+		usage: "decline",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return nil, m.declineDraw()
+		},
+	},
+	"goto": {
+		usage: "goto <ply>|end",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			if len(args) != 1 {
+				return nil, errors.New("usage: goto <ply>|end")
+			}
+			if args[0] == "end" {
+				m.setMode(modePlay)
+				return nil, nil
+			}
+			// in analysis the board has the moves tried, review the game's
+			last := len(m.liveGame().Moves())
+			ply, err := strconv.Atoi(args[0])
+			if err != nil || ply < 0 || ply > last {
+				return nil, fmt.Errorf("ply must be between 0 and %d", last)
+			}
+			m.setMode(modeReview)
+			m.viewPly = ply
+			return nil, nil
+		},
+	},
+	"find": {
+		usage: "find [<move>|<number>]",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return nil, m.findInHistory(strings.Join(args, " "))
+		},
+	},
+	"moves": {
+		usage: "moves",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			m.toggleLegalMoves()
+			return nil, nil
+		},
+	},
+	"play": {
+		usage: "play",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			m.setMode(modePlay)
+			return nil, nil
+		},
+	},
+	"review": {
+		usage: "review",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			m.setMode(modeReview)
+			return nil, nil
+		},
+	},
+	"analyze": {
+		usage: "analyze",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			m.setMode(modeAnalysis)
+			return nil, nil
+		},
+	},
+	"edit": {
+		usage: "edit",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			m.setMode(modeEdit)
+			return nil, nil
+		},
+	},
+	"help": {
+		usage: "help",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			m.showHelp = true
+			return nil, nil
+		},
+	},
+	"quit": {
+		usage: "quit",
+		run: func(m *model, args []string) (tea.Cmd, error) {
+			return tea.Quit, nil
+		},
+	},
+}
+
+func (m *model) enterCommandMode() {
+	m.commandMode = true
+	m.textInput.Reset()
+	m.textInput.Focus()
+	m.textInput.Prompt = commandPrompt
+	m.fitInput(false)
+	m.textInput.EchoMode = textinput.EchoNormal
+}
+
+func (m *model) exitCommandMode() {
+	m.commandMode = false
+	m.textInput.Reset()
+	if m.normalMode {
+		m.textInput.Blur()
+	}
+	m.textInput.Prompt = movePrompt
+	m.fitInput(false)
+	m.textInput.EchoMode = m.inputMask.echoMode()
+}
+
+func (m model) updateCommandMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.exitCommandMode()
+		return m, nil
+	case tea.KeyTab:
+		m.completeCommand()
+		return m, nil
+	case tea.KeyEnter:
+		line := m.textInput.Value()
+		m.exitCommandMode()
+		cmd, err := m.runCommand(line)
+		m.error = err
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+func (m *model) runCommand(line string) (tea.Cmd, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	c, ok := commands[fields[0]]
+	if !ok {
+		return nil, fmt.Errorf("unknown command %q", fields[0])
+	}
+	return c.run(m, fields[1:])
+}
+
+// completeCommand completes the command name being typed, or lists the
+// candidates when the prefix is ambiguous. The name of an opening completes
+// too.
+func (m *model) completeCommand() {
+	value := m.textInput.Value()
+	if name, arg, ok := strings.Cut(value, " "); ok {
+		if name == "opening" {
+			m.completeOpening(arg)
+		}
+		return
+	}
+
+	var matches []string
+	for name := range commands {
+		if strings.HasPrefix(name, value) {
+			matches = append(matches, name)
+		}
+	}
+	slices.Sort(matches)
+
+	switch len(matches) {
+	case 0:
+		m.status = "no matching command"
+	case 1:
+		m.textInput.SetValue(matches[0] + " ")
+		m.textInput.CursorEnd()
+	default:
+		m.status = strings.Join(matches, "  ")
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// pasteModal is a window to paste a PGN into, the interactive counterpart
+// of -pgn.
+type pasteModal struct {
+	textarea textarea.Model
+	err      error // why the pasted text couldn't be loaded
+}
+
+func newPasteModal() *pasteModal {
+	ta := textarea.New()
+	ta.ShowLineNumbers = false
+	ta.Placeholder = "Paste a PGN here"
+	// games can be longer than the default limit of 99 lines
+	ta.MaxHeight = 0
+	ta.SetWidth(overlayWidth)
+	ta.SetHeight(overlayHeight)
+	// the blink messages go to the move input, so the cursor doesn't blink
+	ta.Cursor.SetMode(cursor.CursorStatic)
+	ta.Focus()
+	return &pasteModal{textarea: ta}
+}
+
+// openPaste shows the window to paste a PGN into.
+func (m *model) openPaste() {
+	m.paste = newPasteModal()
+}
+
+// updatePaste edits the pasted text; ctrl+s loads it as the game and esc
+// closes the window without loading.
+func (m model) updatePaste(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.paste = nil
+		return m, nil
+	case "ctrl+s":
+		text := m.paste.textarea.Value()
+		if strings.TrimSpace(text) == "" {
+			return m, nil
+		}
+		pgn, err := chess.PGN(strings.NewReader(text))
+		if err != nil {
+			m.paste.err = err
+			return m, nil
+		}
+		game := chess.NewGame(pgn)
+		// a game set up from a FEN tag starts from wherever it says
+		fen := game.Positions()[0].String()
+		if isChess960(game) {
+			// Chess960 castles with the king and rooks where they start, not
+			// from the squares of standard chess checkFEN expects them on
+			fields := strings.Fields(fen)
+			fields[2] = "-"
+			fen = strings.Join(fields, " ")
+		}
+		if err := checkFEN(fen); err != nil {
+			m.paste.err = err
+			return m, nil
+		}
+		m.paste = nil
+		m.stashedGame, m.stashedHistory = nil, nil
+		cmd := m.startGame(game)
+		m.status = "Game loaded from the pasted PGN"
+		return m, cmd
+	}
+	var cmd tea.Cmd
+	m.paste.textarea, cmd = m.paste.textarea.Update(msg)
+	m.paste.err = nil
+	return m, cmd
+}
+
+func (p *pasteModal) View() string {
+	footer := statusMessageStyle.Faint(true).Render("ctrl+s load • esc cancel")
+	if p.err != nil {
+		footer = errorStyle.Render(p.err.Error()) + "\n" + footer
+	}
+	return helpStyle.Render(titleStyle.Render("Paste PGN") + "\n\n" + p.textarea.View() + "\n\n" + footer)
+}
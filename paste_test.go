@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pastePGN loads pgn through the paste window, returning the error it
+// shows if the game isn't loaded.
+func (u *uiModel) pastePGN(pgn string) error {
+	u.m.openPaste()
+	u.m.paste.textarea.SetValue(pgn)
+	u.send(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if u.m.paste != nil {
+		return u.m.paste.err
+	}
+	return nil
+}
+
+func TestPasteChess960WithCastlingRights(t *testing.T) {
+	u := newUIModel(t)
+	pgn := `[Variant "Chess960"]
+[SetUp "1"]
+[FEN "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w KQkq - 0 1"]
+
+1. e4 e5 *`
+	if err := u.pastePGN(pgn); err != nil {
+		t.Fatalf("pasting a Chess960 game: %v", err)
+	}
+	if got := moveList(u.m.game); got != "e4 e5" {
+		t.Errorf("game has the moves %q, want e4 e5", got)
+	}
+}
+
+func TestPasteImpossibleStart(t *testing.T) {
+	u := newUIModel(t)
+	for _, variant := range []string{"", "[Variant \"Chess960\"]\n"} {
+		pgn := variant + `[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/8/4K2K w - - 0 1"]
+
+*`
+		err := u.pastePGN(pgn)
+		if err == nil || !strings.Contains(err.Error(), "2 kings") {
+			t.Errorf("pasting a start with two white kings, %q: error %v, want the kings counted", variant, err)
+		}
+	}
+}
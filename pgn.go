@@ -0,0 +1,217 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/notnil/chess"
+)
+
+// loadPGN reads the first game of a PGN file, keeping its tag pairs.
+func loadPGN(path string) (*chess.Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	pgn, err := chess.PGN(f)
+	if err != nil {
+		return nil, err
+	}
+	return chess.NewGame(pgn), nil
+}
+
+// exportPGN encodes the game as PGN. Tag pairs and comments are written
+// back as loaded, except that a Result tag is updated to match the game's
+// outcome and the SetUp and FEN tags to match its start, arrows and circles
+// are taken from annotations, which is keyed by FEN, and the engine's
+// evaluations and best moves are added from notes.
+func exportPGN(game *chess.Game, annotations map[string]annotation, notes engineNotes) string {
+	var sb strings.Builder
+	for _, tag := range game.TagPairs() {
+		value := tag.Value
+		switch tag.Key {
+		case "Result":
+			value = string(game.Outcome())
+		case "SetUp", "FEN":
+			continue
+		}
+		fmt.Fprintf(&sb, "[%s \"%s\"]\n", tag.Key, value)
+	}
+	// games from a Chess960, handicap, edited or mid-game position load
+	// back from it
+	if fen := game.Positions()[0].String(); fen != chess.StartingPosition().String() {
+		fmt.Fprintf(&sb, "[SetUp \"1\"]\n[FEN \"%s\"]\n", fen)
+	}
+	sb.WriteString("\n")
+
+	positions := game.Positions()
+	comments := game.Comments()
+	start := gameStartPly(game)
+	for i, mv := range game.Moves() {
+		// a game set up with Black to move opens with "N... "
+		if ply := start + i; ply%2 == 0 || i == 0 {
+			sb.WriteString(plyNumber(ply) + " ")
+		}
+		sb.WriteString(chess.AlgebraicNotation{}.Encode(positions[i], mv) + " ")
+		var texts []string
+		if i < len(comments) {
+			for _, c := range comments[i] {
+				c = stripAnnotation(c)
+				if notes.hasEval(positions[i+1]) {
+					c = strings.TrimSpace(evalCommand.ReplaceAllString(c, ""))
+				}
+				if notes.clk(i) != "" {
+					c = strings.TrimSpace(clkCommand.ReplaceAllString(c, ""))
+				}
+				if c != "" {
+					texts = append(texts, c)
+				}
+			}
+		}
+		if a := annotations[positions[i+1].String()]; !a.empty() {
+			texts = append(texts, a.comment())
+		}
+		if c := strings.TrimSpace(notes.comment(positions[i], positions[i+1], mv) + " " + notes.clk(i)); c != "" {
+			texts = append(texts, c)
+		}
+		for _, note := range texts {
+			sb.WriteString("{ " + note + " } ")
+		}
+	}
+	sb.WriteString(string(game.Outcome()))
+	return sb.String()
+}
+
+// sanHistory returns the moves of the game in algebraic notation.
+func sanHistory(game *chess.Game) []string {
+	positions := game.Positions()
+	var history []string
+	for i, mv := range game.Moves() {
+		history = append(history, chess.AlgebraicNotation{}.Encode(positions[i], mv))
+	}
+	return history
+}
+
+// copyPGN puts the game's PGN on the clipboard, or shows it in an overlay
+// when there is no clipboard to use.
+func (m *model) copyPGN() {
+	pgn := exportPGN(m.game, m.annotations, m.engineNotes())
+	if err := clipboard.WriteAll(pgn); err != nil {
+		m.overlay = newTextOverlay("PGN", strings.TrimSpace(pgn))
+		m.status = "No clipboard available, copy the PGN from here"
+		return
+	}
+	m.status = "PGN copied to the clipboard"
+}
+
+// gameFrom returns the moves of game from ply on as a game of its own, set
+// up from the position at ply with the SetUp and FEN tags. The other tags
+// are kept, and so is the result of a game that was resigned or drawn,
+// which the moves alone may not show. Comments in the PGN are not.
+func gameFrom(game *chess.Game, ply int) (*chess.Game, error) {
+	fen := game.Positions()[ply].String()
+	start, err := chess.FEN(fen)
+	if err != nil {
+		return nil, err
+	}
+	part := chess.NewGame(start, chess.TagPairs(game.TagPairs()))
+	// removed first: updating a tag would change it in game too
+	part.RemoveTagPair("SetUp")
+	part.RemoveTagPair("FEN")
+	part.AddTagPair("SetUp", "1")
+	part.AddTagPair("FEN", fen)
+	for _, mv := range game.Moves()[ply:] {
+		if err := part.Move(mv); err != nil {
+			return nil, err
+		}
+	}
+	if part.Outcome() == chess.NoOutcome {
+		switch game.Outcome() {
+		case chess.WhiteWon:
+			part.Resign(chess.Black)
+		case chess.BlackWon:
+			part.Resign(chess.White)
+		case chess.Draw:
+			part.Draw(chess.DrawOffer)
+		}
+	}
+	return part, nil
+}
+
+// copyPGNFrom puts the moves from ply on on the clipboard as PGN set up from
+// the position before them, to share a phase of the game, or shows them in
+// an overlay when there is no clipboard to use.
+func (m *model) copyPGNFrom(ply int) error {
+	if ply >= len(m.game.Moves()) {
+		return errors.New("no moves from here on, step back to where the part to share starts")
+	}
+	part, err := gameFrom(m.game, ply)
+	if err != nil {
+		return err
+	}
+	notes := m.engineNotes()
+	notes.clocks = notes.clocks[min(ply, len(notes.clocks)):]
+	pgn := exportPGN(part, m.annotations, notes)
+	san := chess.AlgebraicNotation{}.Encode(m.game.Positions()[ply], m.game.Moves()[ply])
+	from := plyNumber(m.startPly()+ply) + " " + m.locale.translateSAN(san)
+	if err := clipboard.WriteAll(pgn); err != nil {
+		m.overlay = newTextOverlay("PGN from "+from, strings.TrimSpace(pgn))
+		m.status = "No clipboard available, copy the PGN from here"
+		return nil
+	}
+	m.status = "PGN from " + from + " copied to the clipboard"
+	return nil
+}
+
+// uciMoves lists the moves of the game in coordinate notation, e.g.
+// "e2e4 e7e5 g1f3", as UCI engines and many scripts take them.
+func uciMoves(game *chess.Game) string {
+	moves := make([]string, len(game.Moves()))
+	for i, mv := range game.Moves() {
+		moves[i] = mv.String()
+	}
+	return strings.Join(moves, " ")
+}
+
+// copyUCI puts the moves in coordinate notation on the clipboard, or shows
+// them in an overlay when there is no clipboard to use.
+func (m *model) copyUCI() {
+	moves := uciMoves(m.game)
+	if moves == "" {
+		m.error = errors.New("no moves have been played yet")
+		return
+	}
+	if err := clipboard.WriteAll(moves); err != nil {
+		m.overlay = newTextOverlay("Moves", moves)
+		m.status = "No clipboard available, copy the moves from here"
+		return
+	}
+	m.status = "Moves copied to the clipboard"
+}
+
+// copyLastMove puts the last move on the clipboard with its number, e.g.
+// "12... Nf6", for relaying it in a correspondence game. Without a
+// clipboard it is shown on the status line instead.
+func (m *model) copyLastMove() {
+	if len(m.history) == 0 {
+		m.error = errors.New("no moves have been played yet")
+		return
+	}
+	positions := m.game.Positions()
+	before := positions[len(positions)-2]
+	move := fullMoveNumber(before) + ". "
+	if before.Turn() == chess.Black {
+		move = fullMoveNumber(before) + "... "
+	}
+	move += m.locale.translateSAN(m.history[len(m.history)-1])
+	if err := clipboard.WriteAll(move); err != nil {
+		m.status = "Last move: " + move
+		return
+	}
+	m.status = move + " copied to the clipboard"
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+// reloadPGN reads back a PGN written by exportPGN.
+func reloadPGN(t *testing.T, pgn string) *chess.Game {
+	t.Helper()
+	opt, err := chess.PGN(strings.NewReader(pgn))
+	if err != nil {
+		t.Fatalf("%v in\n%s", err, pgn)
+	}
+	return chess.NewGame(opt)
+}
+
+func TestExportPGNSetUp(t *testing.T) {
+	fen960, err := fen960(518 + 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handicap, err := handicapFEN([]string{"d1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		fen   string
+		moves []string
+	}{
+		{"chess960", fen960, []string{"e4", "e5"}},
+		{"handicap", handicap, []string{"e4", "e5", "Nf3"}},
+		{"black to move", "r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 3 34", []string{"Nf6", "Nc3", "Bc5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, err := chess.FEN(tt.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			game := chess.NewGame(start)
+			for _, move := range tt.moves {
+				if err := game.MoveStr(move); err != nil {
+					t.Fatalf("%s: %v", move, err)
+				}
+			}
+			pgn := exportPGN(game, nil, engineNotes{})
+			if !strings.Contains(pgn, "[SetUp \"1\"]\n[FEN \""+tt.fen+"\"]\n") {
+				t.Errorf("no SetUp and FEN tags for the start in\n%s", pgn)
+			}
+			back := reloadPGN(t, pgn)
+			if got, want := back.Position().String(), game.Position().String(); got != want {
+				t.Errorf("reloaded at %s, want %s", got, want)
+			}
+			if got := sanHistory(back); strings.Join(got, " ") != strings.Join(tt.moves, " ") {
+				t.Errorf("reloaded moves %v, want %v", got, tt.moves)
+			}
+		})
+	}
+}
+
+func TestExportPGNStandardStart(t *testing.T) {
+	pgn := exportPGN(newTestGame(t, "e4", "e5"), nil, engineNotes{})
+	if strings.Contains(pgn, "[SetUp") || strings.Contains(pgn, "[FEN") {
+		t.Errorf("setup tags for the standard start in\n%s", pgn)
+	}
+}
+
+func TestExportPGNUpdatesSetUp(t *testing.T) {
+	// a game loaded with a FEN tag and played on is written with one pair
+	// of tags, for where it starts
+	game := reloadPGN(t, "[FEN \"8/8/8/4k3/8/8/4P3/4K3 w - - 0 1\"]\n\n1. Kd2 *")
+	pgn := exportPGN(game, nil, engineNotes{})
+	if n := strings.Count(pgn, "[FEN "); n != 1 {
+		t.Errorf("%d FEN tags in\n%s", n, pgn)
+	}
+	if !strings.Contains(pgn, "[SetUp \"1\"]") {
+		t.Errorf("no SetUp tag in\n%s", pgn)
+	}
+}
+
+func TestGameFromRoundTrip(t *testing.T) {
+	resigned := newTestGame(t, "e4", "e5", "Nf3", "Nc6", "Bb5")
+	resigned.Resign(chess.Black)
+	drawn := newTestGame(t, "d4", "d5", "c4", "e6")
+	drawn.Draw(chess.DrawOffer)
+	black := newTestGameFrom(t, blackToMove, "Nf6", "Nc3", "Bc5", "Bc4")
+
+	tests := []struct {
+		name string
+		game *chess.Game
+		ply  int
+	}{
+		{"mate from the start", newTestGame(t, "f3", "e5", "g4", "Qh4#"), 0},
+		{"mate from White's move", newTestGame(t, "f3", "e5", "g4", "Qh4#"), 2},
+		{"mate from the last move", newTestGame(t, "f3", "e5", "g4", "Qh4#"), 3},
+		{"resigned", resigned, 3},
+		{"drawn", drawn, 1},
+		{"unfinished", newTestGame(t, "e4", "c5", "Nf3"), 1},
+		{"set up with Black to move", black, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			part, err := gameFrom(tt.game, tt.ply)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pgn := exportPGN(part, nil, engineNotes{})
+			back := reloadPGN(t, pgn)
+			if got, want := back.Positions()[0].String(), tt.game.Positions()[tt.ply].String(); got != want {
+				t.Errorf("reloaded from %s, want %s", got, want)
+			}
+			if got, want := back.Position().String(), tt.game.Position().String(); got != want {
+				t.Errorf("reloaded at %s, want %s", got, want)
+			}
+			if got, want := back.Outcome(), tt.game.Outcome(); got != want {
+				t.Errorf("reloaded with the result %s, want %s in\n%s", got, want, pgn)
+			}
+			if got, want := moveList(back), strings.Join(sanHistory(tt.game)[tt.ply:], " "); got != want {
+				t.Errorf("reloaded moves %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestGameFromKeepsTags(t *testing.T) {
+	game := reloadPGN(t, "[Event \"Club championship\"]\n[White \"Anna\"]\n\n1. e4 e5 2. Nf3 *")
+	part, err := gameFrom(game, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag := part.GetTagPair("White"); tag == nil || tag.Value != "Anna" {
+		t.Errorf("White tag %v in the part, want Anna", tag)
+	}
+	// the part's setup tags are its own
+	if tag := game.GetTagPair("FEN"); tag != nil {
+		t.Errorf("the game got the part's FEN tag %q", tag.Value)
+	}
+}
+
+func TestCopyPGNFromTheEnd(t *testing.T) {
+	u := newUIModel(t)
+	u.m.startGame(newTestGame(t, "e4", "e5"))
+	if err := u.m.copyPGNFrom(2); err == nil {
+		t.Error("copying the moves after the last one doesn't fail")
+	}
+}
+
+// tagList lists the tag pairs of a game in order, as "Key=Value".
+func tagList(game *chess.Game) []string {
+	var tags []string
+	for _, tag := range game.TagPairs() {
+		tags = append(tags, tag.Key+"="+tag.Value)
+	}
+	return tags
+}
+
+func TestTagPairsRoundTrip(t *testing.T) {
+	tempConfigDir(t)
+	dir := t.TempDir()
+	in, out := filepath.Join(dir, "in.pgn"), filepath.Join(dir, "out.pgn")
+	pgn := `[Event "Club championship"]
+[Site "Riga"]
+[Date "1960.05.07"]
+[Round "3"]
+[White "Tal, Mikhail"]
+[Black "Botvinnik, Mikhail"]
+[Result "1-0"]
+[WhiteElo "2600"]
+[Annotator "gochess"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 1-0
+`
+	if err := os.WriteFile(in, []byte(pgn), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	u := newUIModel(t)
+	if _, err := u.m.loadGame(in); err != nil {
+		t.Fatal(err)
+	}
+	want := tagList(u.m.game)
+	if len(want) != 9 {
+		t.Fatalf("loaded tags %q, want the 9 of the file", want)
+	}
+	if err := u.m.writeGame(out); err != nil {
+		t.Fatal(err)
+	}
+	back, err := loadPGN(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tagList(back); !slices.Equal(got, want) {
+		t.Errorf("saved tags %q, want %q", got, want)
+	}
+	if back.Outcome() != chess.WhiteWon {
+		t.Errorf("saved game's result %s, want 1-0", back.Outcome())
+	}
+}
+
+func TestTagPairsResultUpdated(t *testing.T) {
+	game := reloadPGN(t, "[Event \"Casual\"]\n[Result \"*\"]\n\n1. f3 e5 2. g4 *")
+	if err := game.MoveStr("Qh4#"); err != nil {
+		t.Fatal(err)
+	}
+	back := reloadPGN(t, exportPGN(game, nil, engineNotes{}))
+	if got, want := tagList(back), []string{"Event=Casual", "Result=0-1"}; !slices.Equal(got, want) {
+		t.Errorf("tags %q after the game ended, want %q", got, want)
+	}
+}
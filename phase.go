@@ -0,0 +1,80 @@
+package main
+
+import "github.com/notnil/chess"
+
+// gamePhase is the stage a game has reached, as judged from the board.
+type gamePhase int
+
+const (
+	phaseOpening gamePhase = iota
+	phaseMiddlegame
+	phaseEndgame
+)
+
+func (p gamePhase) String() string {
+	switch p {
+	case phaseOpening:
+		return "Opening"
+	case phaseMiddlegame:
+		return "Middlegame"
+	}
+	return "Endgame"
+}
+
+const (
+	// endgameMaterial is the most material besides pawns and kings, of both
+	// sides together, left in an endgame: a rook and a minor piece each,
+	// or a queen and a rook between them. Both sides start with 62.
+	endgameMaterial = 26
+	// openingMaterial is the least material besides pawns and kings left in
+	// the opening, which allows a pair of minor pieces to be traded.
+	openingMaterial = 56
+	// openingHomeMinors is how many knights and bishops must still stand
+	// on the squares they start from for the game to be in the opening.
+	openingHomeMinors = 4
+)
+
+// minorHomes are the squares the knights and bishops start from, with the
+// pieces that start there.
+var minorHomes = map[chess.Square]chess.Piece{
+	chess.B1: chess.WhiteKnight, chess.G1: chess.WhiteKnight,
+	chess.C1: chess.WhiteBishop, chess.F1: chess.WhiteBishop,
+	chess.B8: chess.BlackKnight, chess.G8: chess.BlackKnight,
+	chess.C8: chess.BlackBishop, chess.F8: chess.BlackBishop,
+}
+
+// positionPhase judges the phase of the game from the board alone. It is an
+// endgame once the pieces besides pawns and kings are worth no more than
+// endgameMaterial, in the opening while little has been traded and at least
+// openingHomeMinors knights and bishops are still undeveloped, and a
+// middlegame otherwise.
+func positionPhase(pos *chess.Position) gamePhase {
+	board := pos.Board()
+	material := 0
+	for _, p := range board.SquareMap() {
+		if p.Type() != chess.Pawn {
+			material += pieceValues[p.Type()]
+		}
+	}
+	if material <= endgameMaterial {
+		return phaseEndgame
+	}
+	home := 0
+	for sq, p := range minorHomes {
+		if board.Piece(sq) == p {
+			home++
+		}
+	}
+	if material >= openingMaterial && home >= openingHomeMinors {
+		return phaseOpening
+	}
+	return phaseMiddlegame
+}
+
+// togglePhase shows or hides the phase of the game next to whose turn it is.
+func (m *model) togglePhase() {
+	m.showPhase = !m.showPhase
+	if m.showPhase {
+		m.status = "Game phase: " + positionPhase(m.displayedPosition()).String()
+	}
+}
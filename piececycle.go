@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// cyclePieces are the piece types whose moves their letters cycle through
+// in normal mode.
+var cyclePieces = []chess.PieceType{chess.Knight, chess.Bishop, chess.Rook, chess.Queen, chess.King}
+
+// pieceMoves returns the legal moves of the side to move's pieces of type t,
+// in the order the validMoves list them. Promotions other than to a queen
+// are left out, as they are when dragging.
+func pieceMoves(pos *chess.Position, moves []*chess.Move, t chess.PieceType) []*chess.Move {
+	var list []*chess.Move
+	for _, mv := range moves {
+		if pos.Board().Piece(mv.S1()).Type() == t && (mv.Promo() == chess.NoPieceType || mv.Promo() == chess.Queen) {
+			list = append(list, mv)
+		}
+	}
+	return list
+}
+
+// cycleKey handles a piece letter pressed in normal mode: it picks the next
+// legal move of that piece type, shown on the board until enter plays it.
+// It reports whether the key was a piece letter.
+func (m *model) cycleKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	for _, t := range cyclePieces {
+		if msg.String() != strings.ToUpper(m.locale.letter(t)) {
+			continue
+		}
+		if m.mode != modePlay && m.mode != modeAnalysis || m.game.Outcome() != chess.NoOutcome {
+			m.error = errors.New("moves can only be picked while playing or analysing")
+			return nil, true
+		}
+		pos := m.game.Position()
+		moves := pieceMoves(pos, m.validMoves, t)
+		if len(moves) == 0 {
+			m.cycledMove = nil
+			m.status = "No " + strings.ToLower(pieceName(t)) + " moves"
+			return nil, true
+		}
+		next := 0
+		for i, mv := range moves {
+			if mv == m.cycledMove {
+				next = (i + 1) % len(moves)
+			}
+		}
+		m.cycledMove = moves[next]
+		san := m.locale.translateSAN(chess.AlgebraicNotation{}.Encode(pos, m.cycledMove))
+		m.status = fmt.Sprintf("%s (%d/%d) • enter plays it", san, next+1, len(moves))
+		return nil, true
+	}
+	return nil, false
+}
+
+// playCycled plays the move picked with a piece letter.
+func (m *model) playCycled() tea.Cmd {
+	mv := m.cycledMove
+	m.cycledMove = nil
+	m.status = ""
+	return m.submitMove(mv)
+}
+
+// cycledHighlights marks the squares of the move picked with a piece
+// letter.
+func (m model) cycledHighlights() map[chess.Square]lipgloss.Style {
+	if m.cycledMove == nil {
+		return nil
+	}
+	return map[chess.Square]lipgloss.Style{
+		m.cycledMove.S1(): dragSourceStyle,
+		m.cycledMove.S2(): dragTargetStyle,
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// ponderSearch is the engine thinking, on the user's time, about the
+// position after the reply it expects, with -ponder.
+type ponderSearch struct {
+	engine *uciEngine
+	from   string // the position the user is to move in
+	fen    string // the position after the expected reply
+	hit    bool   // the user played the expected reply: the search is the engine's move
+	end    chan string
+}
+
+// ponderDoneMsg carries the result of pondering once the engine stopped.
+type ponderDoneMsg struct {
+	search *ponderSearch
+	result searchResult
+	err    error
+}
+
+// startPondering lets the engine think about the reply it expects while the
+// user is to move, if -ponder is set. Pondering waits for the evaluations
+// and any hint or analysis to be done, as they need the engine too, and
+// stops if the game moves elsewhere.
+func (m *model) startPondering() tea.Cmd {
+	if p := m.pondering; p != nil && p.from != m.game.Position().String() {
+		m.stopPondering()
+	}
+	if !m.ponder || m.engine == nil || m.pondering != nil || m.engineThinking ||
+		m.evalPending || m.hintPending || m.analysisPending {
+		return nil
+	}
+	pos := m.game.Position()
+	if m.ponderMove == "" || m.ponderFEN != pos.String() || pos.Turn() != m.engineColor.Other() {
+		return nil
+	}
+	predicted := m.ponderMove
+	m.ponderMove = ""
+	mv, err := chess.UCINotation{}.Decode(pos, predicted)
+	if err != nil {
+		return nil
+	}
+	p := &ponderSearch{engine: m.engine, from: pos.String(), fen: pos.Update(mv).String(), end: make(chan string, 1)}
+	m.pondering = p
+	limit := m.engine.limit
+	if limit.isZero() {
+		limit = searchLimit{movetime: playMovetime}
+	}
+	return func() tea.Msg {
+		result, err := p.engine.ponder(pos, predicted, limit, p.end)
+		return ponderDoneMsg{search: p, result: result, err: err}
+	}
+}
+
+// ponderHit turns pondering into the search for the engine's move if the
+// user played the reply it expected, reporting whether it did.
+func (m *model) ponderHit() bool {
+	p := m.pondering
+	if p == nil || p.hit || p.fen != m.game.Position().String() {
+		return false
+	}
+	p.hit = true
+	m.engineThinking = true
+	m.engineProgress = nil
+	p.end <- "ponderhit"
+	return true
+}
+
+// stopPondering ends pondering that is of no use, because the user played
+// another move or the engine is needed for something else.
+func (m *model) stopPondering() {
+	if p := m.pondering; p != nil && !p.hit {
+		p.end <- "stop"
+		m.pondering = nil
+	}
+}
+
+// handlePonderDone plays the engine's move after a ponder hit. The result of
+// pondering that was stopped is dropped.
+func (m *model) handlePonderDone(msg ponderDoneMsg) tea.Cmd {
+	if msg.search != m.pondering {
+		return nil
+	}
+	m.pondering = nil
+	if !msg.search.hit {
+		// the engine stopped pondering by itself
+		if msg.err != nil && msg.search.engine == m.engine {
+			return m.engineFailed(msg.err)
+		}
+		return nil
+	}
+	return m.handleEngineMove(engineMoveMsg{engine: msg.search.engine, fen: msg.search.fen, result: msg.result, err: msg.err})
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// ponderingModel is a game against the fake engine, which plays Black and
+// ponders on e4 while the user is to move.
+func ponderingModel(t *testing.T) (*uiModel, *fakeEngine, tea.Cmd) {
+	t.Helper()
+	f := newFakeEngine(t, func(command string) []string {
+		switch {
+		case command == "ponderhit":
+			return []string{"info depth 5 score cp -20 pv e7e5", "bestmove e7e5 ponder g1f3"}
+		case command == "stop":
+			return []string{"bestmove e7e5"}
+		}
+		return nil
+	})
+	u := newUIModel(t)
+	u.m.engine = f.uciEngine
+	u.m.engineColor = chess.Black
+	u.m.ponder = true
+	u.m.evals[u.m.game.Position().String()] = engineScore{cp: 30}
+	u.m.ponderFEN, u.m.ponderMove = u.m.game.Position().String(), "e2e4"
+	cmd := u.m.startPondering()
+	if cmd == nil {
+		t.Fatal("the engine doesn't ponder")
+	}
+	return u, f, cmd
+}
+
+func TestPonderHit(t *testing.T) {
+	u, f, ponder := ponderingModel(t)
+	done := make(chan tea.Msg)
+	go func() { done <- ponder() }()
+	if got := f.expect(t, "position"); !strings.HasSuffix(got, " moves e2e4") {
+		t.Errorf("engine ponders on %q, want the position after e2e4", got)
+	}
+	f.expect(t, "go ponder")
+
+	u.enter("e4")
+	f.expect(t, "ponderhit", "stop")
+	u.send(<-done)
+	if got := strings.Join(sanHistory(u.m.game), " "); got != "e4 e5" {
+		t.Errorf("moves = %q, want the engine's reply from pondering: e4 e5", got)
+	}
+}
+
+func TestPonderMiss(t *testing.T) {
+	u, f, ponder := ponderingModel(t)
+	done := make(chan tea.Msg)
+	go func() { done <- ponder() }()
+	f.expect(t, "go ponder")
+
+	u.enter("d4")
+	f.expect(t, "stop", "ponderhit")
+	u.send(<-done)
+	if u.m.pondering != nil {
+		t.Error("still pondering after another move")
+	}
+	if got := strings.Join(sanHistory(u.m.game), " "); got != "d4" {
+		t.Errorf("moves = %q, want d4 with the engine yet to reply", got)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// progressInterval is how often the engine's progress is shown while it
+// thinks about its move, however often it reports.
+const progressInterval = 250 * time.Millisecond
+
+// searchProgress is how far a search has got, from the engine's info lines.
+type searchProgress struct {
+	depth int
+	nodes int
+	nps   int
+	score engineScore // from White's point of view
+	move  string      // the best move so far, in UCI notation
+}
+
+// update takes in the fields of an info line, sign turning its score to
+// White's point of view. It reports whether the line said anything about
+// the search rather than, say, the engine's settings.
+func (p *searchProgress) update(fields []string, sign int) bool {
+	updated := false
+	for key, value := range map[string]*int{"depth": &p.depth, "nodes": &p.nodes, "nps": &p.nps} {
+		if v, ok := infoValue(fields, key); ok {
+			if n, err := strconv.Atoi(v); err == nil {
+				*value = n
+				updated = true
+			}
+		}
+	}
+	if s, ok := parseScore(fields); ok {
+		p.score = engineScore{cp: sign * s.cp, mate: sign * s.mate}
+	}
+	// only the best line counts with MultiPV
+	if rank, ok := infoValue(fields, "multipv"); !ok || rank == "1" {
+		if mv, ok := infoValue(fields, "pv"); ok {
+			p.move = mv
+		}
+	}
+	return updated
+}
+
+// engineProgressMsg carries the progress of the engine thinking about its
+// move. More follows on next until the search ends.
+type engineProgressMsg struct {
+	engine   *uciEngine
+	progress searchProgress
+	next     <-chan searchProgress
+}
+
+// listenProgress waits for the next report of the search's progress.
+func listenProgress(engine *uciEngine, progress <-chan searchProgress) tea.Cmd {
+	return func() tea.Msg {
+		p, ok := <-progress
+		if !ok {
+			return nil
+		}
+		return engineProgressMsg{engine: engine, progress: p, next: progress}
+	}
+}
+
+func (m *model) handleEngineProgress(msg engineProgressMsg) tea.Cmd {
+	if msg.engine == m.engine && m.engineThinking {
+		m.engineProgress = &msg.progress
+	}
+	return listenProgress(msg.engine, msg.next)
+}
+
+// renderProgress is a line such as "depth 18 · Nf3 +0.35 · 1.2M nodes at
+// 850k/s", the move in SAN for the position searched.
+func renderProgress(p searchProgress, pos *chess.Position) string {
+	s := fmt.Sprintf("depth %d", p.depth)
+	if p.move != "" {
+		move := p.move
+		if mv, err := (chess.UCINotation{}).Decode(pos, p.move); err == nil {
+			move = chess.AlgebraicNotation{}.Encode(pos, mv)
+		}
+		s += " · " + move + " " + p.score.String()
+	}
+	if p.nodes > 0 {
+		s += " · " + siCount(p.nodes) + " nodes"
+		if p.nps > 0 {
+			s += " at " + siCount(p.nps) + "/s"
+		}
+	}
+	return s
+}
+
+// siCount abbreviates large counts: 1234567 is 1.2M.
+func siCount(n int) string {
+	switch {
+	case n >= 1_000_000_000:
+		return fmt.Sprintf("%.1fG", float64(n)/1e9)
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1_000:
+		return fmt.Sprintf("%dk", n/1000)
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// newRNG returns the source of the random choices, seeded with seed so that
+// a -seed given twice makes the same choices, or from the time when seed
+// is 0.
+func newRNG(seed uint64) *rand.Rand {
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+	return rand.New(rand.NewPCG(seed, seed))
+}
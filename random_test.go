@@ -0,0 +1,58 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// demoMoves plays plies random moves of a demo seeded with seed.
+func demoMoves(t *testing.T, seed uint64, plies int) string {
+	t.Helper()
+	u := &uiModel{t: t, m: initialModel(config{demo: true, seed: seed})}
+	u.send(tea.WindowSizeMsg{Width: 120, Height: 50})
+	for range plies {
+		u.send(demoTickMsg{})
+	}
+	if n := len(u.m.game.Moves()); n != plies {
+		t.Fatalf("demo played %d moves, want %d", n, plies)
+	}
+	return moveList(u.m.game)
+}
+
+// trainerTargets returns the first n squares the trainer asks for with
+// seed.
+func trainerTargets(t *testing.T, seed uint64, n int) []chess.Square {
+	t.Helper()
+	tempConfigDir(t)
+	m := initialModel(config{seed: seed})
+	m.startTrainer()
+	targets := []chess.Square{m.trainer.target}
+	for len(targets) < n {
+		m.trainer.pickTarget()
+		targets = append(targets, m.trainer.target)
+	}
+	return targets
+}
+
+func TestSeedRepeatsDemo(t *testing.T) {
+	first, again := demoMoves(t, 42, 20), demoMoves(t, 42, 20)
+	if first != again {
+		t.Errorf("seed 42 played %q, then %q", first, again)
+	}
+	if other := demoMoves(t, 7, 20); other == first {
+		t.Errorf("seeds 42 and 7 both played %q", first)
+	}
+}
+
+func TestSeedRepeatsTrainer(t *testing.T) {
+	first, again := trainerTargets(t, 42, 10), trainerTargets(t, 42, 10)
+	if !slices.Equal(first, again) {
+		t.Errorf("seed 42 asked for %v, then %v", first, again)
+	}
+	if other := trainerTargets(t, 7, 10); slices.Equal(other, first) {
+		t.Errorf("seeds 42 and 7 both asked for %v", first)
+	}
+}
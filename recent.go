@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+const (
+	recentFile  = "recent.txt"
+	recentGames = 3 // games kept in the list
+)
+
+// readRecent returns the paths of the games saved or loaded last, the most
+// recent first.
+func readRecent() ([]string, error) {
+	path, err := configPath(recentFile)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths, nil
+}
+
+// rememberGame puts path at the top of the recent games.
+func rememberGame(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	paths, err := readRecent()
+	if err != nil {
+		return err
+	}
+	paths = slices.DeleteFunc(paths, func(p string) bool { return p == abs })
+	paths = append([]string{abs}, paths...)
+	if len(paths) > recentGames {
+		paths = paths[:recentGames]
+	}
+	file, err := configPath(recentFile)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(file, []byte(strings.Join(paths, "\n")+"\n"))
+}
+
+// loadGame replaces the game with the one in the PGN file at path.
+func (m *model) loadGame(path string) (tea.Cmd, error) {
+	game, err := loadPGN(path)
+	if err != nil {
+		return nil, err
+	}
+	m.stashedGame, m.stashedHistory = nil, nil
+	cmd := m.startGame(game)
+	m.status = "Game loaded from " + path
+	// the list is a convenience, failing to update it doesn't fail the load
+	rememberGame(path)
+	return cmd, nil
+}
+
+// recentGame is an entry of the recent games menu.
+type recentGame struct {
+	path   string
+	result chess.Outcome
+	date   string // when the file was last written
+}
+
+// recentMenu lists the last games saved or loaded, to load one with a key.
+type recentMenu struct {
+	games    []recentGame
+	selected int
+}
+
+// openRecent shows the recent games menu. Files that were removed since
+// are left out.
+func (m *model) openRecent() error {
+	paths, err := readRecent()
+	if err != nil {
+		return err
+	}
+	var games []recentGame
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		game, err := loadPGN(path)
+		if err != nil {
+			continue
+		}
+		games = append(games, recentGame{
+			path:   path,
+			result: game.Outcome(),
+			date:   info.ModTime().Format("2006-01-02 15:04"),
+		})
+	}
+	if len(games) == 0 {
+		m.status = "No recent games"
+		return nil
+	}
+	m.recent = &recentMenu{games: games}
+	return nil
+}
+
+// updateRecent loads the game with the number typed, or the one selected
+// with the arrow keys on enter. Esc closes the menu.
+func (m model) updateRecent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	games := m.recent.games
+	switch key := msg.String(); key {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.recent = nil
+	case "up", "shift+tab":
+		m.recent.selected = (m.recent.selected + len(games) - 1) % len(games)
+	case "down", "tab":
+		m.recent.selected = (m.recent.selected + 1) % len(games)
+	case "enter":
+		return m.loadRecent(games[m.recent.selected].path)
+	default:
+		if len(key) == 1 && key[0] >= '1' && int(key[0]-'1') < len(games) {
+			return m.loadRecent(games[key[0]-'1'].path)
+		}
+	}
+	return m, nil
+}
+
+func (m model) loadRecent(path string) (tea.Model, tea.Cmd) {
+	m.recent = nil
+	cmd, err := m.loadGame(path)
+	m.error = err
+	return m, cmd
+}
+
+func (r recentMenu) View() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Recent games") + "\n\n")
+	for i, g := range r.games {
+		line := fmt.Sprintf("%d  %-24s %-7s %s", i+1, fitWidth(filepath.Base(g.path), 24), g.result, g.date)
+		if i == r.selected {
+			sb.WriteString(gameOverSelectedStyle.Render(line) + "\n")
+		} else {
+			sb.WriteString(lipgloss.NewStyle().Padding(0, 1).Render(line) + "\n")
+		}
+	}
+	sb.WriteString("\n" + statusMessageStyle.Faint(true).Render(fmt.Sprintf("1-%d or enter load • ↑/↓ choose • esc close", len(r.games))))
+	return helpStyle.Render(sb.String())
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/notnil/chess"
+)
+
+// runRender implements `gochess render [flags] <fen>`, which prints a board
+// and exits without starting the UI.
+func runRender(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: gochess render [flags] <fen>")
+		fs.PrintDefaults()
+	}
+	var opts boardOptions
+	unicode := fs.Bool("unicode", false, "draw pieces with chess symbols (same as a theme with pieces = unicode)")
+	fs.Func("theme", "load board colors and the piece set from this `file`", func(s string) error {
+		var err error
+		opts.theme, err = loadTheme(s)
+		return err
+	})
+	fs.BoolVar(&opts.flipped, "flip", false, "draw the board from Black's side")
+	fs.BoolVar(&opts.noColor, "no-color", false, "don't use colors; black pieces are lowercase")
+	out := fs.String("o", "", "write the board to this file instead of stdout")
+	fs.Func("labels", "where to draw rank/file labels: all, left-bottom or none", func(s string) error {
+		var err error
+		opts.labels, err = parseLabelPlacement(s)
+		return err
+	})
+	fs.Func("scale", "board size: compact, small, normal or large", func(s string) error {
+		var err error
+		opts.scale, err = parseBoardScale(s)
+		return err
+	})
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("missing FEN")
+	}
+
+	// the FEN may be passed as one argument or as its six fields
+	fen, err := chess.FEN(strings.Join(fs.Args(), " "))
+	if err != nil {
+		return err
+	}
+	if *unicode {
+		opts.theme.pieces = piecesUnicode
+	}
+	if opts.noColor {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+	width, _ := opts.size()
+	board := renderBoard(chess.NewGame(fen).Position(), width, opts) + "\n"
+
+	if *out == "" {
+		_, err = fmt.Print(board)
+		return err
+	}
+	return os.WriteFile(*out, []byte(board), 0o644)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/notnil/chess"
+)
+
+const (
+	castWidth      = 30
+	castHeight     = 12
+	castFrameDelay = 1.0 // seconds between plies
+)
+
+// writeCast writes the game as an asciicast v2 recording, one frame per ply,
+// that can be played back with `asciinema play`.
+func writeCast(game *chess.Game, path string, opts boardOptions) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+
+	// grow the terminal for boards that don't fit the default size
+	boardWidth, boardHeight := opts.size()
+	width := max(castWidth, boardWidth)
+	header := map[string]any{
+		"version":   2,
+		"width":     width,
+		"height":    max(castHeight, boardHeight+2),
+		"timestamp": time.Now().Unix(),
+		"title":     "Go Chess replay",
+	}
+	if err := enc.Encode(header); err != nil {
+		return err
+	}
+
+	positions := game.Positions()
+	moves := game.Moves()
+	start := gameStartPly(game)
+	for i, pos := range positions {
+		caption := "Start"
+		if i > 0 {
+			san := chess.AlgebraicNotation{}.Encode(positions[i-1], moves[i-1])
+			caption = plyNumber(start+i-1) + " " + san
+		}
+
+		frame := "\x1b[2J\x1b[H" + caption + "\n\n" + renderBoard(pos, width, opts)
+		frame = strings.ReplaceAll(frame, "\n", "\r\n")
+		event := []any{float64(i) * castFrameDelay, "o", frame}
+		if err := enc.Encode(event); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
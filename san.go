@@ -0,0 +1,82 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/notnil/chess"
+)
+
+var (
+	castleLong   = regexp.MustCompile(`^[0Oo]-?[0Oo]-?[0Oo]$`)
+	castleShort  = regexp.MustCompile(`^[0Oo]-?[0Oo]$`)
+	enPassant    = regexp.MustCompile(`\s*\(?e\.?p\.?\)?$`)
+	bareQueening = regexp.MustCompile(`^([a-h](?:x[a-h])?[18])=?([nbrqNBRQ])$`)
+)
+
+// sanFixes rewrite the usual ways of typing a move that the strict SAN
+// parser doesn't take, in order. Each one leaves moves it doesn't apply to
+// alone.
+var sanFixes = []struct {
+	name string
+	fix  func(string) string
+}{
+	// Nf3! and e4?! are fine, but a wrong or missing check mark is not
+	{"annotations", func(s string) string { return strings.TrimRight(s, "!?+#") }},
+	{"en passant", func(s string) string { return enPassant.ReplaceAllString(s, "") }},
+	{"castling", func(s string) string {
+		switch {
+		case castleLong.MatchString(s):
+			return "O-O-O"
+		case castleShort.MatchString(s):
+			return "O-O"
+		}
+		return s
+	}},
+	// e8q, e8Q and e8=q are e8=Q
+	{"promotion", func(s string) string {
+		return bareQueening.ReplaceAllStringFunc(s, func(m string) string {
+			parts := bareQueening.FindStringSubmatch(m)
+			return parts[1] + "=" + strings.ToUpper(parts[2])
+		})
+	}},
+}
+
+// normalizeSAN applies every fix of sanFixes to a typed move.
+func normalizeSAN(s string) string {
+	s = strings.TrimSpace(s)
+	for _, f := range sanFixes {
+		s = f.fix(s)
+	}
+	return s
+}
+
+// decodeMove reads a move typed in the locale's SAN. Moves the parser
+// doesn't take as typed are tried again normalized, and then with a
+// lowercase piece letter capitalized: nf3 is Nf3, while bxc3 stays a pawn
+// capture as long as one is legal. The error is the one for the move as
+// typed.
+func (l pieceLocale) decodeMove(pos *chess.Position, input string) (*chess.Move, error) {
+	input = strings.TrimSpace(input)
+	mv, err := chess.AlgebraicNotation{}.Decode(pos, l.parseSAN(input))
+	if err == nil {
+		return mv, nil
+	}
+	normalized := normalizeSAN(input)
+	candidates := []string{normalized}
+	if r := []rune(normalized); len(r) > 1 && unicode.IsLower(r[0]) {
+		upper := string(unicode.ToUpper(r[0]))
+		for _, t := range sanPieces {
+			if l.letter(t) == upper {
+				candidates = append(candidates, upper+string(r[1:]))
+			}
+		}
+	}
+	for _, c := range candidates {
+		if mv, err := (chess.AlgebraicNotation{}).Decode(pos, l.parseSAN(c)); err == nil {
+			return mv, nil
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/notnil/chess"
+)
+
+// sanFixTests has the cases of each fix of sanFixes, by name: typed
+// moves and what the fix makes of them.
+var sanFixTests = map[string][]struct{ in, want string }{
+	"annotations": {
+		{"Nf3!", "Nf3"},
+		{"e4?!", "e4"},
+		{"Qh4#", "Qh4"},
+		{"Bb5+", "Bb5"},
+		{"Qxf7+!!", "Qxf7"},
+		{"O-O", "O-O"},
+	},
+	"en passant": {
+		{"exd6 e.p.", "exd6"},
+		{"exd6ep", "exd6"},
+		{"exd6 (ep)", "exd6"},
+		{"exd6", "exd6"},
+	},
+	"castling": {
+		{"0-0", "O-O"},
+		{"o-o", "O-O"},
+		{"OO", "O-O"},
+		{"0-0-0", "O-O-O"},
+		{"ooo", "O-O-O"},
+		{"O-O", "O-O"},
+		{"Ke2", "Ke2"},
+	},
+	"promotion": {
+		{"e8q", "e8=Q"},
+		{"e8Q", "e8=Q"},
+		{"e8=q", "e8=Q"},
+		{"exd1n", "exd1=N"},
+		{"e8=Q", "e8=Q"},
+		{"e7", "e7"},
+		{"Bb8", "Bb8"},
+	},
+}
+
+func TestSANFixes(t *testing.T) {
+	for _, f := range sanFixes {
+		t.Run(f.name, func(t *testing.T) {
+			tests, ok := sanFixTests[f.name]
+			if !ok {
+				t.Fatalf("no tests for the %s fix", f.name)
+			}
+			for _, tt := range tests {
+				if got := f.fix(tt.in); got != tt.want {
+					t.Errorf("%q = %q, want %q", tt.in, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeSAN(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"  0-0+ ", "O-O"},
+		{"exd6 e.p.+", "exd6"},
+		{"e8q+", "e8=Q"},
+		{"bxa1=n#", "bxa1=N"},
+	}
+	for _, tt := range tests {
+		if got := normalizeSAN(tt.in); got != tt.want {
+			t.Errorf("normalizeSAN(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeMoveNormalized(t *testing.T) {
+	tests := []struct {
+		fen   string
+		moves []string
+		input string
+		want  string
+	}{
+		{startFEN, nil, "nf3", "Nf3"},
+		{startFEN, nil, "Nf3!?", "Nf3"},
+		{startFEN, []string{"e4", "a6", "e5", "d5"}, "exd6 e.p.", "exd6"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", nil, "0-0-0", "O-O-O"},
+		{"8/4P3/8/8/8/8/k7/4K3 w - - 0 1", nil, "e8n", "e8=N"},
+		// a pawn capture when there is one, not a bishop move
+		{"4k3/8/8/8/8/2n5/1P1B4/4K3 w - - 0 1", nil, "bxc3", "bxc3"},
+		{"4k3/8/8/8/8/2n5/3B4/4K3 w - - 0 1", nil, "bxc3", "Bxc3"},
+	}
+	for _, tt := range tests {
+		pos := newTestGameFrom(t, tt.fen, tt.moves...).Position()
+		mv, err := pieceLocale{}.decodeMove(pos, tt.input)
+		if err != nil {
+			t.Errorf("decodeMove(%q): %v", tt.input, err)
+			continue
+		}
+		if got := (chess.AlgebraicNotation{}).Encode(pos, mv); got != tt.want {
+			t.Errorf("decodeMove(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// overwritePolicy is what :save does when the file already exists.
+type overwritePolicy int
+
+const (
+	overwriteAsk    overwritePolicy = iota // ask before replacing the file
+	overwriteSuffix                        // save next to it as game-1.pgn, game-2.pgn, …
+	overwriteAlways                        // replace the file
+)
+
+func parseOverwritePolicy(s string) (overwritePolicy, error) {
+	switch s {
+	case "ask":
+		return overwriteAsk, nil
+	case "suffix":
+		return overwriteSuffix, nil
+	case "always":
+		return overwriteAlways, nil
+	default:
+		return overwriteAsk, fmt.Errorf("unknown overwrite policy %q (want ask, suffix or always)", s)
+	}
+}
+
+// exists reports whether there is a file at path.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, fs.ErrNotExist)
+}
+
+// freePath returns the first of path, path-1, path-2, … (the number going
+// before the extension) that no file has.
+func freePath(path string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for n := 1; exists(path); n++ {
+		path = fmt.Sprintf("%s-%d%s", base, n, ext)
+	}
+	return path
+}
+
+// saveGame writes the game as PGN to path, following -overwrite when the
+// file exists already: with the default policy it asks first.
+func (m *model) saveGame(path string) error {
+	if exists(path) {
+		switch m.overwrite {
+		case overwriteAsk:
+			m.pendingSave = path
+			return nil
+		case overwriteSuffix:
+			path = freePath(path)
+		}
+	}
+	return m.writeGame(path)
+}
+
+func (m *model) writeGame(path string) error {
+	if err := os.WriteFile(path, []byte(exportPGN(m.game, m.annotations, m.engineNotes())), 0o644); err != nil {
+		return err
+	}
+	m.status = "Game saved to " + path
+	// the list is a convenience, failing to update it doesn't fail the save
+	rememberGame(path)
+	return nil
+}
+
+// answerOverwrite handles the answer to whether to replace the file being
+// saved to.
+func (m *model) answerOverwrite(msg tea.KeyMsg) tea.Cmd {
+	path := m.pendingSave
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "y":
+		m.pendingSave = ""
+		m.error = m.writeGame(path)
+	case "n", "esc":
+		m.pendingSave = ""
+		m.status = "Not saved"
+	}
+	return nil
+}
+
+// overwritePrompt is shown while a save waits for permission to replace
+// the file.
+func (m model) overwritePrompt() string {
+	return fmt.Sprintf("%s exists, overwrite? (y/n)", m.pendingSave)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+const (
+	// idleCheckInterval is how often the time since the last input is
+	// checked against -screensaver.
+	idleCheckInterval = 5 * time.Second
+	// screensaverMoveDelay is the pause between the moves of a famous game.
+	screensaverMoveDelay = 2 * time.Second
+	// screensaverRestartDelay is how long the end of a famous game stays on
+	// screen before the next one starts.
+	screensaverRestartDelay = 10 * time.Second
+)
+
+// famousGame is a game from chess history played through by the
+// screensaver, with notes shown as its key moves are played.
+type famousGame struct {
+	name  string
+	moves string
+	notes map[int]string // by ply, 1 being White's first move
+}
+
+var famousGames = []famousGame{
+	{
+		name:  "The Opera Game · Morphy vs Duke Karl and Count Isouard, Paris 1858",
+		moves: "1. e4 e5 2. Nf3 d6 3. d4 Bg4 4. dxe5 Bxf3 5. Qxf3 dxe5 6. Bc4 Nf6 7. Qb3 Qe7 8. Nc3 c6 9. Bg5 b5 10. Nxb5 cxb5 11. Bxb5+ Nbd7 12. O-O-O Rd8 13. Rxd7 Rxd7 14. Rd1 Qe6 15. Bxd7+ Nxd7 16. Qb8+ Nxb8 17. Rd8#",
+		notes: map[int]string{
+			8:  "Black gives up the bishop pair early",
+			13: "Qb3 hits b7 and f7 at once",
+			19: "Nxb5! Morphy gives a knight to open lines against the king",
+			23: "Castling brings the last piece into the attack",
+			31: "Qb8+!! The queen is given up to deflect the knight",
+			33: "Mate with the last two pieces",
+		},
+	},
+	{
+		name:  "The Immortal Game · Anderssen vs Kieseritzky, London 1851",
+		moves: "1. e4 e5 2. f4 exf4 3. Bc4 Qh4+ 4. Kf1 b5 5. Bxb5 Nf6 6. Nf3 Qh6 7. d3 Nh5 8. Nh4 Qg5 9. Nf5 c6 10. g4 Nf6 11. Rg1 cxb5 12. h4 Qg6 13. h5 Qg5 14. Qf3 Ng8 15. Bxf4 Qf6 16. Nc3 Bc5 17. Nd5 Qxb2 18. Bd6 Bxg1 19. e5 Qxa1+ 20. Ke2 Na6 21. Nxg7+ Kd8 22. Qf6+ Nxf6 23. Be7#",
+		notes: map[int]string{
+			3:  "The King's Gambit",
+			21: "Rg1, giving up the rook for time",
+			35: "Bd6!? Both rooks are offered",
+			37: "e5 shuts the black queen out of the defence",
+			43: "Qf6+!! and the queen goes too",
+			45: "Mate by three minor pieces against a full army",
+		},
+	},
+	{
+		name:  "The Evergreen Game · Anderssen vs Dufresne, Berlin 1852",
+		moves: "1. e4 e5 2. Nf3 Nc6 3. Bc4 Bc5 4. b4 Bxb4 5. c3 Ba5 6. d4 exd4 7. O-O d3 8. Qb3 Qf6 9. e5 Qg6 10. Re1 Nge7 11. Ba3 b5 12. Qxb5 Rb8 13. Qa4 Bb6 14. Nbd2 Bb7 15. Ne4 Qf5 16. Bxd3 Qh5 17. Nf6+ gxf6 18. exf6 Rg8 19. Rad1 Qxf3 20. Rxe7+ Nxe7 21. Qxd7+ Kxd7 22. Bf5+ Ke8 23. Bd7+ Kf8 24. Bxe7#",
+		notes: map[int]string{
+			7:  "The Evans Gambit gives a pawn for the centre",
+			33: "Nf6+ opens the g-file and the e-file",
+			37: "Rad1! lets Black take the knight with check to come",
+			41: "Qxd7+!! the queen for a mating net",
+			47: "Mate by the two bishops",
+		},
+	},
+	{
+		name:  "The Game of the Century · D. Byrne vs Fischer, New York 1956",
+		moves: "1. Nf3 Nf6 2. c4 g6 3. Nc3 Bg7 4. d4 O-O 5. Bf4 d5 6. Qb3 dxc4 7. Qxc4 c6 8. e4 Nbd7 9. Rd1 Nb6 10. Qc5 Bg4 11. Bg5 Na4 12. Qa3 Nxc3 13. bxc3 Nxe4 14. Bxe7 Qb6 15. Bc4 Nxc3 16. Bc5 Rfe8+ 17. Kf1 Be6 18. Bxb6 Bxc4+ 19. Kg1 Ne2+ 20. Kf1 Nxd4+ 21. Kg1 Ne2+ 22. Kf1 Nc3+ 23. Kg1 axb6 24. Qb4 Ra4 25. Qxb6 Nxd1 26. h3 Rxa2 27. Kh2 Nxf2 28. Re1 Rxe1 29. Qd8+ Bf8 30. Nxe1 Bd5 31. Nf3 Ne4 32. Qb8 b5 33. h4 h5 34. Ne5 Kg7 35. Kg1 Bc5+ 36. Kf1 Ng3+ 37. Ke1 Bb4+ 38. Kd1 Bb3+ 39. Kc1 Ne2+ 40. Kb1 Nc3+ 41. Kc1 Rc2#",
+		notes: map[int]string{
+			22: "Na4!! The thirteen-year-old Fischer offers a knight",
+			34: "Be6!! and now the queen",
+			38: "A windmill of discovered checks follows",
+			46: "Black has a rook, two bishops and a knight for the queen",
+			82: "The minor pieces hunt the king down",
+		},
+	},
+}
+
+// screensaver plays through the famous games while the user is away, in a
+// session of its own so that the user's game stays as it was.
+type screensaver struct {
+	game  int // index in famousGames
+	moves []*chess.Move
+}
+
+// screensaverTickMsg plays the next move of the screensaver started with the
+// given sequence number.
+type screensaverTickMsg int
+
+// idleCheckMsg checks whether the user has been away long enough for the
+// screensaver.
+type idleCheckMsg struct{}
+
+func idleCheck() tea.Cmd {
+	return tea.Tick(idleCheckInterval, func(time.Time) tea.Msg {
+		return idleCheckMsg{}
+	})
+}
+
+func screensaverTick(seq int, d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return screensaverTickMsg(seq)
+	})
+}
+
+// handleIdleCheck starts the screensaver once there has been no input for
+// -screensaver, unless something is going on that it would hide: a clock
+// running, the engine thinking, a match or demo playing, or a menu open.
+func (m *model) handleIdleCheck() tea.Cmd {
+	busy := m.demo || m.match != nil || m.engineThinking || m.modalActive() || m.trainer != nil ||
+		m.clock != nil && m.clock.running
+	if m.screensaver == nil && !busy && time.Since(m.lastInput) >= m.screensaverAfter {
+		return tea.Batch(m.startScreensaver(), idleCheck())
+	}
+	return idleCheck()
+}
+
+// startScreensaver sets the user's game aside and starts playing through
+// the next famous game.
+func (m *model) startScreensaver() tea.Cmd {
+	i := m.screensaverNext
+	m.screensaverNext = (i + 1) % len(famousGames)
+	game := chess.NewGame()
+	for _, san := range movetextTokens(famousGames[i].moves) {
+		if err := game.MoveStr(san); err != nil {
+			m.error = err
+			return nil
+		}
+	}
+	m.screensaver = &screensaver{game: i, moves: game.Moves()}
+	m.screensaverSeq++
+	m.session = newSession()
+	m.error = nil
+	m.status = ""
+	m.resetInput()
+	m.positionChanged()
+	m.resizeHistory(m.historyWidth)
+	return screensaverTick(m.screensaverSeq, screensaverMoveDelay)
+}
+
+// handleScreensaverTick plays the next move of the famous game, and moves on
+// to the next game some time after the last move.
+func (m *model) handleScreensaverTick(msg screensaverTickMsg) tea.Cmd {
+	s := m.screensaver
+	if s == nil || int(msg) != m.screensaverSeq {
+		return nil
+	}
+	ply := len(m.game.Moves())
+	if ply == len(s.moves) {
+		return m.startScreensaver()
+	}
+	if err := m.game.Move(s.moves[ply]); err != nil {
+		m.error = err
+		return nil
+	}
+	// not moveApplied: there is nothing to sound, save or evaluate
+	m.history = append(m.history, lastMoveSAN(m.game))
+	m.positionChanged()
+	m.updateHistoryViewport()
+	if note, ok := famousGames[s.game].notes[ply+1]; ok {
+		m.status = note
+	}
+	if ply+1 == len(s.moves) {
+		return screensaverTick(m.screensaverSeq, screensaverRestartDelay)
+	}
+	return screensaverTick(m.screensaverSeq, screensaverMoveDelay)
+}
+
+// stopScreensaver returns to the user's game as it was left.
+func (m *model) stopScreensaver() {
+	m.screensaver = nil
+	m.session = m.sessions[m.active]
+	m.error = nil
+	m.status = ""
+	m.resetInput()
+	m.positionChanged()
+	m.resizeHistory(m.historyWidth)
+}
+
+// renderScreensaverStatus names the game being played through in place of
+// the status of the user's game. Its notes show on the status line.
+func (m model) renderScreensaverStatus() string {
+	name := titleStyle.Render(famousGames[m.screensaver.game].name)
+	hint := statusMessageStyle.Faint(true).Render("Press any key to return to your game")
+	return lipgloss.PlaceHorizontal(m.width, lipgloss.Center, lipgloss.JoinVertical(lipgloss.Center, name, hint))
+}
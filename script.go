@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// applyMoves plays the moves read from r, one per line, stopping at the
+// first one that is illegal. Blank lines are skipped.
+func applyMoves(game *chess.Game, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		move := strings.TrimSpace(scanner.Text())
+		if move == "" {
+			continue
+		}
+		if err := game.MoveStr(move); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// movetextTokens splits PGN-style movetext such as "1. e4 e5 2.Nf3 Nc6!"
+// into moves. Move numbers, results, NAGs, annotation marks, comments and
+// variations are dropped.
+func movetextTokens(text string) []string {
+	var plain strings.Builder
+	depth := 0 // nesting of variations and comments
+	for _, r := range text {
+		switch {
+		case r == '(' || r == '{':
+			depth++
+		case (r == ')' || r == '}') && depth > 0:
+			depth--
+		case depth == 0:
+			plain.WriteRune(r)
+		}
+	}
+
+	var moves []string
+	for _, field := range strings.Fields(plain.String()) {
+		// a move number may be written without a space: 1.e4 or 1...e5
+		if i := strings.LastIndex(field, "."); i >= 0 && strings.Trim(field[:i+1], "0123456789.") == "" {
+			field = field[i+1:]
+		}
+		field = strings.TrimRight(field, "!?")
+		switch {
+		case field == "", strings.HasPrefix(field, "$"):
+		case field == "1-0", field == "0-1", field == "1/2-1/2", field == "*":
+		default:
+			moves = append(moves, field)
+		}
+	}
+	return moves
+}
+
+// readMoves applies the moves from the named file, or stdin for "-".
+func readMoves(game *chess.Game, path string) error {
+	if path == "-" {
+		return applyMoves(game, os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return applyMoves(game, f)
+}
+
+// printGame writes the final board of a headless run along with the
+// game's FEN and outcome.
+func printGame(w io.Writer, game *chess.Game, opts boardOptions) {
+	boardWidth, _ := opts.size()
+	fmt.Fprintln(w, renderBoard(game.Position(), boardWidth, opts))
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, game.Position().String())
+	if game.Outcome() != chess.NoOutcome {
+		fmt.Fprintln(w, resultString(game))
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+var (
+	diffAddedStyle   = lipgloss.NewStyle().Background(lipgloss.Color("#7DAF5A"))
+	diffRemovedStyle = lipgloss.NewStyle().Background(lipgloss.Color("#C0605A"))
+	diffChangedStyle = lipgloss.NewStyle().Background(lipgloss.Color("#D6B04C"))
+)
+
+// boardDiff compares two boards square by square. Squares that gained a
+// piece, lost one, or hold a different piece get their own color.
+func boardDiff(before, after *chess.Board) map[chess.Square]lipgloss.Style {
+	diff := map[chess.Square]lipgloss.Style{}
+	for sq := chess.A1; sq <= chess.H8; sq++ {
+		was, is := before.Piece(sq), after.Piece(sq)
+		switch {
+		case was == is:
+		case was == chess.NoPiece:
+			diff[sq] = diffAddedStyle
+		case is == chess.NoPiece:
+			diff[sq] = diffRemovedStyle
+		default:
+			diff[sq] = diffChangedStyle
+		}
+	}
+	return diff
+}
+
+// takeSnapshot remembers the board shown to compare positions against.
+func (m *model) takeSnapshot() {
+	m.snapshot = m.displayedPosition().Board()
+	m.status = "Snapshot saved"
+}
+
+// toggleCompare turns highlighting the differences from the snapshot on
+// or off.
+func (m *model) toggleCompare() error {
+	if m.snapshot == nil {
+		return errors.New("no snapshot saved yet, use :snapshot first")
+	}
+	m.comparing = !m.comparing
+	if m.comparing {
+		m.status = fmt.Sprintf("%d squares differ from the snapshot", len(m.snapshotHighlights()))
+	}
+	return nil
+}
+
+// snapshotHighlights marks the squares that differ from the snapshot while
+// comparing.
+func (m model) snapshotHighlights() map[chess.Square]lipgloss.Style {
+	if !m.comparing || m.snapshot == nil {
+		return nil
+	}
+	return boardDiff(m.snapshot, m.displayedPosition().Board())
+}
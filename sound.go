@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// soundEvent identifies what kind of move was just played, or that a game
+// started or ended.
+type soundEvent string
+
+const (
+	soundMove      soundEvent = "move"
+	soundCapture   soundEvent = "capture"
+	soundCastle    soundEvent = "castle"
+	soundEnPassant soundEvent = "enpassant"
+	soundPromotion soundEvent = "promotion"
+	soundCheck     soundEvent = "check"
+
+	soundStart soundEvent = "start"
+	// against the engine the result is the user's; between two players
+	// at the keyboard any decisive result is a win
+	soundWin  soundEvent = "win"
+	soundLoss soundEvent = "loss"
+	soundDraw soundEvent = "draw"
+)
+
+// defaultSounds rings the terminal bell a different number of times per event.
+var defaultSounds = map[soundEvent]string{
+	soundCapture:   "bell",
+	soundCastle:    "bell:2",
+	soundEnPassant: "bell:2",
+	soundPromotion: "bell:3",
+	soundCheck:     "bell:2",
+	soundStart:     "bell",
+	soundWin:       "bell:3",
+	soundLoss:      "bell",
+	soundDraw:      "bell:2",
+}
+
+const bellInterval = 150 * time.Millisecond
+
+// audioPlayers are tried in order to play sound files.
+var audioPlayers = []string{"afplay", "paplay", "aplay", "play"}
+
+// parseSoundMap parses a comma separated list of event=sound pairs, where a
+// sound is either "bell", "bell:N" or the path of an audio file, on top of
+// the default sounds.
+func parseSoundMap(spec string) (map[soundEvent]string, error) {
+	sounds := make(map[soundEvent]string, len(defaultSounds))
+	for ev, snd := range defaultSounds {
+		sounds[ev] = snd
+	}
+	if spec == "" {
+		return sounds, nil
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		ev, snd, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid sound mapping %q, want event=sound", pair)
+		}
+		switch event := soundEvent(strings.TrimSpace(ev)); event {
+		case soundMove, soundCapture, soundCastle, soundEnPassant, soundPromotion, soundCheck,
+			soundStart, soundWin, soundLoss, soundDraw:
+			sounds[event] = strings.TrimSpace(snd)
+		default:
+			return nil, fmt.Errorf("unknown sound event %q", ev)
+		}
+	}
+	return sounds, nil
+}
+
+// moveSoundEvent classifies a move, preferring the most notable event.
+func moveSoundEvent(mv *chess.Move) soundEvent {
+	switch {
+	case mv.HasTag(chess.Check):
+		return soundCheck
+	case mv.Promo() != chess.NoPieceType:
+		return soundPromotion
+	case mv.HasTag(chess.KingSideCastle), mv.HasTag(chess.QueenSideCastle):
+		return soundCastle
+	case mv.HasTag(chess.EnPassant):
+		return soundEnPassant
+	case mv.HasTag(chess.Capture):
+		return soundCapture
+	default:
+		return soundMove
+	}
+}
+
+// outcomeSoundEvent classifies the end of a game for the side the user
+// plays, NoColor when both sides are the user's.
+func outcomeSoundEvent(outcome chess.Outcome, user chess.Color) soundEvent {
+	switch {
+	case outcome == chess.Draw:
+		return soundDraw
+	case user == chess.NoColor,
+		outcome == chess.WhiteWon && user == chess.White,
+		outcome == chess.BlackWon && user == chess.Black:
+		return soundWin
+	default:
+		return soundLoss
+	}
+}
+
+// endSound returns a command playing the sound for how the game ended.
+func (m model) endSound() tea.Cmd {
+	if m.sounds == nil {
+		return nil
+	}
+	user := chess.NoColor
+	if m.engine != nil && m.engineColor != chess.NoColor {
+		user = m.engineColor.Other()
+	}
+	return playSound(m.sounds, outcomeSoundEvent(m.game.Outcome(), user))
+}
+
+// startSound returns a command playing the sound for a game starting from
+// its first move.
+func (m model) startSound() tea.Cmd {
+	if m.sounds == nil || len(m.game.Moves()) > 0 || m.game.Outcome() != chess.NoOutcome {
+		return nil
+	}
+	return playSound(m.sounds, soundStart)
+}
+
+// playSound returns a command playing the sound mapped to the event, if any.
+func playSound(sounds map[soundEvent]string, event soundEvent) tea.Cmd {
+	snd := sounds[event]
+	if snd == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		if snd == "bell" || strings.HasPrefix(snd, "bell:") {
+			times := 1
+			if n, err := strconv.Atoi(strings.TrimPrefix(snd, "bell:")); err == nil {
+				times = n
+			}
+			for i := range times {
+				if i > 0 {
+					time.Sleep(bellInterval)
+				}
+				fmt.Fprint(os.Stdout, "\a")
+			}
+			return nil
+		}
+		for _, player := range audioPlayers {
+			if path, err := exec.LookPath(player); err == nil {
+				// Wait for the player in the background so it doesn't linger as a zombie.
+				cmd := exec.Command(path, snd)
+				if cmd.Start() == nil {
+					go cmd.Wait()
+				}
+				break
+			}
+		}
+		return nil
+	}
+}
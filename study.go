@@ -0,0 +1,289 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// studyPosition is a position to find the right moves in, with a hint to
+// ask for and an explanation shown once it is solved.
+type studyPosition struct {
+	fen         string
+	solution    []string // in algebraic notation, the user's moves and the replies in turn
+	hint        string
+	explanation string
+}
+
+// studySet is a set of positions being studied, one at a time.
+type studySet struct {
+	positions []studyPosition
+	solved    []bool
+	current   int
+	hintShown bool
+	revealed  bool // the solution was asked for
+}
+
+// loadStudy reads study positions from a file of key: value lines, one
+// position after another separated by blank lines, e.g.
+//
+//	fen: r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4
+//	solution: Qxf7#
+//	hint: Which pawn does only the king defend?
+//	explanation: The queen takes on f7 backed by the bishop.
+//
+// The solution is the moves the user plays and the answers to them in
+// turn, so a position may take several moves to solve. Lines starting
+// with # are skipped.
+func loadStudy(path string) (*studySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &studySet{}
+	var p studyPosition
+	start := 0
+	flush := func() error {
+		if p.fen == "" && p.solution == nil && p.hint == "" && p.explanation == "" {
+			return nil
+		}
+		if err := checkStudyPosition(p); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, start, err)
+		}
+		s.positions = append(s.positions, p)
+		p = studyPosition{}
+		return nil
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == "" {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if p.fen == "" && p.solution == nil && p.hint == "" && p.explanation == "" {
+			start = i + 1
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: want key: value", path, i+1)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "fen":
+			p.fen = value
+		case "solution":
+			p.solution = movetextTokens(value)
+		case "hint":
+			p.hint = value
+		case "explanation":
+			p.explanation = value
+		default:
+			return nil, fmt.Errorf("%s:%d: unknown key %q", path, i+1, key)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	if len(s.positions) == 0 {
+		return nil, errors.New(path + " has no positions")
+	}
+	s.solved = make([]bool, len(s.positions))
+	return s, nil
+}
+
+// checkStudyPosition makes sure the solution can be played from the
+// position.
+func checkStudyPosition(p studyPosition) error {
+	if len(p.solution) == 0 {
+		return errors.New("the position has no solution")
+	}
+	game, err := p.newGame()
+	if err != nil {
+		return err
+	}
+	for _, san := range p.solution {
+		if err := game.MoveStr(san); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p studyPosition) newGame() (*chess.Game, error) {
+	fen, err := chess.FEN(p.fen)
+	if err != nil {
+		return nil, err
+	}
+	return chess.NewGame(fen), nil
+}
+
+// progress returns how many positions have been solved out of how many.
+func (s *studySet) progress() (done, total int) {
+	for _, solved := range s.solved {
+		if solved {
+			done++
+		}
+	}
+	return done, len(s.positions)
+}
+
+// studying reports whether the game being played is a study position.
+func (m model) studying() bool {
+	return m.study != nil && m.studied
+}
+
+// startStudy sets up the i-th position of the study for the user to play
+// the side to move.
+func (m *model) startStudy(i int) (tea.Cmd, error) {
+	s := m.study
+	if i < 0 || i >= len(s.positions) {
+		return nil, fmt.Errorf("no position %d, there are %d", i+1, len(s.positions))
+	}
+	game, err := s.positions[i].newGame()
+	if err != nil {
+		return nil, err
+	}
+	m.engineColor = chess.NoColor
+	m.flipped = game.Position().Turn() == chess.Black
+	m.stashedGame, m.stashedHistory = nil, nil
+	cmd := m.startGame(game)
+	s.current = i
+	m.studied = true
+	s.hintShown, s.revealed = false, false
+	m.status = fmt.Sprintf("Position %d of %d: %s to play", i+1, len(s.positions), game.Position().Turn().Name())
+	return cmd, nil
+}
+
+// checkStudy rejects a move that isn't the next one of the solution while
+// studying. Once the solution has been played out any move may be played.
+func (m *model) checkStudy(mv *chess.Move) error {
+	if !m.studying() || m.mode != modePlay {
+		return nil
+	}
+	solution := m.study.positions[m.study.current].solution
+	ply := len(m.game.Moves())
+	if ply >= len(solution) {
+		return nil
+	}
+	// compared as moves, as the solution may be written without its check
+	// or capture mark
+	want, err := chess.AlgebraicNotation{}.Decode(m.game.Position(), solution[ply])
+	if err == nil && want.S1() == mv.S1() && want.S2() == mv.S2() && want.Promo() == mv.Promo() {
+		return nil
+	}
+	san := chess.AlgebraicNotation{}.Encode(m.game.Position(), mv)
+	return fmt.Errorf("%s is not it, try again or ask for a hint", m.locale.translateSAN(san))
+}
+
+// studyProgress marks the position solved once the solution has been
+// played out.
+func (m *model) studyProgress() {
+	if !m.studying() || m.mode != modePlay {
+		return
+	}
+	s := m.study
+	if len(m.game.Moves()) == len(s.positions[s.current].solution) {
+		// a solution that was given away doesn't count
+		if !s.revealed {
+			s.solved[s.current] = true
+		}
+		done, total := s.progress()
+		m.status = fmt.Sprintf("Solved! %d of %d positions, :study next for the next one", done, total)
+	}
+}
+
+// showStudyHint shows the hint of the position being studied.
+func (m *model) showStudyHint() error {
+	if m.study.positions[m.study.current].hint == "" {
+		return errors.New("this position has no hint")
+	}
+	m.study.hintShown = true
+	return nil
+}
+
+// studyCommand runs :study with its argument: a file to study, next,
+// prev, a position's number, hint, solution or off.
+func (m *model) studyCommand(arg string) (tea.Cmd, error) {
+	if arg == "off" {
+		m.study = nil
+		return nil, nil
+	}
+	if !isStudyStep(arg) {
+		s, err := loadStudy(arg)
+		if err != nil {
+			return nil, err
+		}
+		m.study = s
+		return m.startStudy(0)
+	}
+	s := m.study
+	if s == nil {
+		return nil, errors.New("no study loaded, :study <file> to load one")
+	}
+	switch arg {
+	case "next":
+		return m.startStudy((s.current + 1) % len(s.positions))
+	case "prev":
+		return m.startStudy((s.current + len(s.positions) - 1) % len(s.positions))
+	case "hint", "solution":
+		if !m.studying() {
+			return nil, errors.New("the game is not a study position, :study next to go back")
+		}
+		if arg == "hint" {
+			return nil, m.showStudyHint()
+		}
+		s.revealed = true
+		return nil, nil
+	}
+	n, _ := strconv.Atoi(arg)
+	return m.startStudy(n - 1)
+}
+
+// isStudyStep reports whether the argument of :study moves through the
+// loaded study rather than naming a file.
+func isStudyStep(arg string) bool {
+	switch arg {
+	case "next", "prev", "hint", "solution":
+		return true
+	}
+	_, err := strconv.Atoi(arg)
+	return err == nil
+}
+
+var studyPanelStyle = historyStyle.Padding(0, 1)
+
+// renderStudyPanel shows the progress through the study, the hint once
+// asked for and the explanation once the position is solved or its
+// solution revealed.
+func (m model) renderStudyPanel(width int) string {
+	s := m.study
+	p := s.positions[s.current]
+	done, _ := s.progress()
+	lines := []string{statusMessageStyle.Bold(true).Render(fmt.Sprintf("Study · position %d of %d · %d solved", s.current+1, len(s.positions), done))}
+	if s.hintShown {
+		lines = append(lines, "Hint: "+p.hint)
+	}
+	finished := len(m.game.Moves()) >= len(p.solution)
+	if s.revealed && !finished {
+		lines = append(lines, "Solution: "+m.locale.translateSAN(strings.Join(p.solution, " ")))
+	}
+	if (finished || s.revealed) && p.explanation != "" {
+		lines = append(lines, p.explanation)
+	}
+	if !s.hintShown && !finished && !s.revealed {
+		lines = append(lines, lipgloss.NewStyle().Faint(true).Render(":hint for a hint, :study solution to give up"))
+	}
+	inner := width - studyPanelStyle.GetHorizontalFrameSize()
+	return studyPanelStyle.Width(inner).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// scholarsMate is the position before 4. Qxf7#.
+const scholarsMate = "r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4"
+
+func writeStudy(t *testing.T, content string) *studySet {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "study.txt")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := loadStudy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCheckStudy(t *testing.T) {
+	tests := []struct {
+		name     string
+		solution string
+		move     string
+		ok       bool
+	}{
+		{"as written", "Qxf7#", "Qxf7#", true},
+		{"written without the mate", "Qxf7", "Qxf7#", true},
+		{"another move", "Qxf7#", "Bxf7+", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUIModel(t)
+			u.m.study = writeStudy(t, "fen: "+scholarsMate+"\nsolution: "+tt.solution+"\n")
+			if _, err := u.m.startStudy(0); err != nil {
+				t.Fatal(err)
+			}
+			u.enter(tt.move)
+			if played := len(u.m.game.Moves()) == 1; played != tt.ok {
+				t.Errorf("%s played = %v against the solution %s, want %v (error %v)", tt.move, played, tt.solution, tt.ok, u.m.error)
+			}
+			if tt.ok && !u.m.study.solved[0] {
+				t.Error("the position wasn't solved")
+			}
+		})
+	}
+}
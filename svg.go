@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+const (
+	svgSquare = 45 // the side of a square, in pixels
+	svgMargin = 20 // the room around the board for its coordinates
+)
+
+// svgColor returns c as a color SVG understands, or fallback for ANSI color
+// numbers, whose colors only the terminal knows.
+func svgColor(c lipgloss.TerminalColor, fallback string) string {
+	if color, ok := c.(lipgloss.Color); ok {
+		if _, ok := luminance(color); ok {
+			return string(color)
+		}
+	}
+	return fallback
+}
+
+// renderSVG draws the position as an SVG image in the theme's colors, from
+// Black's side when flipped. Pieces are the filled chess symbols of the
+// viewer's fonts, outlined in the other side's color so that they stand
+// out on either square.
+func renderSVG(pos *chess.Position, flipped bool, t theme) string {
+	size := 8*svgSquare + 2*svgMargin
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", size, size, size, size)
+	fmt.Fprintf(&sb, `<rect width="%d" height="%d" fill="#FFFFFF"/>`+"\n", size, size)
+
+	board := pos.Board()
+	for sq := chess.A1; sq <= chess.H8; sq++ {
+		col, row := int(sq.File()), 7-int(sq.Rank())
+		if flipped {
+			col, row = 7-col, 7-row
+		}
+		x, y := svgMargin+col*svgSquare, svgMargin+row*svgSquare
+		dark := (int(sq.File())+int(sq.Rank()))%2 == 0
+		bg := t.squareStyle(dark).GetBackground()
+		fallback := "#DEBA90"
+		if dark {
+			fallback = "#BC7342"
+		}
+		fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", x, y, svgSquare, svgSquare, svgColor(bg, fallback))
+
+		piece := board.Piece(sq)
+		if piece == chess.NoPiece {
+			continue
+		}
+		fg := t.pieceOn(piece.Color(), bg).GetForeground()
+		fill, outline := svgColor(fg, "#FFFFFF"), "#000000"
+		if piece.Color() == chess.Black {
+			fill, outline = svgColor(fg, "#000000"), "#FFFFFF"
+		}
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" font-size="%d" text-anchor="middle" dominant-baseline="central" fill="%s" stroke="%s" stroke-width="1">%s</text>`+"\n",
+			x+svgSquare/2, y+svgSquare/2, svgSquare*4/5, fill, outline, unicodePieces[chess.NewPiece(piece.Type(), chess.Black)])
+	}
+
+	for i := range 8 {
+		file, rank := i, 7-i
+		if flipped {
+			file, rank = 7-i, i
+		}
+		center := svgMargin + i*svgSquare + svgSquare/2
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" font-size="14" font-family="sans-serif" text-anchor="middle" fill="#555555">%s</text>`+"\n",
+			center, size-svgMargin/3, chess.File(file).String())
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" font-size="14" font-family="sans-serif" text-anchor="middle" dominant-baseline="central" fill="#555555">%s</text>`+"\n",
+			svgMargin/2, center, chess.Rank(rank).String())
+	}
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+// exportSVG writes the position on the board to path as SVG, the way round
+// and in the colors it is shown.
+func (m *model) exportSVG(path string) error {
+	svg := renderSVG(m.displayedPosition(), m.boardFlipped(), m.theme)
+	if err := os.WriteFile(path, []byte(svg), 0o644); err != nil {
+		return err
+	}
+	m.status = "Board saved to " + path
+	return nil
+}
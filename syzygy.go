@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/notnil/chess"
+)
+
+// tablebaseWin is the centipawn score from which the engine's scores are
+// tablebase wins rather than evaluations. Stockfish reports them as 20000
+// less the plies to the conversion.
+const tablebaseWin = 10000
+
+// syzygyPieces returns how many pieces the largest Syzygy tables in the
+// directories of path cover, 0 when there are none: KQvK.rtbw covers 3.
+func syzygyPieces(path string) int {
+	n := 0
+	for _, dir := range filepath.SplitList(path) {
+		names, _ := filepath.Glob(filepath.Join(dir, "*.rtbw"))
+		for _, name := range names {
+			table := strings.TrimSuffix(filepath.Base(name), ".rtbw")
+			n = max(n, len(strings.ReplaceAll(table, "v", "")))
+		}
+	}
+	return n
+}
+
+// useSyzygy points the engine at the tablebases in path. The probing is
+// left to the engine, which finds the exact result of positions with few
+// enough pieces. UCI has no way to report the distance to zeroing though,
+// only that the score came from the tables.
+func (e *uciEngine) useSyzygy(path string) error {
+	if syzygyPieces(path) == 0 {
+		return errors.New("no Syzygy tables in " + path)
+	}
+	return e.setOption("SyzygyPath", path)
+}
+
+// tablebaseResult describes the theoretical result of a position the
+// engine probed the tables for, or returns "" when its score is not exact,
+// as for wins the fifty-move rule turns into draws.
+func tablebaseResult(s engineScore, tbhits int, pos *chess.Position, pieces int) string {
+	if tbhits == 0 || len(pos.Board().SquareMap()) > pieces {
+		return ""
+	}
+	switch {
+	case s.mate > 0 || s.cp >= tablebaseWin:
+		return "White wins"
+	case s.mate < 0 || s.cp <= -tablebaseWin:
+		return "Black wins"
+	case s.cp == 0:
+		return "draw"
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// maxTabs limits how many games can be open at once.
+const maxTabs = 9
+
+var inactiveTabStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.AdaptiveColor{Light: "#BC7342", Dark: "#BC7342"}).
+	Padding(0, 1)
+
+func newSession() *session {
+	return &session{
+		game:        chess.NewGame(),
+		viewport:    newHistoryViewport(),
+		chess960:    -1,
+		endgame:     -1,
+		annotations: map[string]annotation{},
+	}
+}
+
+// openTab starts a new game in a new tab, with the same time control,
+// engine side and orientation as the current one, and switches to it.
+func (m *model) openTab() (tea.Cmd, error) {
+	if len(m.sessions) >= maxTabs {
+		return nil, fmt.Errorf("at most %d games can be open", maxTabs)
+	}
+	s := newSession()
+	if m.clock != nil {
+		s.clock = newChessClock(m.clock.timeControls())
+	}
+	s.engineColor, s.flipped = m.engineColor, m.flipped
+	m.sessions = append(m.sessions, s)
+	return m.switchTab(len(m.sessions) - 1), nil
+}
+
+// closeTab closes the active tab and switches to its neighbour.
+func (m *model) closeTab() (tea.Cmd, error) {
+	if len(m.sessions) == 1 {
+		return nil, errors.New("can't close the last game")
+	}
+	closing := m.active
+	next := closing + 1
+	if next == len(m.sessions) {
+		next = closing - 1
+	}
+	cmd := m.switchTab(next)
+	m.sessions = append(m.sessions[:closing], m.sessions[closing+1:]...)
+	if m.active > closing {
+		m.active--
+	}
+	return cmd, nil
+}
+
+// switchTab makes the game in tab i the active one. Games in the background
+// are paused: their clocks don't run and the engine only thinks about the
+// active game.
+func (m *model) switchTab(i int) tea.Cmd {
+	if i < 0 || i >= len(m.sessions) || i == m.active {
+		return nil
+	}
+	// there is a single clock tick loop, which moves over to the new game
+	ticking := m.clock != nil && m.clock.running
+	if ticking {
+		m.clock.running = false
+	}
+
+	m.active = i
+	m.session = m.sessions[i]
+	m.pendingMove = nil
+	m.stagedMove = nil
+	m.confirmResign = false
+	m.dragging = false
+	m.error = nil
+	m.status = ""
+	m.resetInput()
+	m.positionChanged()
+	m.resizeHistory(m.historyWidth)
+
+	cmds := []tea.Cmd{m.nextEval()}
+	if m.clock != nil {
+		m.clock.lastTick = time.Now()
+		if ticking {
+			m.clock.running = true
+		} else if m.liveGame().Outcome() == chess.NoOutcome {
+			cmds = append(cmds, m.clock.start())
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// renderTabBar lists the open games, the active one under the title.
+func (m model) renderTabBar(title string) string {
+	var tabs []string
+	for i, s := range m.sessions {
+		if i == m.active {
+			tabs = append(tabs, titleStyle.Render(fmt.Sprintf("%d %s", i+1, title)))
+			continue
+		}
+		label := fmt.Sprintf("%d", i+1)
+		game := s.liveGame()
+		if game.Outcome() != chess.NoOutcome {
+			label += " " + string(game.Outcome())
+		} else if moves := len(game.Moves()); moves > 0 {
+			label += fmt.Sprintf(" move %d", (gameStartPly(game)+moves-1)/2+1)
+		}
+		tabs = append(tabs, inactiveTabStyle.Render(label))
+	}
+	return strings.Join(tabs, " ")
+}
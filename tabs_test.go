@@ -0,0 +1,17 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTabBarNumbersFromTheStart(t *testing.T) {
+	u := newUIModel(t)
+	u.m.game = newTestGameFrom(t, blackToMove, "Nf6")
+	u.send(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if len(u.m.sessions) != 2 {
+		t.Fatalf("%d tabs, want 2", len(u.m.sessions))
+	}
+	u.wantView("1 move 34")
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// pieceSet controls how pieces are drawn on the board.
+type pieceSet int
+
+const (
+	piecesLetters  pieceSet = iota // the piece letters of the locale
+	piecesUnicode                  // outlined symbols for White, filled ones for Black
+	piecesFigurine                 // filled symbols for both sides, told apart by color
+)
+
+func parsePieceSet(s string) (pieceSet, error) {
+	switch s {
+	case "letters":
+		return piecesLetters, nil
+	case "unicode":
+		return piecesUnicode, nil
+	case "figurine":
+		return piecesFigurine, nil
+	default:
+		return piecesLetters, fmt.Errorf("unknown piece set %q (want letters, unicode or figurine)", s)
+	}
+}
+
+// theme controls the colors of the board and how its pieces are drawn.
+// Colors left empty keep the default ones, so the zero value is the default
+// theme.
+type theme struct {
+	pieces      pieceSet
+	light, dark lipgloss.Color // square backgrounds
+	white       lipgloss.Color // piece foregrounds
+	black       lipgloss.Color
+	border      lipgloss.Color // the frame around the board, with -border
+	// contrast is the minimum contrast ratio of pieces against their
+	// squares, 0 for defaultPieceContrast; 1 turns the check off
+	contrast float64
+}
+
+// squareStyle returns the style of a light or dark square.
+func (t theme) squareStyle(dark bool) lipgloss.Style {
+	if dark {
+		if t.dark != "" {
+			return darkSquare.Background(t.dark)
+		}
+		return darkSquare
+	}
+	if t.light != "" {
+		return lightSquare.Background(t.light)
+	}
+	return lightSquare
+}
+
+// pieceStyle returns the style of the pieces of a side.
+func (t theme) pieceStyle(color chess.Color) lipgloss.Style {
+	if color == chess.White {
+		if t.white != "" {
+			return whitePiece.Foreground(t.white)
+		}
+		return whitePiece
+	}
+	if t.black != "" {
+		return blackPiece.Foreground(t.black)
+	}
+	return blackPiece
+}
+
+// pieceOn returns the style of a side's pieces on a square of the given
+// background, recolored if they would be hard to see there.
+func (t theme) pieceOn(color chess.Color, bg lipgloss.TerminalColor) lipgloss.Style {
+	style := t.pieceStyle(color)
+	fg, ok1 := style.GetForeground().(lipgloss.Color)
+	square, ok2 := bg.(lipgloss.Color)
+	if !ok1 || !ok2 {
+		return style
+	}
+	minimum := t.contrast
+	if minimum == 0 {
+		minimum = defaultPieceContrast
+	}
+	return style.Foreground(readableOn(fg, square, minimum))
+}
+
+// themeColor matches the colors a theme may use: hex colors such as
+// #DEBA90 and ANSI color numbers.
+var themeColor = regexp.MustCompile(`^(#[0-9A-Fa-f]{6}|[0-9]{1,3})$`)
+
+// loadTheme reads a theme file of key = value lines, e.g.
+//
+//	pieces = figurine
+//	light = #EEEED2
+//	dark = #769656
+//	white = #FFFFFF
+//	black = 0
+//	border = #769656
+//	contrast = 3
+//
+// pieces is letters, unicode or figurine and contrast the minimum contrast
+// ratio of pieces against their squares (1 to 21, 1 turning the check off);
+// the other keys are colors. Blank lines and lines starting with # are
+// skipped.
+func loadTheme(path string) (theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return theme{}, err
+	}
+	var t theme
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return theme{}, fmt.Errorf("%s:%d: want key = value", path, i+1)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if key == "pieces" {
+			if t.pieces, err = parsePieceSet(value); err != nil {
+				return theme{}, fmt.Errorf("%s:%d: %w", path, i+1, err)
+			}
+			continue
+		}
+		if key == "contrast" {
+			ratio, err := strconv.ParseFloat(value, 64)
+			if err != nil || ratio < 1 || ratio > 21 {
+				return theme{}, fmt.Errorf("%s:%d: contrast %q is not a ratio from 1 to 21", path, i+1, value)
+			}
+			t.contrast = ratio
+			continue
+		}
+		var color *lipgloss.Color
+		switch key {
+		case "light":
+			color = &t.light
+		case "dark":
+			color = &t.dark
+		case "white":
+			color = &t.white
+		case "black":
+			color = &t.black
+		case "border":
+			color = &t.border
+		default:
+			return theme{}, fmt.Errorf("%s:%d: unknown key %q (want pieces, light, dark, white, black, border or contrast)", path, i+1, key)
+		}
+		if !themeColor.MatchString(value) {
+			return theme{}, fmt.Errorf("%s:%d: %q is not a color (want #RRGGBB or an ANSI color number)", path, i+1, value)
+		}
+		*color = lipgloss.Color(value)
+	}
+	return t, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	themeFile = "theme"  // the name of the theme chosen last, in the config dir
+	themesDir = "themes" // theme files, in the config dir, offered with the built-in themes
+)
+
+// namedTheme is a theme the selector offers.
+type namedTheme struct {
+	name  string
+	theme theme
+}
+
+var builtinThemes = []namedTheme{
+	{"default", theme{}},
+	{"green", theme{light: "#EEEED2", dark: "#769656", border: "#769656"}},
+	{"blue", theme{light: "#DEE3E6", dark: "#8CA2AD", border: "#8CA2AD"}},
+	{"wood", theme{pieces: piecesFigurine, light: "#F0D9B5", dark: "#B58863", white: "#FFFFFF", black: "#000000", border: "#B58863"}},
+	{"outline", theme{pieces: piecesUnicode}},
+}
+
+// availableThemes returns the built-in themes followed by those in the
+// themes directory of the config dir, named after their files. Files that
+// don't load are left out.
+func availableThemes() []namedTheme {
+	themes := append([]namedTheme(nil), builtinThemes...)
+	dir, err := configDir()
+	if err != nil {
+		return themes
+	}
+	paths, _ := filepath.Glob(filepath.Join(dir, themesDir, "*"))
+	for _, path := range paths {
+		t, err := loadTheme(path)
+		if err != nil {
+			continue
+		}
+		themes = append(themes, namedTheme{strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), t})
+	}
+	return themes
+}
+
+// savedTheme returns the theme chosen last in the selector, if it is still
+// available.
+func savedTheme() (theme, bool) {
+	path, err := configPath(themeFile)
+	if err != nil {
+		return theme{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return theme{}, false
+	}
+	name := strings.TrimSpace(string(data))
+	for _, t := range availableThemes() {
+		if t.name == name {
+			return t.theme, true
+		}
+	}
+	return theme{}, false
+}
+
+func saveTheme(name string) error {
+	path, err := configPath(themeFile)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, []byte(name+"\n"))
+}
+
+// themeSelector previews the available themes on the board.
+type themeSelector struct {
+	themes   []namedTheme
+	selected int
+}
+
+func (m *model) openThemes() {
+	m.themes = &themeSelector{themes: availableThemes()}
+}
+
+// updateThemes moves through the themes with the arrow keys; enter applies
+// the selected one and keeps it for the next start, esc closes the
+// selector leaving the theme as it was.
+func (m model) updateThemes(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := m.themes
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.themes = nil
+	case "up", "shift+tab":
+		s.selected = (s.selected + len(s.themes) - 1) % len(s.themes)
+	case "down", "tab":
+		s.selected = (s.selected + 1) % len(s.themes)
+	case "enter":
+		chosen := s.themes[s.selected]
+		m.themes = nil
+		m.theme = chosen.theme
+		m.error = saveTheme(chosen.name)
+		m.status = "Theme " + chosen.name
+	}
+	return m, nil
+}
+
+// View lists the themes next to the position on the board drawn with the
+// selected one.
+func (s *themeSelector) View(m model) string {
+	var list strings.Builder
+	for i, t := range s.themes {
+		if i == s.selected {
+			list.WriteString(gameOverSelectedStyle.Render(t.name) + "\n")
+		} else {
+			list.WriteString(lipgloss.NewStyle().Padding(0, 1).Render(t.name) + "\n")
+		}
+	}
+	opts := boardOptions{
+		flipped: m.boardFlipped(),
+		labels:  m.labels,
+		scale:   m.scale,
+		locale:  m.locale,
+		theme:   s.themes[s.selected].theme,
+	}
+	preview := m.border.style(opts.theme.border).Render(renderBoard(m.displayedPosition(), 0, opts))
+	body := lipgloss.JoinHorizontal(lipgloss.Top, list.String(), strings.Repeat(" ", historyGap), preview)
+	footer := statusMessageStyle.Faint(true).Render(fmt.Sprintf("↑/↓ preview • enter apply • esc close • more in %s/ of the config directory", themesDir))
+	return helpStyle.Render(titleStyle.Render("Themes") + "\n\n" + body + "\n\n" + footer)
+}
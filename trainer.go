@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+const (
+	// trainerRound is how long a round of the square naming game lasts.
+	trainerRound  = 30 * time.Second
+	trainerFile   = "trainer-best.txt"
+	trainerPrompt = "Square: "
+)
+
+var trainerTargetStyle = lipgloss.NewStyle().Background(lipgloss.Color("#D9534F"))
+
+// trainer is the square naming game: a random square of an empty board is
+// highlighted and the user types its coordinate, as many times as they can
+// in a round. Board labels are hidden, or it would be too easy.
+type trainer struct {
+	round          int // counts rounds so ticks of an earlier one are ignored
+	target         chess.Square
+	asked          time.Time // when the target was highlighted
+	ends           time.Time
+	correct, wrong int
+	over           bool
+	feedback       string
+
+	// best results over all rounds, kept in the config directory
+	bestScore int
+	fastest   time.Duration
+
+	rng *rand.Rand // picks the targets
+}
+
+// trainerTickMsg redraws the remaining time of a round.
+type trainerTickMsg struct{ round int }
+
+func trainerTick(round int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return trainerTickMsg{round}
+	})
+}
+
+// startTrainer opens the square naming game and starts a round.
+func (m *model) startTrainer() tea.Cmd {
+	m.setMode(modePlay)
+	t := &trainer{rng: m.rng}
+	t.loadBest()
+	m.trainer = t
+	return m.nextRound()
+}
+
+// nextRound starts a new round of the square naming game.
+func (m *model) nextRound() tea.Cmd {
+	t := m.trainer
+	t.round++
+	t.correct, t.wrong = 0, 0
+	t.over = false
+	t.feedback = ""
+	t.ends = time.Now().Add(trainerRound)
+	t.pickTarget()
+	m.textInput.Reset()
+	m.textInput.Focus()
+	m.textInput.Prompt = trainerPrompt
+	m.fitInput(false)
+	return trainerTick(t.round)
+}
+
+func (t *trainer) pickTarget() {
+	previous := t.target
+	for t.target == previous {
+		t.target = chess.Square(t.rng.IntN(64))
+	}
+	t.asked = time.Now()
+}
+
+// handleTrainerTick ends the round once its time is up.
+func (m *model) handleTrainerTick(msg trainerTickMsg) tea.Cmd {
+	t := m.trainer
+	if t == nil || msg.round != t.round {
+		return nil
+	}
+	if time.Now().Before(t.ends) {
+		return trainerTick(t.round)
+	}
+	t.over = true
+	score := t.correct - t.wrong
+	t.feedback = fmt.Sprintf("Time's up: %d right, %d wrong", t.correct, t.wrong)
+	if score > t.bestScore {
+		t.bestScore = score
+		t.feedback += " · new best score!"
+	}
+	m.error = t.saveBest()
+	m.textInput.Reset()
+	return nil
+}
+
+// updateTrainer handles keys while the square naming game is open: enter
+// answers, or starts another round once time is up, and esc leaves.
+func (m model) updateTrainer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	t := m.trainer
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.trainer = nil
+		m.resetInput()
+		if m.normalMode {
+			m.textInput.Blur()
+		}
+		return m, nil
+	case "enter":
+		if t.over {
+			return m, m.nextRound()
+		}
+		answer := strings.ToLower(strings.TrimSpace(m.textInput.Value()))
+		m.textInput.Reset()
+		if answer == "" {
+			return m, nil
+		}
+		if answer != t.target.String() {
+			t.wrong++
+			t.feedback = fmt.Sprintf("%s is wrong, that was %s", answer, t.target)
+			t.pickTarget()
+			return m, nil
+		}
+		t.correct++
+		took := time.Since(t.asked)
+		t.feedback = fmt.Sprintf("%s is right (%.1fs)", answer, took.Seconds())
+		if t.fastest == 0 || took < t.fastest {
+			t.fastest = took
+		}
+		t.pickTarget()
+		return m, nil
+	}
+	if t.over {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// loadBest reads the best results of earlier rounds. A missing or damaged
+// file just means there are none.
+func (t *trainer) loadBest() {
+	path, err := configPath(trainerFile)
+	if err != nil {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var ms int64
+	if _, err := fmt.Sscan(string(data), &t.bestScore, &ms); err == nil {
+		t.fastest = time.Duration(ms) * time.Millisecond
+	}
+}
+
+// saveBest records the best score and the fastest answer.
+func (t *trainer) saveBest() error {
+	path, err := configPath(trainerFile)
+	if err != nil {
+		return err
+	}
+	data := fmt.Sprintf("%d %d\n", t.bestScore, t.fastest.Milliseconds())
+	return writeFileAtomic(path, []byte(data))
+}
+
+// View draws the empty board with the square to name, the score and the
+// time left.
+func (t *trainer) View(m model) string {
+	opts := m.boardOptions()
+	opts.labels = labelsNone
+	opts.showCoords = false
+	opts.annotation = annotation{}
+	opts.highlights = nil
+	opts.decorations = nil
+	if !t.over {
+		opts.highlights = map[chess.Square]lipgloss.Style{t.target: trainerTargetStyle}
+	}
+	width, _ := opts.size()
+	empty, _ := chess.FEN("8/8/8/8/8/8/8/8 w - - 0 1")
+	board := m.boardFrame().Render(renderBoard(chess.NewGame(empty).Position(), width, opts))
+
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Name the square") + "\n\n")
+	if !t.over {
+		left := max(time.Until(t.ends), 0).Round(time.Second)
+		sb.WriteString(fmt.Sprintf("%d right · %d wrong · %s left\n", t.correct, t.wrong, left))
+	} else {
+		sb.WriteString("enter plays another round\n")
+	}
+	best := fmt.Sprintf("best score %d", t.bestScore)
+	if t.fastest > 0 {
+		best += fmt.Sprintf(" · fastest answer %.1fs", t.fastest.Seconds())
+	}
+	sb.WriteString(statusMessageStyle.Render(best) + "\n\n")
+	if t.feedback != "" {
+		sb.WriteString(statusMessageStyle.Bold(true).Render(t.feedback) + "\n")
+	}
+	sb.WriteString(statusMessageStyle.Faint(true).Render("type the coordinate of the red square • esc leaves"))
+	return lipgloss.JoinHorizontal(lipgloss.Top, board, strings.Repeat(" ", historyGap), sb.String())
+}
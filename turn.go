@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/notnil/chess"
+)
+
+// turnFormat controls how the side to move is shown under the board.
+type turnFormat int
+
+const (
+	turnText    turnFormat = iota // "White to move"
+	turnDot                       // "● to move", the dot in the side's piece color
+	turnLetter                    // "w", the active color of FEN
+	turnVerbose                   // "Move 12 · White to move"
+)
+
+func parseTurnFormat(s string) (turnFormat, error) {
+	switch s {
+	case "text":
+		return turnText, nil
+	case "dot":
+		return turnDot, nil
+	case "letter":
+		return turnLetter, nil
+	case "verbose":
+		return turnVerbose, nil
+	default:
+		return turnText, fmt.Errorf("unknown turn format %q (want text, dot, letter or verbose)", s)
+	}
+}
+
+// fullMoveNumber is the number of the move being played in the position,
+// the last field of its FEN.
+func fullMoveNumber(pos *chess.Position) string {
+	fields := strings.Fields(pos.String())
+	return fields[len(fields)-1]
+}
+
+// renderTurn shows the side to move in the format chosen with -turn, in the
+// theme's piece color for that side.
+func (m model) renderTurn(side chess.Color, pos *chess.Position) string {
+	style := turnWhite
+	if side == chess.Black {
+		style = turnBlack
+	}
+	style = style.Foreground(m.theme.pieceStyle(side).GetForeground())
+	toMove := statusMessageStyle.Render(" to move")
+
+	switch m.turnFormat {
+	case turnDot:
+		return style.Render("●") + toMove
+	case turnLetter:
+		return style.Render(" " + strings.ToLower(side.String()) + " ")
+	case turnVerbose:
+		number := statusMessageStyle.Render("Move " + fullMoveNumber(pos) + " · ")
+		return lipgloss.JoinHorizontal(lipgloss.Top, number, style.Render(side.Name()), toMove)
+	default:
+		return style.Render(side.Name()) + toMove
+	}
+}
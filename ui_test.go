@@ -0,0 +1,190 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/notnil/chess"
+)
+
+// newTestGame plays the moves, given in SAN, from the standard start.
+func newTestGame(t *testing.T, moves ...string) *chess.Game {
+	t.Helper()
+	game := chess.NewGame()
+	for _, move := range moves {
+		if err := game.MoveStr(move); err != nil {
+			t.Fatalf("%s: %v", move, err)
+		}
+	}
+	return game
+}
+
+// newTestGameFrom plays the moves, given in SAN, from the position of fen.
+func newTestGameFrom(t *testing.T, fen string, moves ...string) *chess.Game {
+	t.Helper()
+	opt, err := chess.FEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	game := chess.NewGame(opt)
+	for _, move := range moves {
+		if err := game.MoveStr(move); err != nil {
+			t.Fatalf("%s: %v", move, err)
+		}
+	}
+	return game
+}
+
+// uiModel is the program as the user sees it: a model sized to a window,
+// driven by key messages and read through its View.
+type uiModel struct {
+	t *testing.T
+	m model
+}
+
+func newUIModel(t *testing.T) *uiModel {
+	t.Helper()
+	u := &uiModel{t: t, m: initialModel(config{})}
+	u.send(tea.WindowSizeMsg{Width: 120, Height: 50})
+	return u
+}
+
+// send passes msg through Update. The commands it returns, such as timers
+// and sounds, are not run.
+func (u *uiModel) send(msg tea.Msg) {
+	u.t.Helper()
+	next, _ := u.m.Update(msg)
+	u.m = next.(model)
+}
+
+// enter types text at the prompt a key at a time, as a terminal sends it,
+// and presses enter.
+func (u *uiModel) enter(text string) {
+	u.t.Helper()
+	for _, r := range text {
+		u.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	u.send(tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+// view returns the screen without its colors.
+func (u *uiModel) view() string {
+	return ansi.Strip(u.m.View())
+}
+
+func (u *uiModel) wantView(substrings ...string) {
+	u.t.Helper()
+	view := u.view()
+	for _, s := range substrings {
+		if !strings.Contains(view, s) {
+			u.t.Errorf("screen doesn't show %q:\n%s", s, view)
+		}
+	}
+}
+
+func TestUIGameToCheckmate(t *testing.T) {
+	u := newUIModel(t)
+	for _, move := range []string{"f3", "e5", "g4", "Qh4#"} {
+		u.enter(move)
+	}
+	if got := u.m.game.Outcome(); got != chess.BlackWon {
+		t.Fatalf("outcome = %s, want 0-1", got)
+	}
+	u.wantView("Game over", "Checkmate — Black wins!")
+
+	// closing the dialog shows the board and the history again
+	u.send(tea.KeyMsg{Type: tea.KeyEsc})
+	u.wantView("Qh4#", "Game over!")
+}
+
+func TestUIIllegalMove(t *testing.T) {
+	u := newUIModel(t)
+	u.enter("e5")
+	if n := len(u.m.game.Moves()); n != 0 {
+		t.Fatalf("%d moves played, want none", n)
+	}
+	if u.m.error == nil {
+		t.Fatal("no error for an illegal move")
+	}
+	// the move is left at the prompt to be corrected
+	u.wantView(u.m.error.Error(), "White to move", "e5")
+
+	u.send(tea.KeyMsg{Type: tea.KeyCtrlU})
+	u.enter("e4")
+	if u.m.error != nil {
+		t.Errorf("error %q left after a legal move", u.m.error)
+	}
+	u.wantView("Black to move")
+}
+
+func TestUIUndoRedo(t *testing.T) {
+	u := newUIModel(t)
+	u.enter("e4")
+	u.enter("e5")
+	u.send(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	if got := sanHistory(u.m.game); strings.Join(got, " ") != "e4" {
+		t.Fatalf("moves after undo = %v, want [e4]", got)
+	}
+	u.wantView("Black to move")
+
+	u.send(tea.KeyMsg{Type: tea.KeyCtrlY})
+	if got := sanHistory(u.m.game); strings.Join(got, " ") != "e4 e5" {
+		t.Fatalf("moves after redo = %v, want [e4 e5]", got)
+	}
+	u.wantView("White to move")
+}
+
+// command types a command after ':' and presses enter.
+func (u *uiModel) command(text string) {
+	u.t.Helper()
+	u.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{':'}})
+	u.enter(text)
+}
+
+func TestUIGotoFromAnalysis(t *testing.T) {
+	u := newUIModel(t)
+	u.enter("e4")
+	u.enter("e5")
+	u.send(tea.KeyMsg{Type: tea.KeyF4})
+	for _, move := range []string{"Nf3", "Nc6", "Bb5"} {
+		u.enter(move)
+	}
+
+	// the moves tried in analysis are not the game's
+	u.command("goto 4")
+	if u.m.error == nil {
+		t.Error("no error for a ply past the end of the game")
+	}
+	u.view()
+
+	u.command("goto 2")
+	if u.m.mode != modeReview || u.m.viewPly != 2 {
+		t.Fatalf("mode %s at ply %d, want Review at ply 2", u.m.mode, u.m.viewPly)
+	}
+	u.wantView("Reviewing ply 2 of 2")
+}
+
+func TestUIFindFromAnalysis(t *testing.T) {
+	u := newUIModel(t)
+	u.enter("e4")
+	u.enter("e5")
+	u.send(tea.KeyMsg{Type: tea.KeyF4})
+	for _, move := range []string{"Nf3", "Nc6"} {
+		u.enter(move)
+	}
+
+	// Nc6 was only tried in analysis
+	u.command("find Nc6")
+	if u.m.error == nil {
+		t.Error("a move tried in analysis was found in the game")
+	}
+	u.view()
+
+	u.command("find e5")
+	if u.m.mode != modeReview || u.m.viewPly != 2 {
+		t.Fatalf("mode %s at ply %d, want Review at ply 2", u.m.mode, u.m.viewPly)
+	}
+	u.view()
+}
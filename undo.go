@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/notnil/chess"
+)
+
+// replayGame rebuilds the game with only its first n moves, keeping the
+// starting position and tag pairs.
+func replayGame(game *chess.Game, n int) (*chess.Game, error) {
+	start, err := chess.FEN(game.Positions()[0].String())
+	if err != nil {
+		return nil, err
+	}
+	replay := chess.NewGame(start, chess.TagPairs(game.TagPairs()))
+	for _, mv := range game.Moves()[:n] {
+		if err := replay.Move(mv); err != nil {
+			return nil, err
+		}
+	}
+	return replay, nil
+}
+
+// undo takes back the last move, keeping it for redo. Against the engine
+// the engine's reply is taken back as well, so it is the user's turn again.
+// The clocks go back to the time left after the moves kept.
+func (m *model) undo() error {
+	if m.mode != modePlay && m.mode != modeAnalysis {
+		return errors.New("can't undo here")
+	}
+	moves := m.game.Moves()
+	n := len(moves) - 1
+	if n >= 0 && m.mode == modePlay && m.engineColor != chess.NoColor && m.game.Positions()[n].Turn() == m.engineColor {
+		n--
+	}
+	if n < 0 {
+		return errors.New("nothing to undo")
+	}
+	game, err := replayGame(m.game, n)
+	if err != nil {
+		return err
+	}
+	// the redo stack is popped from the end, latest move first
+	for i := len(moves) - 1; i >= n; i-- {
+		m.redo = append(m.redo, moves[i])
+	}
+	m.game = game
+	if m.clock != nil && m.mode == modePlay {
+		m.clock.takeBack(n, game.Positions()[0].Turn())
+	}
+	m.setHistory(sanHistory(game))
+	m.drawOffer = chess.NoColor
+	m.error = nil
+	m.positionChanged()
+	m.updateHistoryViewport()
+	return nil
+}
+
+// applyMovetext plays a sequence of moves such as "1. e4 e5 2. Nf3" for
+// both sides. Every move is checked before any is played, so an illegal
+// one leaves the board as it was. Against the engine, and in drills and
+// studies, the moves have to be played one at a time.
+func (m *model) applyMovetext(text string) (tea.Cmd, error) {
+	switch {
+	case m.mode != modePlay && m.mode != modeAnalysis:
+		return nil, errors.New("moves can only be applied in play or analysis mode")
+	case m.mode == modePlay && m.engine != nil && m.engineColor != chess.NoColor:
+		return nil, errors.New("a line of moves can't be played against the engine, f4 to play it in analysis")
+	case m.mode == modePlay && (m.drill != nil || m.studying()):
+		return nil, errors.New("play the moves one at a time")
+	case m.game.Outcome() != chess.NoOutcome:
+		return nil, errors.New("the game is already over")
+	}
+	moves := movetextTokens(text)
+	if len(moves) == 0 {
+		return nil, errors.New("no moves given")
+	}
+	pos := m.game.Position()
+	decoded := make([]*chess.Move, len(moves))
+	for i, san := range moves {
+		mv, err := m.locale.decodeMove(pos, san)
+		if err != nil {
+			return nil, fmt.Errorf("move %d (%s): %w", i+1, san, err)
+		}
+		decoded[i] = mv
+		pos = pos.Update(mv)
+	}
+	var cmd tea.Cmd
+	for _, mv := range decoded {
+		if err := m.game.Move(mv); err != nil {
+			return cmd, err
+		}
+		// each move is timed, but only the last is announced, evaluated
+		// and so on
+		cmd = m.moveApplied()
+	}
+	m.status = fmt.Sprintf("Played %d moves", len(decoded))
+	return cmd, nil
+}
+
+// redoMove replays the most recently undone move.
+func (m *model) redoMove() (tea.Cmd, error) {
+	if m.mode != modePlay && m.mode != modeAnalysis {
+		return nil, errors.New("can't redo here")
+	}
+	if len(m.redo) == 0 {
+		return nil, errors.New("nothing to redo")
+	}
+	mv := m.redo[len(m.redo)-1]
+	redo := m.redo[:len(m.redo)-1]
+	if err := m.game.Move(mv); err != nil {
+		return nil, err
+	}
+	// moveApplied drops the redo stack as for any new move
+	cmd := m.moveApplied()
+	m.redo = redo
+	return cmd, nil
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/notnil/chess"
+)
+
+func moveList(game *chess.Game) string {
+	return strings.Join(sanHistory(game), " ")
+}
+
+func TestUndoRedoAtTheEnds(t *testing.T) {
+	u := newUIModel(t)
+	if err := u.m.undo(); err == nil {
+		t.Error("undo at the start of the game")
+	}
+	if _, err := u.m.redoMove(); err == nil {
+		t.Error("redo with nothing undone")
+	}
+
+	u.enter("e4")
+	u.enter("e5")
+	for _, want := range []string{"e4", ""} {
+		if err := u.m.undo(); err != nil {
+			t.Fatal(err)
+		}
+		if got := moveList(u.m.game); got != want {
+			t.Errorf("moves after undo = %q, want %q", got, want)
+		}
+	}
+	if err := u.m.undo(); err == nil {
+		t.Error("undo past the start of the game")
+	}
+	for _, want := range []string{"e4", "e4 e5"} {
+		if _, err := u.m.redoMove(); err != nil {
+			t.Fatal(err)
+		}
+		if got := moveList(u.m.game); got != want {
+			t.Errorf("moves after redo = %q, want %q", got, want)
+		}
+	}
+	if _, err := u.m.redoMove(); err == nil {
+		t.Error("redo past the last move undone")
+	}
+}
+
+func TestUndoNewMoveDropsRedo(t *testing.T) {
+	u := newUIModel(t)
+	u.enter("e4")
+	u.enter("e5")
+	if err := u.m.undo(); err != nil {
+		t.Fatal(err)
+	}
+	u.enter("c5")
+	if _, err := u.m.redoMove(); err == nil {
+		t.Error("redo of e5 after c5 was played instead")
+	}
+}
+
+func TestUndoAgainstEngine(t *testing.T) {
+	u := newUIModel(t)
+	// the engine's replies are typed here, as there is no engine to play them
+	u.m.engineColor = chess.Black
+	u.enter("e4")
+	u.enter("e5")
+	u.enter("Nf3")
+	u.enter("Nc6")
+
+	// the engine's reply goes with the user's move
+	if err := u.m.undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := moveList(u.m.game); got != "e4 e5" {
+		t.Errorf("moves after undo = %q, want e4 e5", got)
+	}
+	if _, err := u.m.redoMove(); err != nil {
+		t.Fatal(err)
+	}
+	if got := moveList(u.m.game); got != "e4 e5 Nf3" {
+		t.Errorf("moves after redo = %q, want e4 e5 Nf3 with the engine to reply", got)
+	}
+}
+
+func TestUndoAfterNewGame(t *testing.T) {
+	u := newUIModel(t)
+	u.enter("e4")
+	if err := u.m.undo(); err != nil {
+		t.Fatal(err)
+	}
+	u.m.newGame()
+	if _, err := u.m.redoMove(); err == nil {
+		t.Error("redo of a move from the game before")
+	}
+	if err := u.m.undo(); err == nil {
+		t.Error("undo at the start of a new game")
+	}
+}
+
+func TestUndoTakesBackTheClock(t *testing.T) {
+	u := newUIModel(t)
+	tc := timeControl{initial: 5 * time.Minute, increment: 2 * time.Second}
+	u.m.clock = newChessClock(tc, tc)
+	u.tick(10 * time.Second)
+	u.enter("e4")
+	u.tick(20 * time.Second)
+	u.enter("e5")
+	u.tick(30 * time.Second)
+	u.enter("Nf3")
+	u.tick(5 * time.Second)
+
+	if err := u.m.undo(); err != nil {
+		t.Fatal(err)
+	}
+	white, black := u.m.clock.remaining[chess.White], u.m.clock.remaining[chess.Black]
+	if want := 5*time.Minute - 10*time.Second + 2*time.Second; white != want {
+		t.Errorf("White has %s after Nf3 was taken back, want %s as after e4", white, want)
+	}
+	if want := 5*time.Minute - 20*time.Second + 2*time.Second; black != want {
+		t.Errorf("Black has %s after Nf3 was taken back, want %s as after e5", black, want)
+	}
+
+	// White thinks about the move again from the time it had
+	u.tick(3 * time.Second)
+	u.enter("d4")
+	if want := 5*time.Minute - 10*time.Second - 3*time.Second + 4*time.Second; u.m.clock.remaining[chess.White] != want {
+		t.Errorf("White has %s after d4, want %s", u.m.clock.remaining[chess.White], want)
+	}
+
+	for range 3 {
+		if err := u.m.undo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for side, left := range u.m.clock.remaining {
+		if left != 5*time.Minute {
+			t.Errorf("%s has %s back at the start, want 5m0s", side.Name(), left)
+		}
+	}
+}
+
+func TestApplyMovetext(t *testing.T) {
+	u := newUIModel(t)
+	tc := timeControl{initial: 5 * time.Minute, increment: 2 * time.Second}
+	u.m.clock = newChessClock(tc, tc)
+	u.command("apply 1. e4 e5 2. Nf3 Nc6")
+	if got := moveList(u.m.game); got != "e4 e5 Nf3 Nc6" {
+		t.Fatalf("moves = %q, want e4 e5 Nf3 Nc6", got)
+	}
+	// every move is timed and given its increment
+	if n := len(u.m.clock.left); n != 4 {
+		t.Errorf("the clock timed %d moves, want 4", n)
+	}
+	for side, left := range u.m.clock.remaining {
+		if want := 5*time.Minute + 4*time.Second; left != want {
+			t.Errorf("%s has %s, want %s with two increments", side.Name(), left, want)
+		}
+	}
+	u.wantView("Played 4 moves")
+}
+
+func TestApplyMovetextStopsAtIllegalMove(t *testing.T) {
+	u := newUIModel(t)
+	u.command("apply e4 e5 Ke3 Nc6")
+	if got := moveList(u.m.game); got != "" {
+		t.Errorf("moves = %q, want none played because of the illegal Ke3", got)
+	}
+	if u.m.error == nil || !strings.Contains(u.m.error.Error(), "move 3 (Ke3)") {
+		t.Errorf("error %v doesn't point at move 3", u.m.error)
+	}
+}
+
+func TestApplyMovetextRefusedInStudy(t *testing.T) {
+	u := newUIModel(t)
+	u.m.study = writeStudy(t, "fen: "+scholarsMate+"\nsolution: Qxf7#\n")
+	if _, err := u.m.startStudy(0); err != nil {
+		t.Fatal(err)
+	}
+	u.command("apply Qxf7# Kxf7")
+	if got := moveList(u.m.game); got != "" {
+		t.Errorf("moves = %q, want none played in a study", got)
+	}
+	if u.m.error == nil || !strings.Contains(u.m.error.Error(), "one at a time") {
+		t.Errorf("error = %v, want the moves played one at a time", u.m.error)
+	}
+}
+
+func TestApplyMovetextRefusedAgainstEngine(t *testing.T) {
+	u := engineModel(t, chess.Black)
+	u.command("apply e4 e5 Nf3")
+	if got := moveList(u.m.game); got != "" {
+		t.Errorf("moves = %q, want none played for the engine", got)
+	}
+	if u.m.error == nil || !strings.Contains(u.m.error.Error(), "against the engine") {
+		t.Errorf("error = %v, want a refusal against the engine", u.m.error)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// normalKeys lists the single key commands of normal mode, for the status
+// line.
+const normalKeys = "N/B/R/Q/K pick a move, enter plays it • i type a move • f flip • u/r undo/redo • h hint • n new game • l legal moves • q quit"
+
+// setNormalMode switches between normal mode, where the move input is
+// unfocused and single keys are commands, and insert mode, where keys are
+// typed into the input. It only applies with -vim.
+func (m *model) setNormalMode(normal bool) {
+	m.normalMode = normal
+	if normal {
+		m.textInput.Reset()
+		m.textInput.Blur()
+	} else {
+		m.textInput.Focus()
+	}
+}
+
+// normalKey handles a key pressed in normal mode. It reports whether the
+// key was used up; others, such as : and the function keys, are handled as
+// usual.
+func (m *model) normalKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if cmd, ok := m.cycleKey(msg); ok {
+		return cmd, true
+	}
+	switch msg.String() {
+	case "enter":
+		if m.cycledMove != nil {
+			return m.playCycled(), true
+		}
+		m.setNormalMode(false)
+		return nil, true
+	case "i", "a":
+		m.cycledMove = nil
+		m.setNormalMode(false)
+		return nil, true
+	case "f":
+		return m.flipBoard(), true
+	case "u":
+		m.error = m.undo()
+		return nil, true
+	case "r":
+		cmd, err := m.redoMove()
+		m.error = err
+		return cmd, true
+	case "n":
+		return m.newGame(), true
+	case "l":
+		m.toggleLegalMoves()
+		return nil, true
+	case "h":
+		cmd, err := m.requestHint()
+		m.error = err
+		return cmd, true
+	case "q":
+		return tea.Quit, true
+	case "esc":
+		// esc leaves the other modes but never quits from normal mode
+		if m.cycledMove != nil {
+			m.cycledMove = nil
+			m.status = ""
+			return nil, true
+		}
+		if m.mode != modePlay {
+			m.setMode(modePlay)
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+// vimStatus shows which mode the input is in.
+func (m model) vimStatus() string {
+	if m.normalMode {
+		return "-- NORMAL -- " + normalKeys
+	}
+	return "-- INSERT -- esc for normal mode"
+}